@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+type AuthPolicyConfig struct {
+	// Jika true, CreateOrder dan CreateOffer ditolak sampai email user terverifikasi.
+	RequireEmailVerification bool
+	// Jika true, route admin hanya bisa diakses dengan token hasil login 2FA.
+	RequireAdminMFA bool
+}
+
+func LoadAuthPolicy() AuthPolicyConfig {
+	requireVerification, _ := strconv.ParseBool(os.Getenv("REQUIRE_EMAIL_VERIFICATION"))
+	requireAdminMFA, _ := strconv.ParseBool(os.Getenv("REQUIRE_ADMIN_MFA"))
+	return AuthPolicyConfig{
+		RequireEmailVerification: requireVerification,
+		RequireAdminMFA:          requireAdminMFA,
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CookieAuthConfig mengatur mode autentikasi berbasis cookie untuk frontend web.
+// Jika Enabled, client bisa meminta mode cookie dengan header X-Auth-Mode: cookie
+// saat login/refresh; token lalu dikirim sebagai cookie HttpOnly, bukan di body.
+type CookieAuthConfig struct {
+	Enabled  bool
+	Secure   bool
+	Domain   string
+	SameSite http.SameSite
+
+	AccessCookie  string
+	RefreshCookie string
+	// CSRFCookie sengaja tidak HttpOnly: frontend membacanya dan mengirim ulang
+	// nilainya di CSRFHeader (double-submit).
+	CSRFCookie string
+	CSRFHeader string
+	// RefreshPath membatasi cookie refresh token hanya terkirim ke endpoint auth.
+	RefreshPath string
+}
+
+func LoadCookieAuth() CookieAuthConfig {
+	enabled, _ := strconv.ParseBool(os.Getenv("AUTH_COOKIE_MODE"))
+
+	// default Secure; hanya dimatikan eksplisit untuk development lewat http
+	secure := true
+	if v, err := strconv.ParseBool(os.Getenv("AUTH_COOKIE_SECURE")); err == nil {
+		secure = v
+	}
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		// browser menolak SameSite=None tanpa Secure
+		sameSite = http.SameSiteNoneMode
+		secure = true
+	}
+
+	return CookieAuthConfig{
+		Enabled:       enabled,
+		Secure:        secure,
+		Domain:        os.Getenv("AUTH_COOKIE_DOMAIN"),
+		SameSite:      sameSite,
+		AccessCookie:  "hm_access_token",
+		RefreshCookie: "hm_refresh_token",
+		CSRFCookie:    "hm_csrf_token",
+		CSRFHeader:    "X-CSRF-Token",
+		RefreshPath:   "/api/auth",
+	}
+}
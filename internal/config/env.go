@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"os"
 
 	"github.com/joho/godotenv"
 )
@@ -11,4 +12,9 @@ func LoadEnv() {
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
-}
\ No newline at end of file
+}
+
+// IsDevelopment bernilai true jika APP_ENV=development.
+func IsDevelopment() bool {
+	return os.Getenv("APP_ENV") == "development"
+}
@@ -6,8 +6,23 @@ import (
 )
 
 type JWTConfig struct {
-	Secret []byte
-	TTLHours int
+	Secret          []byte
+	TTLHours        int
+	RefreshTTLHours int
+	// Issuer ditulis ke claim "iss" dan wajib cocok saat validasi.
+	Issuer string
+	// Audience opsional; jika diisi, ditulis ke claim "aud" dan wajib cocok saat validasi.
+	Audience string
+	// KeysDir berisi key PEM RSA/Ed25519 (nama file tanpa ekstensi = kid). Jika kosong,
+	// dipakai HS256 dengan Secret.
+	KeysDir string
+	// AcceptLegacyHS256: saat KeysDir diisi, token HS256 lama hanya diterima jika flag ini
+	// aktif (masa migrasi). Matikan setelah token lama kedaluwarsa agar JWT_SECRET yang
+	// bocor tidak bisa dipakai memalsukan token.
+	AcceptLegacyHS256 bool
+	// ActiveKID adalah kid yang dipakai untuk menandatangani token baru; default file terakhir.
+	// Public key lama cukup dibiarkan di KeysDir agar token lama tetap valid selama rotasi.
+	ActiveKID string
 }
 
 func LoadJWT() JWTConfig {
@@ -17,5 +32,26 @@ func LoadJWT() JWTConfig {
 	if err != nil || ttl <= 0 {
 		ttl = 24
 	}
-	return JWTConfig{Secret: []byte(secret), TTLHours: ttl}
+
+	refreshTTL, err := strconv.Atoi(os.Getenv("JWT_REFRESH_TTL_HOURS"))
+	if err != nil || refreshTTL <= 0 {
+		refreshTTL = 7 * 24
+	}
+
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "student-performance-app"
+	}
+
+	return JWTConfig{
+		Secret:          []byte(secret),
+		TTLHours:        ttl,
+		RefreshTTLHours: refreshTTL,
+		Issuer:          issuer,
+		Audience:        os.Getenv("JWT_AUDIENCE"),
+		KeysDir:         os.Getenv("JWT_KEYS_DIR"),
+		ActiveKID:       os.Getenv("JWT_ACTIVE_KID"),
+
+		AcceptLegacyHS256: os.Getenv("JWT_ACCEPT_LEGACY_HS256") == "true",
+	}
 }
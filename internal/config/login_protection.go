@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type LoginProtectionConfig struct {
+	// Jumlah gagal login per username sebelum akun dikunci sementara.
+	MaxAttempts int
+	// Jumlah gagal login per IP sebelum IP dikunci sementara.
+	IPMaxAttempts int
+	// Jeda awal setelah gagal login, berlipat dua pada setiap kegagalan berikutnya.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Lama penguncian setelah ambang batas tercapai.
+	LockoutDuration time.Duration
+	// Kegagalan yang lebih lama dari window ini tidak dihitung lagi.
+	Window time.Duration
+}
+
+func LoadLoginProtection() LoginProtectionConfig {
+	return LoginProtectionConfig{
+		MaxAttempts:     envInt("LOGIN_MAX_ATTEMPTS", 5),
+		IPMaxAttempts:   envInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+		BaseDelay:       time.Duration(envInt("LOGIN_BACKOFF_BASE_SECONDS", 1)) * time.Second,
+		MaxDelay:        time.Duration(envInt("LOGIN_BACKOFF_MAX_SECONDS", 60)) * time.Second,
+		LockoutDuration: time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		Window:          time.Duration(envInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+	}
+}
+
+// envInt membaca env integer positif, atau mengembalikan nilai default.
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+)
+
+const (
+	MailDriverLog  = "log" // mencetak email (termasuk link token) ke log; hanya untuk development
+	MailDriverFile = "file"
+	MailDriverSMTP = "smtp"
+)
+
+type MailConfig struct {
+	Driver       string // log, file, smtp; kosong hanya diizinkan saat APP_ENV=development
+	From         string
+	Dir          string // dipakai driver file
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	AppBaseURL   string // base URL frontend untuk link di email
+}
+
+func LoadMail() MailConfig {
+	cfg := MailConfig{
+		Driver:       os.Getenv("MAIL_DRIVER"),
+		From:         os.Getenv("MAIL_FROM"),
+		Dir:          os.Getenv("MAIL_DIR"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		AppBaseURL:   os.Getenv("APP_BASE_URL"),
+	}
+
+	// driver kosong di development berarti file; di luar development NewSender menolaknya
+	if cfg.Driver == "" && IsDevelopment() {
+		cfg.Driver = MailDriverFile
+	}
+	if cfg.From == "" {
+		cfg.From = "no-reply@home-market.local"
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "storage/mail"
+	}
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "587"
+	}
+	if cfg.AppBaseURL == "" {
+		cfg.AppBaseURL = "http://localhost:8080"
+	}
+	return cfg
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+)
+
+type MFAConfig struct {
+	Issuer        string
+	EncryptionKey []byte // kunci AES-256 untuk mengenkripsi secret TOTP di database
+}
+
+// LoadMFA gagal jika MFA_ENCRYPTION_KEY maupun JWT_SECRET kosong: sha256("") adalah
+// konstanta publik dan tidak boleh dipakai untuk mengenkripsi secret TOTP.
+func LoadMFA() (MFAConfig, error) {
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "Home Market"
+	}
+
+	keySource := os.Getenv("MFA_ENCRYPTION_KEY")
+	if keySource == "" {
+		keySource = os.Getenv("JWT_SECRET")
+	}
+	if keySource == "" {
+		return MFAConfig{}, errors.New("mfa: MFA_ENCRYPTION_KEY or JWT_SECRET must be set")
+	}
+	key := sha256.Sum256([]byte(keySource))
+
+	return MFAConfig{Issuer: issuer, EncryptionKey: key[:]}, nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import "os"
+
+type OIDCConfig struct {
+	// IssuerURL provider OIDC, mis. https://accounts.example.com atau http://localhost:9000 untuk mock lokal.
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL harus terdaftar di provider dan mengarah ke GET /api/auth/oidc/callback.
+	RedirectURL string
+	Scopes      string
+}
+
+func LoadOIDC() OIDCConfig {
+	redirect := os.Getenv("OIDC_REDIRECT_URL")
+	if redirect == "" {
+		redirect = LoadMail().AppBaseURL + "/api/auth/oidc/callback"
+	}
+	scopes := os.Getenv("OIDC_SCOPES")
+	if scopes == "" {
+		scopes = "openid email profile"
+	}
+
+	return OIDCConfig{
+		IssuerURL:    os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  redirect,
+		Scopes:       scopes,
+	}
+}
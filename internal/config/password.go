@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+type PasswordConfig struct {
+	// Algorithm untuk hash baru: argon2id (default) atau bcrypt. Hash lama dengan
+	// algoritma/parameter lain tetap bisa diverifikasi dan di-rehash saat login.
+	Algorithm string
+	BcryptCost int
+
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	MinLength int
+	MaxLength int
+	// BreachedListFile: file teks satu password per baris, tambahan dari daftar bawaan.
+	BreachedListFile string
+}
+
+func LoadPassword() PasswordConfig {
+	algorithm := strings.ToLower(os.Getenv("PASSWORD_HASH_ALGORITHM"))
+	if algorithm != PasswordAlgorithmBcrypt {
+		algorithm = PasswordAlgorithmArgon2id
+	}
+
+	return PasswordConfig{
+		Algorithm:         algorithm,
+		BcryptCost:        envInt("PASSWORD_BCRYPT_COST", 12),
+		Argon2Memory:      uint32(envInt("PASSWORD_ARGON2_MEMORY_KB", 64*1024)),
+		Argon2Iterations:  uint32(envInt("PASSWORD_ARGON2_ITERATIONS", 3)),
+		Argon2Parallelism: uint8(envInt("PASSWORD_ARGON2_PARALLELISM", 2)),
+		MinLength:         envInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:         envInt("PASSWORD_MAX_LENGTH", 128),
+		BreachedListFile:  os.Getenv("PASSWORD_BREACHED_LIST_FILE"),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+type PrivacyConfig struct {
+	// DeletionGracePeriod: jeda antara permintaan hapus akun dan eksekusinya.
+	DeletionGracePeriod time.Duration
+	// DeletionSweepInterval: seberapa sering worker memproses permintaan yang jatuh tempo.
+	DeletionSweepInterval time.Duration
+}
+
+func LoadPrivacy() PrivacyConfig {
+	return PrivacyConfig{
+		DeletionGracePeriod:   time.Duration(envInt("ACCOUNT_DELETION_GRACE_DAYS", 14)) * 24 * time.Hour,
+		DeletionSweepInterval: time.Duration(envInt("ACCOUNT_DELETION_SWEEP_MINUTES", 60)) * time.Minute,
+	}
+}
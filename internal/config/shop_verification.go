@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+type ShopVerificationConfig struct {
+	// StorageDir: direktori privat dokumen KYC, sengaja di luar uploads/ yang bisa diakses publik.
+	StorageDir string
+	// MaxDocumentSize dalam byte per file.
+	MaxDocumentSize int64
+	// Jika true, hanya toko terverifikasi yang boleh menambah item dan tampil di marketplace.
+	RequireVerifiedToList bool
+}
+
+func LoadShopVerification() ShopVerificationConfig {
+	dir := os.Getenv("SHOP_KYC_STORAGE_DIR")
+	if dir == "" {
+		dir = "storage/kyc"
+	}
+	requireVerified, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_SHOP_TO_LIST"))
+	return ShopVerificationConfig{
+		StorageDir:            dir,
+		MaxDocumentSize:       int64(envInt("SHOP_KYC_MAX_DOCUMENT_MB", 10)) << 20,
+		RequireVerifiedToList: requireVerified,
+	}
+}
@@ -18,14 +18,9 @@ func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
 
 // FR-ADMIN-01: List Users
 func (h *AdminHandler) ListUsers(c *gin.Context) {
-	role := c.MustGet("role_name").(string)
-	users, err := h.adminService.ListUsers(role)
+	users, err := h.adminService.ListUsers()
 
 	if err != nil {
-		if err.Error() == "unauthorized: admin access required" { 
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -48,14 +43,9 @@ func (h *AdminHandler) BlockUser(c *gin.Context) {
 		return
 	}
 	
-	adminRole := c.MustGet("role_name").(string)
-	err = h.adminService.BlockUser(adminRole, targetID, input.IsActive) 
+	err = h.adminService.BlockUser(targetID, input.IsActive) 
 	
 	if err != nil {
-		if err.Error() == "unauthorized: admin access required" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -63,6 +53,28 @@ func (h *AdminHandler) BlockUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user status updated to", "is_active": input.IsActive})
 }
 
+// Membuka lockout login user
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id format"})
+		return
+	}
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+	client := entity.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	if err := h.adminService.UnlockUser(adminID, targetID, client); err != nil {
+		if err == service.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user login unlocked"})
+}
+
 // FR-ADMIN-02: Moderate Item
 func (h *AdminHandler) ModerateItem(c *gin.Context) {
 	itemIDStr := c.Param("id")
@@ -72,21 +84,38 @@ func (h *AdminHandler) ModerateItem(c *gin.Context) {
 		return
 	}
 	
-	adminRole := c.MustGet("role_name").(string)
-	err = h.adminService.ModerateItem(adminRole, itemID) 
+	err = h.adminService.ModerateItem(itemID) 
 	
 	if err != nil {
 		if err.Error() == "item not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "unauthorized: admin access required" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{"message": "item successfully moderated (set inactive)"})
-}
\ No newline at end of file
+}
+
+func (h *AdminHandler) UnmoderateItem(c *gin.Context) {
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid item id format"})
+		return
+	}
+
+	if err := h.adminService.UnmoderateItem(itemID); err != nil {
+		switch err {
+		case service.ErrItemNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrItemNotModerated:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "moderation lifted; item is inactive until the seller lists it again"})
+}
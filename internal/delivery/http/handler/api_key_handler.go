@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	resp, err := h.apiKeyService.Create(userID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	keys, err := h.apiKeyService.List(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	keyID, ok := parseUUIDParam(c, "id", "invalid api key id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.apiKeyService.Revoke(userID, keyID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
+
+func (h *APIKeyHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrNoShopOwned, service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrInvalidAPIKeyScope, service.ErrAPIKeyLimitTooHigh:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrAPIKeyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"home-market/internal/config"
+	"home-market/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useCookieMode menentukan apakah respons auth dikirim sebagai cookie. Client
+// memintanya lewat header X-Auth-Mode: cookie, atau sudah memakai cookie sebelumnya.
+func useCookieMode(c *gin.Context, cookies config.CookieAuthConfig) bool {
+	if !cookies.Enabled {
+		return false
+	}
+	if strings.EqualFold(c.GetHeader("X-Auth-Mode"), "cookie") {
+		return true
+	}
+	if c.GetHeader("Authorization") != "" {
+		return false
+	}
+	if _, err := c.Cookie(cookies.AccessCookie); err == nil {
+		return true
+	}
+	_, err := c.Cookie(cookies.RefreshCookie)
+	return err == nil
+}
+
+func setCookie(c *gin.Context, cookies config.CookieAuthConfig, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cookies.Domain,
+		MaxAge:   maxAge,
+		Secure:   cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cookies.SameSite,
+	})
+}
+
+// setAuthCookies menulis access token dan refresh token sebagai cookie HttpOnly,
+// plus token CSRF baru yang bisa dibaca frontend. Token CSRF juga dikembalikan
+// agar frontend di domain lain tetap bisa mengirimkannya.
+func setAuthCookies(c *gin.Context, cookies config.CookieAuthConfig, accessToken, refreshToken string) (string, error) {
+	csrf, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	jwtCfg := config.LoadJWT()
+	accessAge := jwtCfg.TTLHours * 3600
+	refreshAge := jwtCfg.RefreshTTLHours * 3600
+
+	setCookie(c, cookies, cookies.AccessCookie, accessToken, "/", accessAge, true)
+	setCookie(c, cookies, cookies.RefreshCookie, refreshToken, cookies.RefreshPath, refreshAge, true)
+	setCookie(c, cookies, cookies.CSRFCookie, csrf, "/", refreshAge, false)
+	return csrf, nil
+}
+
+func clearAuthCookies(c *gin.Context, cookies config.CookieAuthConfig) {
+	if !cookies.Enabled {
+		return
+	}
+	setCookie(c, cookies, cookies.AccessCookie, "", "/", -1, true)
+	setCookie(c, cookies, cookies.RefreshCookie, "", cookies.RefreshPath, -1, true)
+	setCookie(c, cookies, cookies.CSRFCookie, "", "/", -1, false)
+}
+
+// refreshTokenFromRequest mengambil refresh token dari body, atau dari cookie pada mode cookie.
+func refreshTokenFromRequest(c *gin.Context, cookies config.CookieAuthConfig, fromBody string) string {
+	if fromBody != "" || !cookies.Enabled {
+		return fromBody
+	}
+	cookie, _ := c.Cookie(cookies.RefreshCookie)
+	return cookie
+}
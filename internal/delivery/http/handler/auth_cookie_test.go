@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteLoginResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		headers    map[string]string
+		cookies    []*http.Cookie
+		wantCookie bool
+	}{
+		{name: "cookie mode requested", enabled: true, headers: map[string]string{"X-Auth-Mode": "cookie"}, wantCookie: true},
+		{name: "existing refresh cookie", enabled: true, cookies: []*http.Cookie{{Name: "hm_refresh_token", Value: "old"}}, wantCookie: true},
+		{name: "bearer client", enabled: true, headers: map[string]string{"Authorization": "Bearer token"}},
+		{name: "plain client", enabled: true},
+		{name: "cookie mode disabled", headers: map[string]string{"X-Auth-Mode": "cookie"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.LoadCookieAuth()
+			cfg.Enabled = tt.enabled
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+			for _, cookie := range tt.cookies {
+				c.Request.AddCookie(cookie)
+			}
+
+			writeLoginResponse(c, cfg, &entity.LoginResponse{Token: "access", RefreshToken: "refresh"})
+
+			var body entity.LoginResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			set := map[string]*http.Cookie{}
+			for _, cookie := range w.Result().Cookies() {
+				set[cookie.Name] = cookie
+			}
+
+			if !tt.wantCookie {
+				if body.Token != "access" || body.RefreshToken != "refresh" || len(set) != 0 {
+					t.Fatalf("body = %+v, cookies = %v; want tokens in the body only", body, set)
+				}
+				return
+			}
+			if body.Token != "" || body.RefreshToken != "" || body.CSRFToken == "" {
+				t.Fatalf("body = %+v; want only the csrf token", body)
+			}
+			access, refresh, csrf := set[cfg.AccessCookie], set[cfg.RefreshCookie], set[cfg.CSRFCookie]
+			if access == nil || access.Value != "access" || !access.HttpOnly {
+				t.Fatalf("access cookie = %+v", access)
+			}
+			if refresh == nil || refresh.Value != "refresh" || !refresh.HttpOnly || refresh.Path != cfg.RefreshPath {
+				t.Fatalf("refresh cookie = %+v", refresh)
+			}
+			// frontend harus bisa membaca token CSRF untuk dikirim ulang di header
+			if csrf == nil || csrf.Value != body.CSRFToken || csrf.HttpOnly {
+				t.Fatalf("csrf cookie = %+v", csrf)
+			}
+		})
+	}
+}
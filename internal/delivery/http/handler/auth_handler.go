@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 
+	"home-market/internal/config"
 	entity "home-market/internal/domain"
 	service "home-market/internal/service/postgresql"
 
@@ -12,10 +17,25 @@ import (
 
 type AuthHandler struct {
 	authService *service.AuthService
+	cookies     config.CookieAuthConfig
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *service.AuthService, cookies config.CookieAuthConfig) *AuthHandler {
+	return &AuthHandler{authService: authService, cookies: cookies}
+}
+
+// writeLoginResponse mengirim token di body, atau sebagai cookie pada mode cookie.
+// Dipakai login password, verifikasi MFA, ganti password, dan callback OIDC.
+func writeLoginResponse(c *gin.Context, cookies config.CookieAuthConfig, resp *entity.LoginResponse) {
+	if useCookieMode(c, cookies) {
+		csrf, err := setAuthCookies(c, cookies, resp.Token, resp.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.Token, resp.RefreshToken, resp.CSRFToken = "", "", csrf
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -23,7 +43,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
         Username string `json:"username" binding:"required"`
         Email  string `json:"email"  binding:"required,email"`
         FullName string `json:"fullName" binding:"required"`
-        Password string `json:"password" binding:"required,min=6"`
+        Password string `json:"password" binding:"required"`
     }
 
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,6 +63,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
     userResp, err := h.authService.Register(&input) 
     
     if err != nil {
+        var policyErr *service.PasswordPolicyError
+        if errors.As(err, &policyErr) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
         switch err {
         case service.ErrUsernameTaken,
              service.ErrEmailTaken:
@@ -67,8 +92,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.Login(req.Username, req.Password)
+	resp, challenge, err := h.authService.Login(req.Username, req.Password, clientInfo(c))
 	if err != nil {
+		if writeLoginThrottled(c, err) {
+			return
+		}
 		switch err {
 		case service.ErrInvalidCredentials:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
@@ -80,7 +108,53 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	writeLoginResponse(c, h.cookies, resp)
+}
+
+// writeLoginThrottled menjawab 429 dengan Retry-After jika err adalah LoginThrottledError.
+func writeLoginThrottled(c *gin.Context, err error) bool {
+	var throttled *service.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": retryAfter})
+	return true
+}
+
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var input entity.MFAVerifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.VerifyMFA(input, clientInfo(c))
+	if err != nil {
+		if writeLoginThrottled(c, err) {
+			return
+		}
+		switch err {
+		case service.ErrInvalidMFAToken,
+			service.ErrInvalidMFACode:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrInactiveAccount:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	writeLoginResponse(c, h.cookies, resp)
 }
 
 func (h *AuthHandler) Refresh(c *gin.Context) {
@@ -88,17 +162,26 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		RefreshToken string `json:"refreshToken"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// body boleh kosong pada mode cookie
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	refreshToken := refreshTokenFromRequest(c, h.cookies, req.RefreshToken)
+	if refreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	newToken, err := h.authService.Refresh(req.RefreshToken)
+	resp, err := h.authService.Refresh(refreshToken, clientInfo(c))
 	if err != nil {
 		switch err {
 		case service.ErrInvalidRefreshToken,
+			service.ErrRefreshTokenReused,
 			service.ErrInvalidUserID:
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrInactiveAccount:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		case service.ErrUserNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
@@ -107,7 +190,134 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": newToken})
+	if useCookieMode(c, h.cookies) {
+		csrf, err := setAuthCookies(c, h.cookies, resp.Token, resp.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp.Token, resp.RefreshToken, resp.CSRFToken = "", "", csrf
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	// body boleh kosong pada mode cookie
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	refreshToken := refreshTokenFromRequest(c, h.cookies, req.RefreshToken)
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	clearAuthCookies(c, h.cookies)
+	if err := h.authService.Logout(refreshToken); err != nil {
+		if err == service.ErrInvalidRefreshToken {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	clearAuthCookies(c, h.cookies)
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out from all devices"})
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var input entity.ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	if err := h.authService.ForgotPassword(input.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var input entity.ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	if err := h.authService.ResetPassword(input.Token, input.Password); err != nil {
+		var policyErr *service.PasswordPolicyError
+		if err == service.ErrInvalidResetToken || errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset, please login again"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(token); err != nil {
+		switch err {
+		case service.ErrInvalidVerificationToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.authService.ResendVerification(userID); err != nil {
+		switch err {
+		case service.ErrEmailAlreadyVerified:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrVerificationThrottled:
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
 }
 
 func (h *AuthHandler) Profile(c *gin.Context) {
@@ -136,3 +346,150 @@ func (h *AuthHandler) Profile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.UpdateProfile(userID, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidUsername, service.ErrInvalidFullName:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUsernameTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	resp, err := h.authService.ChangePassword(userID, input, c.GetBool("mfa"), clientInfo(c))
+	if err != nil {
+		var policyErr *service.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		switch err {
+		case service.ErrWrongPassword:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrSamePassword:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	writeLoginResponse(c, h.cookies, resp)
+}
+
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.ChangeEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	if err := h.authService.RequestEmailChange(userID, input); err != nil {
+		switch err {
+		case service.ErrWrongPassword:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrSameEmail:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrEmailTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "confirmation link sent to the new email"})
+}
+
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := h.authService.ConfirmEmailChange(token); err != nil {
+		switch err {
+		case service.ErrInvalidEmailChangeToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrEmailTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email changed"})
+}
+
+// clientInfo mengambil IP dan user agent request untuk dicatat pada sesi.
+func clientInfo(c *gin.Context) entity.ClientInfo {
+	return entity.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	currentID, _ := c.Get("session_id")
+	current, _ := currentID.(uuid.UUID)
+
+	sessions, err := h.authService.ListSessions(userID, current)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, ok := parseUUIDParam(c, "id", "invalid session id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		if err == service.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
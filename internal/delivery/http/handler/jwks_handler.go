@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"home-market/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS mempublikasikan public key penandatangan JWT (GET /.well-known/jwks.json).
+func JWKS(c *gin.Context) {
+	set, err := utils.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	resp, err := h.mfaService.Setup(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MFAHandler) Enable(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	codes, err := h.mfaService.Enable(userID, input.Code)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "two-factor authentication enabled, store the recovery codes safely",
+		"recovery_codes": codes,
+	})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	if err := h.mfaService.Disable(userID, input.Code, input.RecoveryCode); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, input.Code)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *MFAHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrMFAAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrMFANotEnabled,
+		service.ErrMFASetupMissing,
+		service.ErrInvalidMFACode:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
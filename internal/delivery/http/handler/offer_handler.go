@@ -22,13 +22,6 @@ func NewOfferHandler(offerService *service.OfferService) *OfferHandler {
 // FR-GIVER-01 & FR-GIVER-02: Membuat Penawaran (POST /offers)
 func (h *OfferHandler) CreateOffer(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
-
-	if role != "giver" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: only Giver can create offers"})
-		return
-	}
-
 	form, err := c.MultipartForm()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form-data", "detail": err.Error()})
@@ -78,7 +71,7 @@ func (h *OfferHandler) CreateOffer(c *gin.Context) {
 	imageURL := "/uploads/offers/" + filename
 	
 	// --- Service Call ---
-	offer, err := h.offerService.CreateOffer(userID, role, input, imageURL) // Panggil OfferService
+	offer, err := h.offerService.CreateOffer(userID, input, imageURL) // Panggil OfferService
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -93,15 +86,14 @@ func (h *OfferHandler) CreateOffer(c *gin.Context) {
 // FR-GIVER-03: Melihat Status Penawaran (GET /offers/my)
 func (h *OfferHandler) GetMyOffers(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
+	permissions := c.MustGet("permissions").([]string)
 
-	if role != "giver" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: only Giver can view offers"})
-		return
-	}
-
-	offers, err := h.offerService.GetMyOffers(userID, role) // Panggil OfferService
+	offers, err := h.offerService.GetMyOffers(userID, permissions) // Panggil OfferService
 	if err != nil {
+		if err == service.ErrNotGiver {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -112,9 +104,8 @@ func (h *OfferHandler) GetMyOffers(c *gin.Context) {
 // FR-OFFER-01: Seller Melihat Penawaran (GET /offers/inbox)
 func (h *OfferHandler) GetOffersToSeller(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
 
-	offers, err := h.offerService.GetOffersToSeller(userID, role) // Panggil OfferService
+	offers, err := h.offerService.GetOffersToSeller(userID) // Panggil OfferService
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	cookies     config.CookieAuthConfig
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService, cookies config.CookieAuthConfig) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, cookies: cookies}
+}
+
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, err := h.oidcService.Start(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	if c.Query("mode") == "json" {
+		c.JSON(http.StatusOK, entity.OIDCStartResponse{AuthorizationURL: authURL})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	// provider mengirim error (mis. user membatalkan login) lewat query string
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "external login failed", "detail": providerErr})
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	resp, challenge, err := h.oidcService.Callback(c.Request.Context(), code, state, clientInfo(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	writeLoginResponse(c, h.cookies, resp)
+}
+
+func (h *OIDCHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOIDCDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCInvalidState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCLoginFailed),
+		errors.Is(err, service.ErrOIDCEmailMissing):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInactiveAccount):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOIDCAccountUnverified):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
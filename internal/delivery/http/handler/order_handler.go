@@ -56,13 +56,6 @@ func (h *OrderHandler) GetItemDetail(c *gin.Context) {
 // FR-BUYER-04: Membuat Order (POST /orders)
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
-	
-	if role != "buyer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: only Buyer can create orders"})
-		return
-	}
-
 	var input entity.CreateOrderInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input", "detail": err.Error()})
@@ -94,9 +87,9 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
+	permissions := c.MustGet("permissions").([]string)
 
-	order, err := h.orderService.UpdateOrderStatus(userID, role, orderID, input.NewStatus) // Asumsi service menerima string status
+	order, err := h.orderService.UpdateOrderStatus(userID, permissions, orderID, input.NewStatus) // Asumsi service menerima string status
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -121,9 +114,9 @@ func (h *OrderHandler) InputShippingReceipt(c *gin.Context) {
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
+	permissions := c.MustGet("permissions").([]string)
 
-	order, err := h.orderService.InputShippingReceipt(userID, role, orderID, input)
+	order, err := h.orderService.InputShippingReceipt(userID, permissions, orderID, input)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -142,9 +135,9 @@ func (h *OrderHandler) GetOrderTracking(c *gin.Context) {
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
+	permissions := c.MustGet("permissions").([]string)
 
-	order, items, err := h.orderService.GetOrderTracking(userID, role, orderID)
+	order, items, err := h.orderService.GetOrderTracking(userID, permissions, orderID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PrivacyHandler struct {
+	privacyService *service.PrivacyService
+	kycCfg         config.ShopVerificationConfig
+}
+
+func NewPrivacyHandler(privacyService *service.PrivacyService, kycCfg config.ShopVerificationConfig) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService, kycCfg: kycCfg}
+}
+
+func (h *PrivacyHandler) Export(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	export, err := h.privacyService.ExportData(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("home-market-export-%s", export.ExportedAt.Format("20060102150405"))
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := buildExportZip(export, h.kycCfg.StorageDir)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// buildExportZip menulis setiap bagian ekspor sebagai file JSON terpisah di dalam ZIP,
+// ditambah file dokumen KYC di folder kyc/.
+func buildExportZip(export *entity.DataExport, kycDir string) ([]byte, error) {
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"roles.json", export.Roles},
+		{"shop.json", export.Shop},
+		{"shop_verifications.json", export.ShopVerifications},
+		{"orders.json", export.Orders},
+		{"offers_given.json", export.OffersGiven},
+		{"offers_received.json", export.OffersReceived},
+		{"notifications.json", export.Notifications},
+		{"status_history.json", export.StatusHistory},
+		{"sessions.json", export.Sessions},
+		{"activity_logs.json", export.ActivityLogs},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		w, err := zw.Create(section.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(section.data); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range export.ShopVerifications {
+		for _, doc := range v.Documents {
+			name := fmt.Sprintf("kyc/%s_%s", doc.ID, filepath.Base(doc.OriginalName))
+			if err := addFileToZip(zw, name, filepath.Join(kycDir, filepath.Base(doc.StoragePath))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addFileToZip menyalin file di disk ke ZIP; file yang sudah tidak ada dilewati.
+func addFileToZip(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.DeleteAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	req, err := h.privacyService.RequestDeletion(userID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, req)
+}
+
+func (h *PrivacyHandler) GetDeletion(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	req, err := h.privacyService.GetDeletion(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.privacyService.CancelDeletion(userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
+
+func (h *PrivacyHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrWrongPassword:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case service.ErrDeletionAlreadyRequested:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrNoDeletionRequest, service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleApplicationHandler struct {
+	appService *service.RoleApplicationService
+}
+
+func NewRoleApplicationHandler(appService *service.RoleApplicationService) *RoleApplicationHandler {
+	return &RoleApplicationHandler{appService: appService}
+}
+
+func (h *RoleApplicationHandler) Submit(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.CreateRoleApplicationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	app, err := h.appService.Submit(userID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, app)
+}
+
+func (h *RoleApplicationHandler) ListMine(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	apps, err := h.appService.ListMine(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": apps})
+}
+
+func (h *RoleApplicationHandler) List(c *gin.Context) {
+	apps, err := h.appService.List(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": apps})
+}
+
+func (h *RoleApplicationHandler) Approve(c *gin.Context) {
+	h.review(c, h.appService.Approve)
+}
+
+func (h *RoleApplicationHandler) Reject(c *gin.Context) {
+	h.review(c, h.appService.Reject)
+}
+
+func (h *RoleApplicationHandler) review(c *gin.Context, action func(adminID, appID uuid.UUID, note string) (*entity.RoleApplication, error)) {
+	appID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application id"})
+		return
+	}
+
+	// body opsional, hanya berisi catatan review
+	var input entity.ReviewRoleApplicationInput
+	_ = c.ShouldBindJSON(&input)
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+	app, err := action(adminID, appID, input.Note)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, app)
+}
+
+func (h *RoleApplicationHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrRoleNotFound,
+		service.ErrRoleApplicationNotFound,
+		service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrRoleAlreadyHeld,
+		service.ErrApplicationPending,
+		service.ErrRoleApplicationNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var input entity.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	roleID, ok := parseUUIDParam(c, "id", "invalid role id")
+	if !ok {
+		return
+	}
+
+	var input entity.RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(roleID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	roleID, ok := parseUUIDParam(c, "id", "invalid role id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(roleID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role deleted"})
+}
+
+func (h *RoleHandler) ListRolePermissions(c *gin.Context) {
+	roleID, ok := parseUUIDParam(c, "id", "invalid role id")
+	if !ok {
+		return
+	}
+
+	perms, err := h.roleService.ListRolePermissions(roleID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": perms})
+}
+
+func (h *RoleHandler) GrantPermission(c *gin.Context) {
+	h.changeGrant(c, h.roleService.GrantPermission, "permission granted")
+}
+
+func (h *RoleHandler) RevokePermission(c *gin.Context) {
+	h.changeGrant(c, h.roleService.RevokePermission, "permission revoked")
+}
+
+func (h *RoleHandler) changeGrant(c *gin.Context, action func(roleID, permissionID uuid.UUID) error, message string) {
+	roleID, ok := parseUUIDParam(c, "id", "invalid role id")
+	if !ok {
+		return
+	}
+	permissionID, ok := parseUUIDParam(c, "permissionId", "invalid permission id")
+	if !ok {
+		return
+	}
+
+	if err := action(roleID, permissionID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	perms, err := h.roleService.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": perms})
+}
+
+func (h *RoleHandler) CreatePermission(c *gin.Context) {
+	var input entity.CreatePermissionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	perm, err := h.roleService.CreatePermission(input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, perm)
+}
+
+func (h *RoleHandler) DeletePermission(c *gin.Context) {
+	permissionID, ok := parseUUIDParam(c, "id", "invalid permission id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeletePermission(permissionID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "permission deleted"})
+}
+
+func (h *RoleHandler) AssignUserRole(c *gin.Context) {
+	userID, ok := parseUUIDParam(c, "id", "invalid user id")
+	if !ok {
+		return
+	}
+
+	var input entity.AssignRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	if err := h.roleService.AssignUserRole(userID, input.RoleID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role assigned"})
+}
+
+func (h *RoleHandler) RemoveUserRole(c *gin.Context) {
+	userID, ok := parseUUIDParam(c, "id", "invalid user id")
+	if !ok {
+		return
+	}
+	roleID, ok := parseUUIDParam(c, "roleId", "invalid role id")
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.RemoveUserRole(userID, roleID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role removed"})
+}
+
+func (h *RoleHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrRoleNotFound,
+		service.ErrPermissionNotFound,
+		service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrRoleNameTaken,
+		service.ErrPermissionNameTaken,
+		service.ErrSystemRole,
+		service.ErrSystemPermission,
+		service.ErrRoleInUse,
+		service.ErrRoleAlreadyHeld,
+		service.ErrRoleNotHeld,
+		service.ErrPrimaryRole:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,12 +39,7 @@ func (h *ShopItemHandler) CreateShop(c *gin.Context) {
 	}
 	userID := rawID.(uuid.UUID)
 
-	roleRaw, ok := c.Get("role_name")
-	if !ok {
-		c.JSON(http.StatusForbidden, gin.H{"error": "role missing"})
-		return
-	}
-	role := roleRaw.(string)
+	permissions := c.MustGet("permissions").([]string)
 
 	var input entity.CreateShopInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -48,7 +48,7 @@ func (h *ShopItemHandler) CreateShop(c *gin.Context) {
 	}
 
 	// Memanggil service gabungan
-	shop, err := h.shopItemService.CreateShop(userID, role, input)
+	shop, err := h.shopItemService.CreateShop(userID, permissions, input)
 	if err != nil {
 		switch err {
 		case service.ErrNotSeller:
@@ -64,6 +64,206 @@ func (h *ShopItemHandler) CreateShop(c *gin.Context) {
 	c.JSON(http.StatusCreated, shop)
 }
 
+func (h *ShopItemHandler) ListShops(c *gin.Context) {
+	var filter entity.ShopFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query", "detail": err.Error()})
+		return
+	}
+
+	shops, err := h.shopItemService.ListShops(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shops})
+}
+
+func (h *ShopItemHandler) GetShop(c *gin.Context) {
+	shopID, ok := parseUUIDParam(c, "id", "invalid shop id")
+	if !ok {
+		return
+	}
+
+	profile, err := h.shopItemService.GetShopProfile(shopID)
+	if err != nil {
+		h.writeShopError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *ShopItemHandler) GetMyShop(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	profile, err := h.shopItemService.GetMyShop(userID)
+	if err != nil {
+		h.writeShopError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *ShopItemHandler) UpdateMyShop(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form-data", "detail": err.Error()})
+		return
+	}
+
+	// helper: nil jika field tidak dikirim sama sekali
+	get := func(key string) *string {
+		if v, ok := form.Value[key]; ok && len(v) > 0 {
+			return &v[0]
+		}
+		return nil
+	}
+
+	input := entity.UpdateShopInput{
+		Name:        get("name"),
+		Description: get("description"),
+		Address:     get("address"),
+	}
+
+	// kedua file dicek dulu agar banner yang ditolak tidak meninggalkan logo tersimpan
+	logo, err := checkShopImage(form.File["logo"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid logo", "detail": err.Error()})
+		return
+	}
+	banner, err := checkShopImage(form.File["banner"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid banner", "detail": err.Error()})
+		return
+	}
+
+	logoURL, err := saveShopImage(c, logo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	bannerURL, err := saveShopImage(c, banner)
+	if err != nil {
+		removeUploads([]string{logoURL})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	shop, replaced, err := h.shopItemService.UpdateShop(userID, input, logoURL, bannerURL)
+	if err != nil {
+		// ditolak (mis. bukan anggota toko atau tanpa izin manage_shop): file baru tidak dipakai
+		removeUploads([]string{logoURL, bannerURL})
+		h.writeShopError(c, err)
+		return
+	}
+	removeUploads(replaced)
+
+	c.JSON(http.StatusOK, gin.H{"message": "shop updated", "data": shop})
+}
+
+func (h *ShopItemHandler) GetMyAvailability(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	availability, err := h.shopItemService.GetMyAvailability(userID)
+	if err != nil {
+		h.writeShopError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *ShopItemHandler) UpdateVacation(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.UpdateVacationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	availability, err := h.shopItemService.UpdateVacation(userID, input)
+	if err != nil {
+		h.writeShopError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *ShopItemHandler) UpdateOperatingHours(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.UpdateOperatingHoursInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	availability, err := h.shopItemService.UpdateOperatingHours(userID, input)
+	if err != nil {
+		h.writeShopError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *ShopItemHandler) writeShopError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrNoShopOwned):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrShopPermissionDenied), errors.Is(err, service.ErrShopAccessRevoked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidShopName), errors.Is(err, service.ErrInvalidShopAddress),
+		errors.Is(err, service.ErrInvalidOperatingHours), errors.Is(err, service.ErrInvalidVacationUntil),
+		errors.Is(err, service.ErrInvalidUTCOffset):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Batas ukuran logo/banner toko
+const maxShopImageSize = 5 << 20
+
+var shopImageExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+// checkShopImage memvalidasi file pertama (jika ada) tanpa menyimpannya.
+func checkShopImage(files []*multipart.FileHeader) (*multipart.FileHeader, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	file := files[0]
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !shopImageExts[ext] {
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+	if file.Size > maxShopImageSize {
+		return nil, fmt.Errorf("file larger than %d MB", maxShopImageSize>>20)
+	}
+	return file, nil
+}
+
+// saveShopImage menyimpan file yang sudah dicek ke uploads/shops dan mengembalikan URL-nya.
+func saveShopImage(c *gin.Context, file *multipart.FileHeader) (string, error) {
+	if file == nil {
+		return "", nil
+	}
+
+	filename := uuid.New().String() + strings.ToLower(filepath.Ext(file.Filename))
+	if err := c.SaveUploadedFile(file, "uploads/shops/"+filename); err != nil {
+		return "", err
+	}
+	return "/uploads/shops/" + filename, nil
+}
+
 // ===============================================
 // 2. CATEGORY MANAGEMENT METHODS (dari CategoryHandler)
 // ===============================================
@@ -76,13 +276,6 @@ func (h *ShopItemHandler) CreateCategory(c *gin.Context) {
 	}
 	userID := rawID.(uuid.UUID)
 
-	rawRole, ok := c.Get("role_name")
-	if !ok {
-		c.JSON(http.StatusForbidden, gin.H{"error": "role missing"})
-		return
-	}
-	role := rawRole.(string)
-
 	var input entity.CreateCategoryInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
@@ -90,12 +283,12 @@ func (h *ShopItemHandler) CreateCategory(c *gin.Context) {
 	}
 
 	// Memanggil service gabungan
-	category, err := h.shopItemService.CreateCategory(userID, role, input)
+	category, err := h.shopItemService.CreateCategory(userID, input)
 	if err != nil {
 		switch err {
-		case service.ErrNotSeller:
+		case service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		case service.ErrNoShopOwned:
+		case service.ErrNoShopOwned, service.ErrCategoryNotOwned, service.ErrCategoryDepthExceeded:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case service.ErrCategoryExists:
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
@@ -108,6 +301,81 @@ func (h *ShopItemHandler) CreateCategory(c *gin.Context) {
 	c.JSON(http.StatusCreated, category)
 }
 
+func (h *ShopItemHandler) ListCategories(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	categories, err := h.shopItemService.ListCategories(userID)
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+func (h *ShopItemHandler) UpdateCategory(c *gin.Context) {
+	categoryID, ok := parseUUIDParam(c, "id", "invalid category id")
+	if !ok {
+		return
+	}
+
+	var input entity.UpdateCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	category, err := h.shopItemService.UpdateCategory(userID, categoryID, input)
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *ShopItemHandler) DeleteCategory(c *gin.Context) {
+	categoryID, ok := parseUUIDParam(c, "id", "invalid category id")
+	if !ok {
+		return
+	}
+
+	var reassignTo *uuid.UUID
+	if raw := c.Query("reassign_to"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to"})
+			return
+		}
+		reassignTo = &id
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.shopItemService.DeleteCategory(userID, categoryID, reassignTo); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted"})
+}
+
+func writeCategoryError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrInvalidCategoryName, service.ErrCategoryNotOwned, service.ErrCategoryDepthExceeded,
+		service.ErrCategoryCycle, service.ErrInvalidReassignTarget:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrNoShopOwned, service.ErrCategoryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrCategoryExists, service.ErrCategoryInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ===============================================
 // 3. ITEM CRUD METHODS (dari ItemHandler)
 // ===============================================
@@ -116,7 +384,6 @@ func (h *ShopItemHandler) CreateItem(c *gin.Context) {
 	fmt.Println("Content-Type:", c.GetHeader("Content-Type"))
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	role := c.MustGet("role_name").(string)
 
 	// --- FORM MULTIPART ---
 	form, err := c.MultipartForm()
@@ -173,6 +440,15 @@ func (h *ShopItemHandler) CreateItem(c *gin.Context) {
 		CategoryID: categoryID,
 	}
 
+	if raw := get("global_category_id"); raw != "" {
+		globalCategoryID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid global_category_id"})
+			return
+		}
+		input.GlobalCategoryID = &globalCategoryID
+	}
+
 	// --- Images ---
 	files := form.File["images"]
 
@@ -190,9 +466,22 @@ func (h *ShopItemHandler) CreateItem(c *gin.Context) {
 	}
 
 	// --- Service ---
-	item, images, err := h.shopItemService.CreateItem(userID, role, input, imageURLs) // Memanggil service gabungan
+	item, images, err := h.shopItemService.CreateItem(userID, input, imageURLs) // Memanggil service gabungan
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		// item ditolak sebelum tersimpan: file yang sudah diupload tidak dipakai siapa pun
+		if item == nil {
+			removeUploads(imageURLs)
+		}
+		switch err {
+		case service.ErrShopNotVerified, service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case service.ErrInvalidGlobalCategory, service.ErrCategoryNotOwned, service.ErrInvalidStock, service.ErrInvalidPrice:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrNoShopOwned:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -202,6 +491,19 @@ func (h *ShopItemHandler) CreateItem(c *gin.Context) {
 	})
 }
 
+// removeUploads menghapus file upload (gambar item atau toko) dari disk berdasarkan URL
+// publiknya. URL kosong atau di luar /uploads/ dilewati.
+func removeUploads(imageURLs []string) {
+	for _, url := range imageURLs {
+		if !strings.HasPrefix(url, "/uploads/") || strings.Contains(url, "..") {
+			continue
+		}
+		if err := os.Remove(strings.TrimPrefix(url, "/")); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove upload %s: %v", url, err)
+		}
+	}
+}
+
 func (h *ShopItemHandler) UpdateItem(c *gin.Context) {
 	idStr := c.Param("id")
 	itemID, err := uuid.Parse(idStr)
@@ -219,6 +521,18 @@ func (h *ShopItemHandler) UpdateItem(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	updatedItem, err := h.shopItemService.UpdateItem(userID, itemID, input) // Memanggil service gabungan
+	if err == service.ErrShopNotVerified || err == service.ErrShopPermissionDenied || err == service.ErrShopAccessRevoked {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err == service.ErrInvalidGlobalCategory || err == service.ErrGlobalCategoryConflict || err == service.ErrInvalidItemState || err == service.ErrCategoryNotOwned {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == service.ErrItemModerated || err == service.ErrDraftNotPublished {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		// Asumsi error handling di service sudah mencakup unauthorized/not found
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -239,10 +553,90 @@ func (h *ShopItemHandler) DeleteItem(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 
 	if err := h.shopItemService.DeleteItem(userID, itemID); err != nil { // Memanggil service gabungan
+		if err == service.ErrShopPermissionDenied || err == service.ErrShopAccessRevoked {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err == service.ErrItemModerated {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		// Asumsi error handling di service sudah mencakup unauthorized/not found
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "item archived/deleted successfully"})
-}
\ No newline at end of file
+}
+func (h *ShopItemHandler) ListMyItems(c *gin.Context) {
+	var filter entity.InventoryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query", "detail": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	items, err := h.shopItemService.ListInventory(userID, filter)
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+func (h *ShopItemHandler) BulkUpdateItemStatus(c *gin.Context) {
+	var input entity.BulkItemStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	result, err := h.shopItemService.BulkUpdateItemStatus(userID, input)
+	if err != nil {
+		writeInventoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func writeInventoryError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrShopPermissionDenied, service.ErrShopAccessRevoked, service.ErrShopNotVerified:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrNoShopOwned:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *ShopItemHandler) PublishItem(c *gin.Context) {
+	itemID, ok := parseUUIDParam(c, "id", "invalid item id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	item, images, err := h.shopItemService.PublishItem(userID, itemID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDraftIncomplete):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrShopPermissionDenied), errors.Is(err, service.ErrShopAccessRevoked), errors.Is(err, service.ErrShopNotVerified),
+			errors.Is(err, service.ErrItemNotInShop):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrItemNotFound), errors.Is(err, service.ErrNoShopOwned):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrItemNotDraft):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "item published", "item": item, "images": images})
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ShopMemberHandler struct {
+	memberService *service.ShopMemberService
+}
+
+func NewShopMemberHandler(memberService *service.ShopMemberService) *ShopMemberHandler {
+	return &ShopMemberHandler{memberService: memberService}
+}
+
+func (h *ShopMemberHandler) ListMembers(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	members, err := h.memberService.ListMembers(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+func (h *ShopMemberHandler) Invite(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input entity.InviteShopMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	inv, err := h.memberService.Invite(userID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, inv)
+}
+
+func (h *ShopMemberHandler) ListShopInvitations(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	invitations, err := h.memberService.ListShopInvitations(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+func (h *ShopMemberHandler) RevokeInvitation(c *gin.Context) {
+	invitationID, ok := parseUUIDParam(c, "id", "invalid invitation id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.memberService.RevokeInvitation(userID, invitationID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked"})
+}
+
+func (h *ShopMemberHandler) UpdateMemberRole(c *gin.Context) {
+	memberUserID, ok := parseUUIDParam(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	var input entity.UpdateShopMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	member, err := h.memberService.UpdateMemberRole(userID, memberUserID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *ShopMemberHandler) RemoveMember(c *gin.Context) {
+	memberUserID, ok := parseUUIDParam(c, "userId", "invalid user id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.memberService.RemoveMember(userID, memberUserID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+func (h *ShopMemberHandler) LeaveShop(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.memberService.LeaveShop(userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "you have left the shop"})
+}
+
+func (h *ShopMemberHandler) ListMyInvitations(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	invitations, err := h.memberService.ListMyInvitations(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+func (h *ShopMemberHandler) AcceptInvitation(c *gin.Context) {
+	invitationID, ok := parseUUIDParam(c, "id", "invalid invitation id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	member, err := h.memberService.AcceptInvitation(userID, invitationID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *ShopMemberHandler) DeclineInvitation(c *gin.Context) {
+	invitationID, ok := parseUUIDParam(c, "id", "invalid invitation id")
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.memberService.DeclineInvitation(userID, invitationID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation declined"})
+}
+
+func (h *ShopMemberHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrCannotInviteSelf:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrNoShopOwned, service.ErrInviteeNotFound, service.ErrShopInvitationNotFound, service.ErrShopMemberNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrAlreadyShopMember, service.ErrInvitationPending, service.ErrShopInvitationNotPending, service.ErrCannotModifyOwner:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
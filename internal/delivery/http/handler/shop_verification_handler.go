@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var verificationDocExts = map[string]bool{".pdf": true, ".jpg": true, ".jpeg": true, ".png": true}
+
+type ShopVerificationHandler struct {
+	verificationService *service.ShopVerificationService
+	cfg                 config.ShopVerificationConfig
+}
+
+func NewShopVerificationHandler(verificationService *service.ShopVerificationService, cfg config.ShopVerificationConfig) *ShopVerificationHandler {
+	return &ShopVerificationHandler{verificationService: verificationService, cfg: cfg}
+}
+
+func (h *ShopVerificationHandler) Submit(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.verificationService.CanSubmit(userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid form data"})
+		return
+	}
+
+	var docs []entity.VerificationDocument
+	for _, docType := range []string{entity.VerificationDocIdentity, entity.VerificationDocBusiness} {
+		files := form.File[docType]
+		if len(files) == 0 {
+			continue
+		}
+		doc, err := h.saveDocument(files[0], docType)
+		if err != nil {
+			h.removeDocuments(docs)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + docType + " document", "detail": err.Error()})
+			return
+		}
+		docs = append(docs, *doc)
+	}
+
+	v, err := h.verificationService.Submit(userID, c.PostForm("note"), docs)
+	if err != nil {
+		h.removeDocuments(docs)
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, v)
+}
+
+// saveDocument menyimpan dokumen ke direktori privat (bukan uploads/) dan mengembalikan path relatifnya.
+func (h *ShopVerificationHandler) saveDocument(file *multipart.FileHeader, docType string) (*entity.VerificationDocument, error) {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !verificationDocExts[ext] {
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+	if file.Size > h.cfg.MaxDocumentSize {
+		return nil, fmt.Errorf("file larger than %d MB", h.cfg.MaxDocumentSize>>20)
+	}
+
+	if err := os.MkdirAll(h.cfg.StorageDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	storagePath := uuid.New().String() + ext
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Join(h.cfg.StorageDir, storagePath), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer dst.Close()
+
+	if _, err := dst.ReadFrom(src); err != nil {
+		return nil, err
+	}
+
+	return &entity.VerificationDocument{
+		DocType:      docType,
+		StoragePath:  storagePath,
+		OriginalName: filepath.Base(file.Filename),
+		ContentType:  file.Header.Get("Content-Type"),
+		SizeBytes:    file.Size,
+	}, nil
+}
+
+func (h *ShopVerificationHandler) removeDocuments(docs []entity.VerificationDocument) {
+	for _, doc := range docs {
+		os.Remove(filepath.Join(h.cfg.StorageDir, doc.StoragePath))
+	}
+}
+
+func (h *ShopVerificationHandler) GetMine(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	status, err := h.verificationService.GetMyVerification(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *ShopVerificationHandler) ListShops(c *gin.Context) {
+	var filter entity.AdminShopFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query", "detail": err.Error()})
+		return
+	}
+
+	shops, err := h.verificationService.ListShops(filter)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shops})
+}
+
+func (h *ShopVerificationHandler) GetShopVerification(c *gin.Context) {
+	shopID, ok := parseUUIDParam(c, "id", "invalid shop id")
+	if !ok {
+		return
+	}
+
+	v, err := h.verificationService.GetShopVerification(shopID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *ShopVerificationHandler) DownloadDocument(c *gin.Context) {
+	shopID, ok := parseUUIDParam(c, "id", "invalid shop id")
+	if !ok {
+		return
+	}
+	docID, ok := parseUUIDParam(c, "docId", "invalid document id")
+	if !ok {
+		return
+	}
+
+	doc, err := h.verificationService.GetDocument(shopID, docID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(filepath.Join(h.cfg.StorageDir, filepath.Base(doc.StoragePath)), doc.OriginalName)
+}
+
+func (h *ShopVerificationHandler) Approve(c *gin.Context) {
+	h.review(c, h.verificationService.Approve)
+}
+
+func (h *ShopVerificationHandler) Reject(c *gin.Context) {
+	h.review(c, h.verificationService.Reject)
+}
+
+func (h *ShopVerificationHandler) review(c *gin.Context, action func(adminID, shopID uuid.UUID, input entity.ReviewShopVerificationInput) (*entity.ShopVerification, error)) {
+	shopID, ok := parseUUIDParam(c, "id", "invalid shop id")
+	if !ok {
+		return
+	}
+
+	// body opsional untuk approve, berisi catatan review
+	var input entity.ReviewShopVerificationInput
+	_ = c.ShouldBindJSON(&input)
+
+	adminID := c.MustGet("user_id").(uuid.UUID)
+	v, err := action(adminID, shopID, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+func (h *ShopVerificationHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrShopPermissionDenied, service.ErrShopAccessRevoked:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case service.ErrIdentityDocumentRequired, service.ErrRejectionNoteRequired, service.ErrInvalidVerificationStatus:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrNoShopOwned, service.ErrVerificationNotFound, service.ErrVerificationDocNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrShopAlreadyVerified, service.ErrVerificationPending, service.ErrVerificationNotPending:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	entity "home-market/internal/domain"
+	service "home-market/internal/service/postgresql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TaxonomyHandler struct {
+	taxonomyService *service.TaxonomyService
+}
+
+func NewTaxonomyHandler(taxonomyService *service.TaxonomyService) *TaxonomyHandler {
+	return &TaxonomyHandler{taxonomyService: taxonomyService}
+}
+
+func (h *TaxonomyHandler) GetTree(c *gin.Context) {
+	tree, err := h.taxonomyService.GetTree()
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tree})
+}
+
+func (h *TaxonomyHandler) Create(c *gin.Context) {
+	var input entity.CreateGlobalCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	category, err := h.taxonomyService.Create(input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *TaxonomyHandler) Update(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id", "invalid global category id")
+	if !ok {
+		return
+	}
+
+	var input entity.UpdateGlobalCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+
+	category, err := h.taxonomyService.Update(id, input)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *TaxonomyHandler) Delete(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id", "invalid global category id")
+	if !ok {
+		return
+	}
+
+	var reassignTo *uuid.UUID
+	if raw := c.Query("reassign_to"); raw != "" {
+		target, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reassign_to"})
+			return
+		}
+		reassignTo = &target
+	}
+
+	if err := h.taxonomyService.Delete(id, reassignTo); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "global category deleted"})
+}
+
+func (h *TaxonomyHandler) writeError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidCategoryName, service.ErrInvalidSlug, service.ErrCategoryDepthExceeded,
+		service.ErrCategoryCycle, service.ErrInvalidGlobalReassignTo:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrGlobalCategoryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrSlugExists, service.ErrGlobalCategoryInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
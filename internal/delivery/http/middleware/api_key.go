@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(raw string) (*entity.APIKeyPrincipal, error)
+}
+
+// quotaExceeded diimplementasikan oleh error kuota dari service API key.
+type quotaExceeded interface {
+	error
+	QuotaLimit() int
+	QuotaResetAt() time.Time
+}
+
+// AuthOrAPIKey menerima header X-API-Key (integrasi toko) sebagai alternatif
+// Bearer token. Key wajib punya scope yang diminta; request dijalankan atas nama
+// pemilik toko, tanpa role/permission user.
+func AuthOrAPIKey(sessions SessionValidator, cookies config.CookieAuthConfig, keys APIKeyAuthenticator, scope string) gin.HandlerFunc {
+	bearer := AuthRequired(sessions, cookies)
+
+	return func(c *gin.Context) {
+		raw := c.GetHeader("X-API-Key")
+		if raw == "" {
+			bearer(c)
+			return
+		}
+
+		principal, err := keys.AuthenticateAPIKey(raw)
+		if err != nil {
+			var quota quotaExceeded
+			if errors.As(err, &quota) {
+				retryAfter := int(math.Ceil(time.Until(quota.QuotaResetAt()).Seconds()))
+				c.Header("X-RateLimit-Limit", strconv.Itoa(quota.QuotaLimit()))
+				c.Header("X-RateLimit-Remaining", "0")
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+			c.Abort()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(principal.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(principal.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(principal.ResetAt.Unix(), 10))
+
+		allowed := false
+		for _, s := range principal.Scopes {
+			if s == scope {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "api key scope denied: needed '" + scope + "'"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", principal.UserID)
+		c.Set("roles", []string{})
+		c.Set("permissions", []string{})
+		c.Set("mfa", false)
+		c.Set("api_key_id", principal.KeyID)
+		c.Set("shop_id", principal.ShopID)
+
+		c.Next()
+	}
+}
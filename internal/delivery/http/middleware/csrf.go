@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"home-market/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// validCSRF memeriksa double-submit token: nilai header harus sama dengan cookie CSRF.
+// Situs lain bisa membuat browser mengirim cookie, tapi tidak bisa membaca nilainya.
+func validCSRF(c *gin.Context, cookies config.CookieAuthConfig) bool {
+	cookie, err := c.Cookie(cookies.CSRFCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+	header := c.GetHeader(cookies.CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// CSRFRequired melindungi endpoint yang membaca cookie refresh token (refresh, logout).
+// Request tanpa cookie tersebut (client Bearer biasa) diteruskan apa adanya.
+func CSRFRequired(cookies config.CookieAuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cookies.Enabled || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if _, err := c.Cookie(cookies.RefreshCookie); err != nil {
+			c.Next()
+			return
+		}
+
+		if !validCSRF(c, cookies) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "invalid or missing csrf token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
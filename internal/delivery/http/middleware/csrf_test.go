@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Exit(m.Run())
+}
+
+type allowAllSessions struct{}
+
+func (allowAllSessions) ValidateClaims(claims *entity.JWTClaims) error { return nil }
+
+func testCookieConfig(enabled bool) config.CookieAuthConfig {
+	cfg := config.LoadCookieAuth()
+	cfg.Enabled = enabled
+	return cfg
+}
+
+// csrfRequest menyiapkan request dengan cookie dan header CSRF opsional.
+type csrfRequest struct {
+	method      string
+	cookies     map[string]string
+	csrfHeader  string
+	bearerToken string
+}
+
+func (r csrfRequest) serve(t *testing.T, handler gin.HandlerFunc, cfg config.CookieAuthConfig) int {
+	t.Helper()
+	router := gin.New()
+	router.Handle(r.method, "/test", handler, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(r.method, "/test", nil)
+	for name, value := range r.cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	if r.csrfHeader != "" {
+		req.Header.Set(cfg.CSRFHeader, r.csrfHeader)
+	}
+	if r.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.bearerToken)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestCSRFRequired(t *testing.T) {
+	cfg := testCookieConfig(true)
+	withRefresh := map[string]string{cfg.RefreshCookie: "refresh", cfg.CSRFCookie: "csrf-value"}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		req      csrfRequest
+		want     int
+	}{
+		{name: "matching header", req: csrfRequest{method: http.MethodPost, cookies: withRefresh, csrfHeader: "csrf-value"}, want: http.StatusOK},
+		{name: "missing header", req: csrfRequest{method: http.MethodPost, cookies: withRefresh}, want: http.StatusForbidden},
+		{name: "wrong header", req: csrfRequest{method: http.MethodPost, cookies: withRefresh, csrfHeader: "other"}, want: http.StatusForbidden},
+		{
+			name: "missing csrf cookie",
+			req:  csrfRequest{method: http.MethodPost, cookies: map[string]string{cfg.RefreshCookie: "refresh"}, csrfHeader: "csrf-value"},
+			want: http.StatusForbidden,
+		},
+		{name: "no refresh cookie (bearer client)", req: csrfRequest{method: http.MethodPost}, want: http.StatusOK},
+		{name: "safe method", req: csrfRequest{method: http.MethodGet, cookies: withRefresh}, want: http.StatusOK},
+		{name: "cookie mode disabled", disabled: true, req: csrfRequest{method: http.MethodPost, cookies: withRefresh}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testCookieConfig(!tt.disabled)
+			if got := tt.req.serve(t, CSRFRequired(cfg), cfg); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthRequiredCookieCSRF(t *testing.T) {
+	token, err := utils.GenerateToken(&entity.JWTClaims{UserID: uuid.New(), RoleName: "buyer", Roles: []string{"buyer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testCookieConfig(true)
+	withAccess := map[string]string{cfg.AccessCookie: token, cfg.CSRFCookie: "csrf-value"}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		req      csrfRequest
+		want     int
+	}{
+		{name: "cookie GET without csrf", req: csrfRequest{method: http.MethodGet, cookies: withAccess}, want: http.StatusOK},
+		{name: "cookie POST with csrf", req: csrfRequest{method: http.MethodPost, cookies: withAccess, csrfHeader: "csrf-value"}, want: http.StatusOK},
+		{name: "cookie POST without csrf", req: csrfRequest{method: http.MethodPost, cookies: withAccess}, want: http.StatusForbidden},
+		{name: "cookie DELETE with wrong csrf", req: csrfRequest{method: http.MethodDelete, cookies: withAccess, csrfHeader: "x"}, want: http.StatusForbidden},
+		{name: "bearer POST needs no csrf", req: csrfRequest{method: http.MethodPost, bearerToken: token}, want: http.StatusOK},
+		{name: "cookie ignored when mode disabled", disabled: true, req: csrfRequest{method: http.MethodGet, cookies: withAccess}, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testCookieConfig(!tt.disabled)
+			if got := tt.req.serve(t, AuthRequired(allowAllSessions{}, cfg), cfg); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,30 +4,60 @@ import (
 	"net/http"
 	"strings"
 
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
 	"home-market/pkg"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-func AuthRequired() gin.HandlerFunc {
+// SessionValidator memastikan token yang valid secara signature masih berlaku
+// terhadap state user terbaru (akun aktif, token_version).
+type SessionValidator interface {
+	ValidateClaims(claims *entity.JWTClaims) error
+}
+
+// AuthRequired menerima access token dari header Authorization, atau dari cookie
+// HttpOnly jika mode cookie aktif. Request berbasis cookie yang mengubah state
+// wajib membawa token CSRF.
+func AuthRequired(sessions SessionValidator, cookies config.CookieAuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
+		var token string
+		if auth := c.GetHeader("Authorization"); auth != "" {
+			parts := strings.Split(auth, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+				c.Abort()
+				return
+			}
+			token = parts[1]
+		} else if cookies.Enabled {
+			if cookie, err := c.Cookie(cookies.AccessCookie); err == nil && cookie != "" {
+				if !isSafeMethod(c.Request.Method) && !validCSRF(c, cookies) {
+					c.JSON(http.StatusForbidden, gin.H{"error": "invalid or missing csrf token"})
+					c.Abort()
+					return
+				}
+				token = cookie
+			}
+		}
+
+		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
 			c.Abort()
 			return
 		}
 
-		parts := strings.Split(auth, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+		claims, err := utils.ValidateToken(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.ValidateToken(parts[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		if err := sessions.ValidateClaims(claims); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session is no longer valid"})
 			c.Abort()
 			return
 		}
@@ -36,32 +66,44 @@ func AuthRequired() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("role_id", claims.RoleID)
 		c.Set("role_name", claims.RoleName)
+
+		// token lama (sebelum multi-role) hanya membawa role utama
+		roles := claims.Roles
+		if len(roles) == 0 && claims.RoleName != "" {
+			roles = []string{claims.RoleName}
+		}
+		c.Set("roles", roles)
 		c.Set("permissions", claims.Permissions)
+		c.Set("mfa", claims.MFA)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
 }
 
+// RoleAllowed meneruskan request jika salah satu role user termasuk allowedRoles.
 func RoleAllowed(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		roleAny, exists := c.Get("role_name")
+		rolesAny, exists := c.Get("roles")
 		if !exists {
 			c.JSON(http.StatusForbidden, gin.H{"error": "role missing in context"})
 			c.Abort()
 			return
 		}
 
-		role, ok := roleAny.(string)
+		roles, ok := rolesAny.([]string)
 		if !ok {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid role type"})
 			c.Abort()
 			return
 		}
 
-		for _, r := range allowedRoles {
-			if strings.EqualFold(role, r) {
-				c.Next()
-				return
+		for _, role := range roles {
+			for _, r := range allowedRoles {
+				if strings.EqualFold(role, r) {
+					c.Next()
+					return
+				}
 			}
 		}
 
@@ -99,3 +141,57 @@ func PermissionRequired(needed string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+type EmailVerificationChecker interface {
+	IsEmailVerified(userID uuid.UUID) (bool, error)
+}
+
+// EmailVerifiedRequired menolak request dari user yang emailnya belum terverifikasi.
+// Jika enabled false (kebijakan dimatikan), middleware langsung meneruskan request.
+func EmailVerifiedRequired(checker EmailVerificationChecker, enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		userID, ok := c.MustGet("user_id").(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+
+		verified, err := checker.IsEmailVerified(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "email address must be verified first"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// MFARequired hanya meneruskan request jika access token diterbitkan setelah verifikasi 2FA.
+func MFARequired(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		if mfa, _ := c.Get("mfa"); mfa != true {
+			c.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication is required for this action"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"database/sql"
 	"log"
 	"github.com/google/uuid"
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/internal/mail"
+	"home-market/internal/oidc"
 	httpHandler "home-market/internal/delivery/http/handler"
 	repo "home-market/internal/repository/postgresql"
 	mongorepo "home-market/internal/repository/mongodb"
@@ -16,7 +20,9 @@ import (
 	_ "home-market/docs"
 )
 
-func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) {
+// SetupRoute mendaftarkan semua route. Service dengan worker background dikembalikan
+// agar main yang memutuskan kapan worker dijalankan.
+func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) *service.PrivacyService {
 	// --- 1. Ambil default role ---
 	var defaultRoleID uuid.UUID
 	if err := db.QueryRow(`SELECT id FROM roles WHERE name = $1`, "buyer").Scan(&defaultRoleID); err != nil {
@@ -25,26 +31,70 @@ func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) {
 
 	// --- 2. INIT REPOSITORIES (Dependencies Inti) ---
 	userRepo := repo.NewUserRepository(db)
+	refreshTokenRepo := repo.NewRefreshTokenRepository(db)
+	sessionRepo := repo.NewSessionRepository(db)
+	userTokenRepo := repo.NewUserTokenRepository(db)
+	mfaRepo := repo.NewMFARepository(db)
+	roleRepo := repo.NewRoleRepository(db)
+	permissionRepo := repo.NewPermissionRepository(db)
+	apiKeyRepo := repo.NewAPIKeyRepository(db)
+	identityRepo := repo.NewIdentityRepository(db)
+	privacyRepo := repo.NewPrivacyRepository(db)
+	roleApplicationRepo := repo.NewRoleApplicationRepository(db)
 	shopRepo := repo.NewShopRepository(db)
+	shopMemberRepo := repo.NewShopMemberRepository(db)
+	shopVerificationRepo := repo.NewShopVerificationRepository(db)
 	categoryRepo := repo.NewCategoryRepository(db)
+	globalCategoryRepo := repo.NewGlobalCategoryRepository(db)
 	itemRepo := repo.NewItemRepository(db)
 	orderRepo := repo.NewOrderRepository(db)
 	offerRepo := repo.NewOfferRepository(db) 
 	logRepo := mongorepo.NewLogRepository(mongoclient) 
 
 	// --- 3. INIT SERVICES ---
-	authService := service.NewAuthService(userRepo, defaultRoleID)
+	sessionGuard := service.NewSessionGuard(userRepo, sessionRepo)
+	mailer, err := mail.NewSender(config.LoadMail())
+	if err != nil {
+		log.Fatalf("failed to configure mail: %v", err)
+	}
+	mfaConfig, err := config.LoadMFA()
+	if err != nil {
+		log.Fatalf("failed to load 2FA config: %v", err)
+	}
+	mfaService := service.NewMFAService(mfaRepo, userRepo, mfaConfig)
+	loginLimiter := service.NewLoginLimiter(service.NewMemoryLoginAttemptStore(), config.LoadLoginProtection(), logRepo)
+	passwordPolicy, err := service.NewPasswordPolicy(config.LoadPassword())
+	if err != nil {
+		log.Fatalf("failed to load password policy: %v", err)
+	}
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, sessionRepo, userTokenRepo, mailer, sessionGuard, mfaService, loginLimiter, passwordPolicy, defaultRoleID)
 	
+	// Kebijakan verifikasi toko (KYC) dipakai service item, marketplace, dan handler upload dokumen
+	shopVerificationConfig := config.LoadShopVerification()
+
 	// INIT SERVICE GABUNGAN (Shop, Category, Item CRUD)
-	shopItemService := service.NewShopItemService(shopRepo, categoryRepo, itemRepo, orderRepo) 
+	shopItemService := service.NewShopItemService(shopRepo, shopMemberRepo, categoryRepo, globalCategoryRepo, itemRepo, orderRepo, offerRepo, logRepo, shopVerificationConfig.RequireVerifiedToList) 
 
 	// Service yang tetap terpisah
-	orderService := service.NewOrderService(orderRepo, shopRepo, logRepo) 
-	offerService := service.NewOfferService(offerRepo, itemRepo, shopRepo, logRepo) 
-	adminService := service.NewAdminService(userRepo, itemRepo) 
+	orderService := service.NewOrderService(orderRepo, shopRepo, shopMemberRepo, logRepo, shopVerificationConfig.RequireVerifiedToList) 
+	offerService := service.NewOfferService(offerRepo, itemRepo, shopRepo, shopMemberRepo, logRepo) 
+	adminService := service.NewAdminService(userRepo, itemRepo, sessionGuard, loginLimiter) 
+	roleApplicationService := service.NewRoleApplicationService(roleApplicationRepo, roleRepo, userRepo, logRepo, sessionGuard)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, userRepo, sessionGuard)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, shopRepo, shopMemberRepo, userRepo)
+	shopMemberService := service.NewShopMemberService(shopMemberRepo, shopRepo, userRepo, logRepo)
+	shopVerificationService := service.NewShopVerificationService(shopVerificationRepo, shopRepo, shopMemberRepo, logRepo)
+	taxonomyService := service.NewTaxonomyService(globalCategoryRepo)
+	oidcService := service.NewOIDCService(oidc.NewProvider(config.LoadOIDC()), oidc.NewStateStore(), identityRepo, userRepo, authService, defaultRoleID)
+
+	// Permintaan hapus akun dieksekusi worker (dijalankan dari main) setelah masa tenggang
+	privacyConfig := config.LoadPrivacy()
+	privacyService := service.NewPrivacyService(userRepo, privacyRepo, sessionRepo, orderRepo, offerRepo, shopRepo, shopVerificationRepo, logRepo, sessionGuard, privacyConfig.DeletionGracePeriod, shopVerificationConfig.StorageDir)
 
 	// --- 4. INIT HANDLERS ---
-	authHandler := httpHandler.NewAuthHandler(authService)
+	cookieAuth := config.LoadCookieAuth()
+	authHandler := httpHandler.NewAuthHandler(authService, cookieAuth)
+	mfaHandler := httpHandler.NewMFAHandler(mfaService)
 	// INIT HANDLER GABUNGAN
 	shopItemHandler := httpHandler.NewShopItemHandler(shopItemService) 
 
@@ -52,8 +102,20 @@ func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) {
 	orderHandler := httpHandler.NewOrderHandler(orderService) 
 	offerHandler := httpHandler.NewOfferHandler(offerService) 
 	adminHandler := httpHandler.NewAdminHandler(adminService)
+	roleApplicationHandler := httpHandler.NewRoleApplicationHandler(roleApplicationService)
+	roleHandler := httpHandler.NewRoleHandler(roleService)
+	apiKeyHandler := httpHandler.NewAPIKeyHandler(apiKeyService)
+	shopMemberHandler := httpHandler.NewShopMemberHandler(shopMemberService)
+	shopVerificationHandler := httpHandler.NewShopVerificationHandler(shopVerificationService, shopVerificationConfig)
+	taxonomyHandler := httpHandler.NewTaxonomyHandler(taxonomyService)
+	oidcHandler := httpHandler.NewOIDCHandler(oidcService, cookieAuth)
+	privacyHandler := httpHandler.NewPrivacyHandler(privacyService, shopVerificationConfig)
 
 	// --- 5. DEFINISIKAN GROUP ROUTE ---
+	authPolicy := config.LoadAuthPolicy()
+	authRequired := middleware.AuthRequired(sessionGuard, cookieAuth)
+	csrfProtected := middleware.CSRFRequired(cookieAuth)
+	emailVerified := middleware.EmailVerifiedRequired(authService, authPolicy.RequireEmailVerification)
 	api := app.Group("/api")
 
 	// --- SWAGGER/OPENAPI DOCUMENTATION ROUTE ---
@@ -62,29 +124,102 @@ func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) {
 		ginSwagger.DefaultModelsExpandDepth(0),
 	))
 
+	// --- Public key untuk verifikasi JWT oleh service lain ---
+	app.GET("/.well-known/jwks.json", httpHandler.JWKS)
+
 	// --- Authentication & Profile ---
 	auth := api.Group("/auth")
 	auth.POST("/register", authHandler.Register)
 	auth.POST("/login", authHandler.Login)
-	auth.POST("/refresh", authHandler.Refresh)
-	auth.GET("/profile", middleware.AuthRequired(), authHandler.Profile)
+	auth.POST("/refresh", csrfProtected, authHandler.Refresh)
+	auth.POST("/logout", csrfProtected, authHandler.Logout)
+	auth.POST("/forgot-password", authHandler.ForgotPassword)
+	auth.POST("/reset-password", authHandler.ResetPassword)
+	auth.GET("/verify-email", authHandler.VerifyEmail)
+	auth.POST("/resend-verification", authRequired, authHandler.ResendVerification)
+	auth.POST("/logout-all", authRequired, authHandler.LogoutAll)
+	auth.GET("/sessions", authRequired, authHandler.ListSessions)
+	auth.DELETE("/sessions/:id", authRequired, authHandler.RevokeSession)
+	auth.GET("/profile", authRequired, authHandler.Profile)
+	auth.PATCH("/profile", authRequired, authHandler.UpdateProfile)
+	auth.POST("/change-password", authRequired, authHandler.ChangePassword)
+	auth.POST("/change-email", authRequired, authHandler.RequestEmailChange)
+	auth.GET("/confirm-email-change", authHandler.ConfirmEmailChange)
+
+	// --- Data pribadi: ekspor dan hapus akun ---
+	me := api.Group("/me", authRequired)
+	me.GET("/export", privacyHandler.Export)
+	me.POST("/deletion", privacyHandler.RequestDeletion)
+	me.GET("/deletion", privacyHandler.GetDeletion)
+	me.DELETE("/deletion", privacyHandler.CancelDeletion)
+	me.GET("/shop-invitations", shopMemberHandler.ListMyInvitations)
+	me.POST("/shop-invitations/:id/accept", shopMemberHandler.AcceptInvitation)
+	me.POST("/shop-invitations/:id/decline", shopMemberHandler.DeclineInvitation)
+
+	// --- Login via provider OpenID Connect ---
+	auth.GET("/oidc/login", oidcHandler.Login)
+	auth.GET("/oidc/callback", oidcHandler.Callback)
+
+	// --- Two-Factor Authentication ---
+	auth.POST("/2fa/verify", authHandler.VerifyMFA)
+	mfa := auth.Group("/2fa", authRequired)
+	mfa.POST("/setup", mfaHandler.Setup)
+	mfa.POST("/enable", mfaHandler.Enable)
+	mfa.POST("/disable", mfaHandler.Disable)
+	mfa.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+	// --- Role Applications (buyer -> seller/giver) ---
+	roleApps := api.Group("/role-applications", authRequired)
+	roleApps.POST("", roleApplicationHandler.Submit)
+	roleApps.GET("/my", roleApplicationHandler.ListMine)
 
 	// --- Shop & Categories (Arahkan ke Handler Gabungan) ---
 	shop := api.Group("/shops")
-	shop.POST("/", middleware.AuthRequired(), shopItemHandler.CreateShop) // DIGANTI
+	shop.POST("/", authRequired, middleware.PermissionRequired(entity.PermShopManage), shopItemHandler.CreateShop) // DIGANTI
+	shop.GET("", shopItemHandler.ListShops)
+	shop.GET("/me", authRequired, shopItemHandler.GetMyShop)
+	shop.PATCH("/me", authRequired, shopItemHandler.UpdateMyShop)
+	shop.GET("/me/availability", authRequired, shopItemHandler.GetMyAvailability)
+	shop.PUT("/me/vacation", authRequired, shopItemHandler.UpdateVacation)
+	shop.PUT("/me/hours", authRequired, shopItemHandler.UpdateOperatingHours)
+	// inventori seller: semua status item, termasuk draft dan moderated; bisa dibaca
+	// sistem POS/inventory lewat X-API-Key (scope items:read)
+	shop.GET("/me/items", middleware.AuthOrAPIKey(sessionGuard, cookieAuth, apiKeyService, entity.APIScopeItemsRead), shopItemHandler.ListMyItems)
+	shop.PATCH("/me/items/status", authRequired, shopItemHandler.BulkUpdateItemStatus)
+	shop.GET("/me/api-keys", authRequired, apiKeyHandler.List)
+	shop.POST("/me/api-keys", authRequired, apiKeyHandler.Create)
+	shop.DELETE("/me/api-keys/:id", authRequired, apiKeyHandler.Revoke)
+	// staf toko: izin dicek per role keanggotaan (owner/manager/packer) di service
+	shop.GET("/me/members", authRequired, shopMemberHandler.ListMembers)
+	shop.PATCH("/me/members/:userId", authRequired, shopMemberHandler.UpdateMemberRole)
+	shop.DELETE("/me/members/:userId", authRequired, shopMemberHandler.RemoveMember)
+	shop.DELETE("/me/membership", authRequired, shopMemberHandler.LeaveShop)
+	shop.GET("/me/invitations", authRequired, shopMemberHandler.ListShopInvitations)
+	shop.POST("/me/invitations", authRequired, shopMemberHandler.Invite)
+	shop.DELETE("/me/invitations/:id", authRequired, shopMemberHandler.RevokeInvitation)
+	// verifikasi toko: dokumen disimpan di direktori privat, hanya bisa diunduh admin
+	shop.GET("/me/verification", authRequired, shopVerificationHandler.GetMine)
+	shop.POST("/me/verification", authRequired, shopVerificationHandler.Submit)
+	shop.GET("/:id", shopItemHandler.GetShop)
 	cat := api.Group("/categories")
-	cat.POST("/", middleware.AuthRequired(), shopItemHandler.CreateCategory) // DIGANTI
+	cat.POST("/", authRequired, shopItemHandler.CreateCategory) // DIGANTI
+	cat.GET("", authRequired, shopItemHandler.ListCategories)
+	cat.PATCH("/:id", authRequired, shopItemHandler.UpdateCategory)
+	cat.DELETE("/:id", authRequired, shopItemHandler.DeleteCategory)
 
 	// --- Item CRUD (Seller) (Arahkan ke Handler Gabungan) ---
-	items := api.Group("/items", middleware.AuthRequired())
-	items.POST("", shopItemHandler.CreateItem) // DIGANTI
-	items.PUT("/:id", shopItemHandler.UpdateItem) // DIGANTI
-	items.DELETE("/:id", shopItemHandler.DeleteItem) // DIGANTI
+	// PUT juga bisa dipanggil sistem POS/inventory seller lewat X-API-Key (scope items:write)
+	items := api.Group("/items")
+	items.POST("", authRequired, shopItemHandler.CreateItem) // DIGANTI
+	items.PUT("/:id", middleware.AuthOrAPIKey(sessionGuard, cookieAuth, apiKeyService, entity.APIScopeItemsWrite), shopItemHandler.UpdateItem) // DIGANTI
+	items.DELETE("/:id", authRequired, shopItemHandler.DeleteItem) // DIGANTI
+	// draft dari penawaran dilengkapi lewat PUT lalu dipublish di sini
+	items.POST("/:id/publish", authRequired, shopItemHandler.PublishItem)
 
 	// --- Offer Management (Giver & Seller) (TIDAK BERUBAH) ---
-	offers := api.Group("/offers", middleware.AuthRequired())
-	offers.POST("", offerHandler.CreateOffer) 
-	offers.GET("/my", offerHandler.GetMyOffers) 
+	offers := api.Group("/offers", authRequired)
+	offers.POST("", middleware.PermissionRequired(entity.PermOfferCreate), emailVerified, offerHandler.CreateOffer)
+	offers.GET("/my", middleware.PermissionRequired(entity.PermOfferCreate), offerHandler.GetMyOffers)
 	offers.GET("/inbox", offerHandler.GetOffersToSeller) 
 	offers.POST("/:id/accept", offerHandler.AcceptOffer)
 	offers.POST("/:id/reject", offerHandler.RejectOffer)
@@ -93,21 +228,59 @@ func SetupRoute(app *gin.Engine, db *sql.DB, mongoclient *mongo.Client) {
 	market := api.Group("/market")
 	market.GET("/items", orderHandler.GetMarketplaceItems) 
 	market.GET("/items/:id", orderHandler.GetItemDetail) 
+	// taksonomi global untuk browsing lintas toko (?global_category_id= di /market/items)
+	api.GET("/taxonomy", taxonomyHandler.GetTree)
 
 	orders := api.Group("/orders")
-	orders.POST("", middleware.AuthRequired(), orderHandler.CreateOrder) 
+	orders.POST("", authRequired, middleware.PermissionRequired(entity.PermOrderCreate), emailVerified, orderHandler.CreateOrder)
 	
-	orders.PATCH("/:id/status", middleware.AuthRequired(), orderHandler.UpdateOrderStatus)
-	orders.POST("/:id/shipping", middleware.AuthRequired(), orderHandler.InputShippingReceipt)
+	// keanggotaan toko + permission global (order:update_status untuk owner, shop:staff untuk staf) dicek di OrderService
+	orders.PATCH("/:id/status", authRequired, orderHandler.UpdateOrderStatus)
+	orders.POST("/:id/shipping", authRequired, orderHandler.InputShippingReceipt)
 	
-	orders.GET("/:id/tracking", middleware.AuthRequired(), orderHandler.GetOrderTracking)
+	orders.GET("/:id/tracking", authRequired, orderHandler.GetOrderTracking)
 
 
-	// --- Admin Group (TIDAK BERUBAH) ---
+	// --- Admin Group (akses per permission, bukan per nama role) ---
 	admin := api.Group("/admin")
-	admin.Use(middleware.AuthRequired(), middleware.RoleAllowed("admin")) 
+	admin.Use(authRequired, middleware.MFARequired(authPolicy.RequireAdminMFA))
 	
-	admin.GET("/users", adminHandler.ListUsers)
-	admin.PATCH("/users/:id/status", adminHandler.BlockUser) 
-	admin.PATCH("/items/:id/moderate", adminHandler.ModerateItem)
+	admin.GET("/users", middleware.PermissionRequired(entity.PermUserRead), adminHandler.ListUsers)
+	admin.PATCH("/users/:id/status", middleware.PermissionRequired(entity.PermUserManage), adminHandler.BlockUser)
+	admin.POST("/users/:id/unlock", middleware.PermissionRequired(entity.PermUserManage), adminHandler.UnlockUser)
+	admin.POST("/users/:id/roles", middleware.PermissionRequired(entity.PermUserManage), roleHandler.AssignUserRole)
+	admin.DELETE("/users/:id/roles/:roleId", middleware.PermissionRequired(entity.PermUserManage), roleHandler.RemoveUserRole)
+	admin.PATCH("/items/:id/moderate", middleware.PermissionRequired(entity.PermItemModerate), adminHandler.ModerateItem)
+	admin.PATCH("/items/:id/unmoderate", middleware.PermissionRequired(entity.PermItemModerate), adminHandler.UnmoderateItem)
+
+	reviewApps := middleware.PermissionRequired(entity.PermRoleApplicationReview)
+	admin.GET("/role-applications", reviewApps, roleApplicationHandler.List)
+	admin.POST("/role-applications/:id/approve", reviewApps, roleApplicationHandler.Approve)
+	admin.POST("/role-applications/:id/reject", reviewApps, roleApplicationHandler.Reject)
+
+	verifyShops := middleware.PermissionRequired(entity.PermShopVerify)
+	admin.GET("/shops", verifyShops, shopVerificationHandler.ListShops)
+	admin.GET("/shops/:id/verification", verifyShops, shopVerificationHandler.GetShopVerification)
+	admin.GET("/shops/:id/verification/documents/:docId", verifyShops, shopVerificationHandler.DownloadDocument)
+	admin.POST("/shops/:id/verification/approve", verifyShops, shopVerificationHandler.Approve)
+	admin.POST("/shops/:id/verification/reject", verifyShops, shopVerificationHandler.Reject)
+
+	taxonomy := admin.Group("/taxonomy", middleware.PermissionRequired(entity.PermTaxonomyManage))
+	taxonomy.POST("", taxonomyHandler.Create)
+	taxonomy.PATCH("/:id", taxonomyHandler.Update)
+	taxonomy.DELETE("/:id", taxonomyHandler.Delete)
+
+	rbac := admin.Group("", middleware.PermissionRequired(entity.PermRoleManage))
+	rbac.GET("/roles", roleHandler.ListRoles)
+	rbac.POST("/roles", roleHandler.CreateRole)
+	rbac.PATCH("/roles/:id", roleHandler.UpdateRole)
+	rbac.DELETE("/roles/:id", roleHandler.DeleteRole)
+	rbac.GET("/roles/:id/permissions", roleHandler.ListRolePermissions)
+	rbac.PUT("/roles/:id/permissions/:permissionId", roleHandler.GrantPermission)
+	rbac.DELETE("/roles/:id/permissions/:permissionId", roleHandler.RevokePermission)
+	rbac.GET("/permissions", roleHandler.ListPermissions)
+	rbac.POST("/permissions", roleHandler.CreatePermission)
+	rbac.DELETE("/permissions/:id", roleHandler.DeletePermission)
+
+	return privacyService
 }
\ No newline at end of file
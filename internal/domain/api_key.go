@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope yang bisa diberikan ke API key toko.
+const (
+	APIScopeItemsRead  = "items:read"
+	APIScopeItemsWrite = "items:write"
+)
+
+type ShopAPIKey struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	ShopID           uuid.UUID  `json:"shop_id" db:"shop_id"`
+	CreatedBy        uuid.UUID  `json:"created_by" db:"created_by"`
+	Name             string     `json:"name" db:"name"`
+	Prefix           string     `json:"prefix" db:"key_prefix"`
+	KeyHash          string     `json:"-" db:"key_hash"`
+	Scopes           []string   `json:"scopes" db:"scopes"`
+	RateLimitPerHour int        `json:"rate_limit_per_hour" db:"rate_limit_per_hour"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateAPIKeyInput struct {
+	Name             string   `json:"name" binding:"required,max=100"`
+	Scopes           []string `json:"scopes" binding:"required,min=1"`
+	RateLimitPerHour int      `json:"rate_limit_per_hour" binding:"min=0"`
+}
+
+// APIKeyCreatedResponse hanya dikembalikan sekali saat key dibuat.
+type APIKeyCreatedResponse struct {
+	Key    string      `json:"key"`
+	APIKey *ShopAPIKey `json:"api_key"`
+}
+
+// APIKeyPrincipal adalah identitas hasil autentikasi X-API-Key.
+type APIKeyPrincipal struct {
+	KeyID     uuid.UUID
+	ShopID    uuid.UUID
+	UserID    uuid.UUID // pemilik toko; request dijalankan atas nama user ini
+	Scopes    []string
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
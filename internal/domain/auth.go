@@ -8,19 +8,22 @@ import (
 type JWTClaims struct {
 	UserID      uuid.UUID `json:"user_id"`
 	RoleID      uuid.UUID `json:"role_id"`
-	RoleName    string    `json:"role_name"`
+	RoleName    string    `json:"role_name"` // role utama (users.role_id)
+	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions,omitempty"` 
+	TokenVersion int      `json:"token_version"`
+	MFA         bool      `json:"mfa,omitempty"` // true jika token diterbitkan setelah verifikasi 2FA
+	SessionID   uuid.UUID `json:"sid"` // id user_sessions; uuid.Nil pada token lama
 	
 	jwt.RegisteredClaims
 }
 
-type RefreshClaims struct {
-	UserID string `json:"userId"`
-	jwt.RegisteredClaims
-}
-
+// Pada mode cookie, Token dan RefreshToken dikosongkan (dikirim sebagai cookie HttpOnly)
+// dan CSRFToken diisi.
 type RefreshResponse struct {
-    Token string `json:"token"`
+    Token        string `json:"token,omitempty"`
+    RefreshToken string `json:"refresh_token,omitempty"`
+    CSRFToken    string `json:"csrf_token,omitempty"`
 }
 
 type LoginInput struct {
@@ -28,18 +31,26 @@ type LoginInput struct {
     Password string `json:"password"`
 }
 
+// Pada mode cookie, Token dan RefreshToken dikosongkan (dikirim sebagai cookie HttpOnly)
+// dan CSRFToken diisi.
 type LoginResponse struct {
-	Token        string   `json:"token"`
-	RefreshToken string   `json:"refresh_token"`
+	Token        string   `json:"token,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	CSRFToken    string   `json:"csrf_token,omitempty"`
 	User         UserResp `json:"user"`
+	// true jika kebijakan mewajibkan 2FA untuk role user tetapi user belum mengaktifkannya
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
 }
 
 type UserResp struct {
 	ID          uuid.UUID `json:"id"`
 	Username    string    `json:"username"`
+	Email       string    `json:"email"`
 	FullName    string    `json:"fullName"`
 	Role        string    `json:"role"`
+	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions"`
+	EmailVerified bool    `json:"emailVerified"`
 }
 
 type RegisterInput struct {
@@ -47,4 +58,9 @@ type RegisterInput struct {
 	Email    string
 	FullName string
 	Password string
-}
\ No newline at end of file
+}
+// ClientInfo berisi informasi klien dari request HTTP (untuk rate limit & audit).
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
@@ -5,13 +5,31 @@ import (
 )
 
 type Category struct {
-	ID        uuid.UUID `db:"id"`
-	ShopID    uuid.UUID `json:"shopId"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID        uuid.UUID  `db:"id"`
+	ShopID    uuid.UUID  `json:"shopId"`
+	ParentID  *uuid.UUID `json:"parentId" db:"parent_id"` // nil berarti kategori tingkat atas
+	Name      string     `db:"name"`
+	SortOrder int        `json:"sortOrder" db:"sort_order"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+// CategoryNode dipakai GET /categories untuk menampilkan kategori toko sebagai pohon
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
 
 type CreateCategoryInput struct {
-	Name string `json:"name" binding:"required"`
+	Name      string     `json:"name" binding:"required"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	SortOrder int        `json:"sort_order"`
+}
+
+// Field nil tidak diubah; move_to_root memindahkan kategori ke tingkat atas
+type UpdateCategoryInput struct {
+	Name       *string    `json:"name"`
+	ParentID   *uuid.UUID `json:"parent_id"`
+	MoveToRoot bool       `json:"move_to_root"`
+	SortOrder  *int       `json:"sort_order"`
 }
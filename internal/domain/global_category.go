@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GlobalCategory adalah taksonomi platform (mis. "Furniture"), berbeda dengan Category milik toko.
+type GlobalCategory struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ParentID  *uuid.UUID `json:"parent_id" db:"parent_id"`
+	Name      string     `json:"name" db:"name"`
+	Slug      string     `json:"slug" db:"slug"`
+	SortOrder int        `json:"sort_order" db:"sort_order"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type GlobalCategoryNode struct {
+	GlobalCategory
+	Children []GlobalCategoryNode `json:"children"`
+}
+
+// Slug kosong dibentuk dari nama
+type CreateGlobalCategoryInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Slug      string     `json:"slug"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	SortOrder int        `json:"sort_order"`
+}
+
+// Field nil tidak diubah; move_to_root memindahkan kategori ke tingkat atas
+type UpdateGlobalCategoryInput struct {
+	Name       *string    `json:"name"`
+	Slug       *string    `json:"slug"`
+	ParentID   *uuid.UUID `json:"parent_id"`
+	MoveToRoot bool       `json:"move_to_root"`
+	SortOrder  *int       `json:"sort_order"`
+}
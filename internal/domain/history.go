@@ -5,11 +5,12 @@ import (
 )
 
 type ActivityLog struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	UserID    string             `bson:"user_id"`
-	Action    string             `bson:"action"`
-	Module    string             `bson:"module"`
-	IPAddress string             `bson:"ip_address"`
-	Device    string             `bson:"device"`
-	CreatedAt time.Time          `bson:"created_at"`
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    string             `bson:"user_id" json:"userId"`
+	Action    string             `bson:"action" json:"action"`
+	Module    string             `bson:"module" json:"module"`
+	Detail    string             `bson:"detail,omitempty" json:"detail,omitempty"`
+	IPAddress string             `bson:"ip_address" json:"ipAddress"`
+	Device    string             `bson:"device" json:"device"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
 }
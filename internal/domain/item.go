@@ -8,14 +8,20 @@ type Item struct {
 	ID          uuid.UUID `db:"id"`
 	ShopID      uuid.UUID `db:"shop_id"`
 	CategoryID  uuid.UUID `db:"category_id"`
+	// Kategori taksonomi global (opsional), dipakai untuk browsing lintas toko
+	GlobalCategoryID *uuid.UUID `db:"global_category_id"`
 	Name        string    `db:"name"`
 	Description string    `db:"description"`
 	Price       float64   `db:"price"`
 	Stock       int       `db:"stock"`
 	Condition   string    `db:"condition"`
-	Status      string    `db:"status"` // active, inactive, deleted
+	Status      string    `db:"status"` // draft, active, inactive, moderated
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
+	// Penawaran asal untuk draft yang dibuat dari AcceptOffer
+	SourceOfferID *uuid.UUID `db:"source_offer_id"`
+	// Badge toko terverifikasi, diisi di hasil marketplace dan detail item
+	ShopVerified bool `db:"shop_verified"`
 }
 
 type ItemImage struct {
@@ -32,6 +38,7 @@ type CreateItemInput struct {
 	Stock       int     `form:"stock" binding:"required"`
 	Condition   string  `form:"condition" binding:"required"`
 	CategoryID  uuid.UUID `form:"category_id" binding:"required"`
+	GlobalCategoryID *uuid.UUID `form:"global_category_id"`
 }
 
 type UpdateItemInput struct {
@@ -41,4 +48,49 @@ type UpdateItemInput struct {
     Stock       int     `json:"stock" binding:"min=0"` 
     Condition   string  `json:"condition" binding:"required"`
     Status      string  `json:"status"` 
+    // nil tidak mengubah kategori toko; wajib diisi untuk draft sebelum publish
+    CategoryID  *uuid.UUID `json:"category_id"`
+    // nil tidak mengubah pemetaan kategori global
+    GlobalCategoryID *uuid.UUID `json:"global_category_id"`
+    // true menghapus pemetaan kategori global; tidak boleh digabung dengan global_category_id
+    ClearGlobalCategory bool `json:"clear_global_category"`
+}
+
+// Status item. moderated hanya bisa diberikan admin dan tidak bisa diubah seller.
+const (
+	ItemStatusDraft     = "draft"
+	ItemStatusActive    = "active"
+	ItemStatusInactive  = "inactive"
+	ItemStatusModerated = "moderated"
+)
+
+// InventoryFilter untuk GET /shops/me/items; berbeda dengan ItemFilter marketplace,
+// semua status ikut ditampilkan.
+type InventoryFilter struct {
+	Status     string    `form:"status" binding:"omitempty,oneof=draft active inactive moderated"`
+	CategoryID uuid.UUID `form:"category_id"`
+	// in_stock, low (1..LowStockThreshold), out (0)
+	Stock  string `form:"stock" binding:"omitempty,oneof=in_stock low out"`
+	Query  string `form:"q"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest oldest updated name price_asc price_desc stock_asc stock_desc"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+// LowStockThreshold: batas atas filter stock=low
+const LowStockThreshold = 5
+
+type BulkItemStatusInput struct {
+	ItemIDs []uuid.UUID `json:"item_ids" binding:"required,min=1,max=100"`
+	Status  string      `json:"status" binding:"required,oneof=active inactive"`
+}
+
+type BulkItemSkip struct {
+	ItemID uuid.UUID `json:"item_id"`
+	Reason string    `json:"reason"`
+}
+
+type BulkItemStatusResult struct {
+	Updated []uuid.UUID    `json:"updated"`
+	Skipped []BulkItemSkip `json:"skipped"`
 }
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+type UserMFA struct {
+	UserID          uuid.UUID  `db:"user_id"`
+	SecretEncrypted string     `db:"secret_encrypted"`
+	EnabledAt       *time.Time `db:"enabled_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+// MFAChallenge dikembalikan oleh login jika user mengaktifkan 2FA.
+// MFAToken ditukar dengan LoginResponse lewat /auth/2fa/verify.
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeInput struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFAVerifyInput struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
@@ -42,10 +42,14 @@ type CreateOrderInput struct {
 type ItemFilter struct {
     Keyword     string  `form:"keyword"`
     CategoryID  uuid.UUID `form:"category_id"`
+    // Kategori taksonomi global, lintas toko
+    GlobalCategoryID uuid.UUID `form:"global_category_id"`
     MinPrice    float64 `form:"min_price"`
     MaxPrice    float64 `form:"max_price"`
     Limit       int     `form:"limit"`
     Offset      int     `form:"offset"`
+    // Hanya item dari toko terverifikasi; dipaksa true jika kebijakan platform mewajibkannya
+    VerifiedOnly bool   `form:"verified_only"`
 }
 
 type UpdateOrderStatusInput struct {
@@ -3,6 +3,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// Nama permission yang dipakai langsung oleh route/service.
+const (
+	PermUserRead              = "user:read"
+	PermUserManage            = "user:manage"
+	PermItemModerate          = "item:moderate"
+	PermOrderCreate           = "order:create"
+	PermOrderUpdateStatus     = "order:update_status"
+	PermOrderManageAny        = "order:manage_any"
+	PermOrderReadAny          = "order:read_any"
+	PermOfferCreate           = "offer:create"
+	PermRoleManage            = "role:manage"
+	PermRoleApplicationReview = "role_application:review"
+	PermShopVerify            = "shop:verify"
+	PermTaxonomyManage        = "taxonomy:manage"
+	PermShopManage            = "shop:manage"
+	PermShopStaff             = "shop:staff"
+)
+
 type Permission struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`         
@@ -15,3 +33,10 @@ type RolePermission struct {
 	RoleID       uuid.UUID `json:"roleId" db:"role_id"`
 	PermissionID uuid.UUID `json:"permissionId" db:"permission_id"`
 }
+
+type CreatePermissionInput struct {
+	Name        string `json:"name" binding:"required"`
+	Resource    string `json:"resource" binding:"required"`
+	Action      string `json:"action" binding:"required"`
+	Description string `json:"description"`
+}
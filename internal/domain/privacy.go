@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountDeletionRequest adalah permintaan hapus akun yang baru dieksekusi
+// setelah masa tenggang (ScheduledFor) dan bisa dibatalkan sebelum itu.
+type AccountDeletionRequest struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	RequestedAt  time.Time  `json:"requested_at" db:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for" db:"scheduled_for"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type DeleteAccountInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type ExportOrder struct {
+	Order Order       `json:"order"`
+	Items []OrderItem `json:"items"`
+}
+
+// DataExport berisi semua data yang terkait dengan satu user ID.
+type DataExport struct {
+	ExportedAt        time.Time          `json:"exported_at"`
+	User              User               `json:"user"`
+	Roles             []string           `json:"roles"`
+	Shop              *Shop              `json:"shop,omitempty"`
+	ShopVerifications []ShopVerification `json:"shop_verifications"`
+	Orders            []ExportOrder      `json:"orders"`
+	OffersGiven       []Offer            `json:"offers_given"`
+	OffersReceived    []Offer            `json:"offers_received"`
+	Notifications     []Notification     `json:"notifications"`
+	StatusHistory     []HistoryStatus    `json:"status_history"`
+	Sessions          []UserSession      `json:"sessions"`
+	ActivityLogs      []ActivityLog      `json:"activity_logs"`
+}
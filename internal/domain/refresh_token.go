@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// RefreshToken disimpan dalam bentuk hash. Satu FamilyID mewakili satu rantai rotasi
+// yang berawal dari satu kali login.
+type RefreshToken struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	FamilyID   uuid.UUID  `db:"family_id"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *uuid.UUID `db:"replaced_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	// MFAVerified: family berasal dari login yang lolos 2FA; ikut disalin saat rotasi.
+	MFAVerified bool `db:"mfa_verified"`
+}
@@ -4,7 +4,16 @@ import (
 )
 
 type Role struct {
-	ID          uuid.UUID `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+}
+
+type RoleInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type AssignRoleInput struct {
+	RoleID uuid.UUID `json:"role_id" binding:"required"`
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+type RoleApplication struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	RoleID     uuid.UUID  `json:"roleId" db:"role_id"`
+	RoleName   string     `json:"role" db:"role_name"`
+	Reason     string     `json:"reason" db:"reason"`
+	Status     string     `json:"status" db:"status"` // pending, approved, rejected
+	ReviewedBy *uuid.UUID `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewNote string     `json:"reviewNote" db:"review_note"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type CreateRoleApplicationInput struct {
+	Role   string `json:"role" binding:"required,oneof=seller giver"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ReviewRoleApplicationInput struct {
+	Note string `json:"note"`
+}
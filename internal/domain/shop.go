@@ -11,6 +11,10 @@ type Shop struct {
 	Name      string    `db:"name"`
 	Description string  `db:"description"`
 	Address   string    `db:"address"`
+	LogoURL   string    `db:"logo_url"`
+	BannerURL string    `db:"banner_url"`
+	VerificationStatus string     `db:"verification_status"`
+	VerifiedAt         *time.Time `db:"verified_at"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
@@ -20,3 +24,92 @@ type CreateShopInput struct {
 	Description string `json:"description"`
 	Address     string `json:"address" binding:"required"`
 }
+
+// UpdateShopInput: field yang tidak dikirim tidak diubah. Logo dan banner dikirim
+// sebagai file multipart terpisah.
+type UpdateShopInput struct {
+	Name        *string
+	Description *string
+	Address     *string
+}
+
+type ShopStats struct {
+	ActiveItems     int `json:"active_items"`
+	CompletedOrders int `json:"completed_orders"`
+	ItemsSold       int `json:"items_sold"`
+}
+
+// PublicShop data toko yang aman ditampilkan publik; user_id pemilik sengaja tidak ikut.
+type PublicShop struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	Address            string     `json:"address"`
+	LogoURL            string     `json:"logo_url"`
+	BannerURL          string     `json:"banner_url"`
+	VerificationStatus string     `json:"verification_status"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// ShopProfile adalah halaman publik toko.
+type ShopProfile struct {
+	Shop         PublicShop       `json:"shop"`
+	Availability ShopAvailability `json:"availability"`
+	Stats        ShopStats        `json:"stats"`
+	Categories   []Category       `json:"categories"`
+	Items        []Item           `json:"items"`
+}
+
+type ShopSummary struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	LogoURL     string    `json:"logo_url"`
+	Verified    bool      `json:"verified"`
+	ActiveItems int       `json:"active_items"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Filter direktori toko
+type ShopFilter struct {
+	Keyword string `form:"q"`
+	Limit   int    `form:"limit"`
+	Offset  int    `form:"offset"`
+}
+
+// OperatingHours satu rentang jam buka dalam format "HH:MM"; Close boleh "24:00".
+type OperatingHours struct {
+	Weekday int    `json:"weekday" binding:"min=0,max=6"` // 0 = Minggu
+	Open    string `json:"open" binding:"required"`
+	Close   string `json:"close" binding:"required"`
+}
+
+// OperatingSlot bentuk OperatingHours seperti yang disimpan di database (menit sejak 00:00).
+type OperatingSlot struct {
+	Weekday     int `db:"weekday"`
+	OpenMinute  int `db:"open_minute"`
+	CloseMinute int `db:"close_minute"`
+}
+
+// ShopAvailability status buka/tutup toko. IsOpen dihitung saat dibaca.
+type ShopAvailability struct {
+	VacationMode     bool             `json:"vacation_mode"`
+	VacationUntil    *time.Time       `json:"vacation_until,omitempty"`
+	VacationMessage  string           `json:"vacation_message,omitempty"`
+	UTCOffsetMinutes int              `json:"utc_offset_minutes"`
+	Hours            []OperatingHours `json:"operating_hours"`
+	IsOpen           bool             `json:"is_open"`
+}
+
+type UpdateVacationInput struct {
+	Enabled bool       `json:"enabled"`
+	Until   *time.Time `json:"until"`
+	Message string     `json:"message" binding:"max=500"`
+}
+
+// UpdateOperatingHoursInput menggantikan seluruh jadwal; Hours kosong berarti buka setiap saat.
+type UpdateOperatingHoursInput struct {
+	UTCOffsetMinutes *int             `json:"utc_offset_minutes"`
+	Hours            []OperatingHours `json:"hours" binding:"dive"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role anggota toko
+const (
+	ShopRoleOwner   = "owner"
+	ShopRoleManager = "manager"
+	ShopRolePacker  = "packer"
+)
+
+// Permission shop-scoped, berlaku hanya di toko tempat user menjadi anggota.
+const (
+	ShopPermManageShop    = "manage_shop"
+	ShopPermManageItems   = "manage_items"
+	ShopPermFulfilOrders  = "fulfil_orders"
+	ShopPermHandleOffers  = "handle_offers"
+	ShopPermManageStaff   = "manage_staff"
+	ShopPermManageAPIKeys = "manage_api_keys"
+)
+
+var ShopRolePermissions = map[string][]string{
+	ShopRoleOwner: {
+		ShopPermManageShop, ShopPermManageItems, ShopPermFulfilOrders,
+		ShopPermHandleOffers, ShopPermManageStaff, ShopPermManageAPIKeys,
+	},
+	ShopRoleManager: {ShopPermManageShop, ShopPermManageItems, ShopPermFulfilOrders, ShopPermHandleOffers},
+	ShopRolePacker:  {ShopPermFulfilOrders},
+}
+
+type ShopMember struct {
+	ShopID    uuid.UUID `json:"shop_id" db:"shop_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	FullName  string    `json:"full_name" db:"full_name"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type ShopInvitation struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	ShopID      uuid.UUID  `json:"shop_id" db:"shop_id"`
+	ShopName    string     `json:"shop_name" db:"shop_name"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Username    string     `json:"username" db:"username"`
+	Role        string     `json:"role" db:"role"`
+	InvitedBy   uuid.UUID  `json:"invited_by" db:"invited_by"`
+	Status      string     `json:"status" db:"status"` // pending, accepted, declined, revoked, expired
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// InviteShopMemberInput: Identifier berupa username atau email user yang sudah terdaftar.
+type InviteShopMemberInput struct {
+	Identifier string `json:"identifier" binding:"required"`
+	Role       string `json:"role" binding:"required,oneof=manager packer"`
+}
+
+type UpdateShopMemberInput struct {
+	Role string `json:"role" binding:"required,oneof=manager packer"`
+}
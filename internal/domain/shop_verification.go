@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status verifikasi di shops.verification_status
+const (
+	ShopUnverified = "unverified"
+	ShopPending    = "pending"
+	ShopVerified   = "verified"
+	ShopRejected   = "rejected"
+)
+
+// Jenis dokumen KYC
+const (
+	VerificationDocIdentity = "identity"
+	VerificationDocBusiness = "business"
+)
+
+type ShopVerification struct {
+	ID          uuid.UUID              `json:"id" db:"id"`
+	ShopID      uuid.UUID              `json:"shop_id" db:"shop_id"`
+	ShopName    string                 `json:"shop_name" db:"shop_name"`
+	SubmittedBy uuid.UUID              `json:"submitted_by" db:"submitted_by"`
+	Note        string                 `json:"note" db:"note"`
+	Status      string                 `json:"status" db:"status"` // pending, approved, rejected
+	ReviewedBy  *uuid.UUID             `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNote  string                 `json:"review_note" db:"review_note"`
+	ReviewedAt  *time.Time             `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	Documents   []VerificationDocument `json:"documents" db:"-"`
+}
+
+// VerificationDocument: StoragePath hanya dipakai server, tidak pernah dikirim ke client.
+type VerificationDocument struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	VerificationID uuid.UUID `json:"verification_id" db:"verification_id"`
+	DocType        string    `json:"doc_type" db:"doc_type"`
+	StoragePath    string    `json:"-" db:"storage_path"`
+	OriginalName   string    `json:"original_name" db:"original_name"`
+	ContentType    string    `json:"content_type" db:"content_type"`
+	SizeBytes      int64     `json:"size_bytes" db:"size_bytes"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// ShopVerificationStatus ringkasan untuk seller: status toko dan pengajuan terakhir.
+type ShopVerificationStatus struct {
+	Status     string            `json:"status"`
+	VerifiedAt *time.Time        `json:"verified_at,omitempty"`
+	Latest     *ShopVerification `json:"latest_submission,omitempty"`
+}
+
+// Antrian admin /api/admin/shops
+type AdminShopFilter struct {
+	VerificationStatus string `form:"verification_status"`
+	Limit              int    `form:"limit"`
+	Offset             int    `form:"offset"`
+}
+
+type AdminShopEntry struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	OwnerID            uuid.UUID  `json:"owner_id"`
+	OwnerUsername      string     `json:"owner_username"`
+	VerificationStatus string     `json:"verification_status"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	SubmittedAt        *time.Time `json:"submitted_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type ReviewShopVerificationInput struct {
+	Note string `json:"note"`
+}
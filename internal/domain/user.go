@@ -13,6 +13,8 @@ type User struct {
 	FullName     string    `json:"full_name" db:"full_name"`
 	RoleID       uuid.UUID `json:"role_id" db:"role_id"`
 	IsActive     bool      `json:"is_active" db:"is_active"`
+	TokenVersion int       `json:"-" db:"token_version"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -20,4 +22,20 @@ type User struct {
 // Struct untuk FR-ADMIN-03
 type UpdateUserStatusInput struct {
     IsActive bool `json:"is_active" binding:"required"`
-}
\ No newline at end of file
+}
+
+// UpdateProfileInput: field yang tidak dikirim tidak diubah.
+type UpdateProfileInput struct {
+	Username *string `json:"username" binding:"omitempty,min=3,max=50"`
+	FullName *string `json:"full_name" binding:"omitempty,min=1,max=100"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailInput struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
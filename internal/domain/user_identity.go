@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity menghubungkan user dengan akun di provider OIDC eksternal.
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"subject" db:"subject"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// OIDCStartResponse dikembalikan GET /auth/oidc/login?mode=json untuk SPA yang ingin redirect sendiri.
+type OIDCStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
@@ -0,0 +1,111 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"home-market/internal/config"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender adalah abstraksi pengirim email agar service tidak bergantung pada provider tertentu.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewSender memilih implementasi berdasarkan MAIL_DRIVER (log, file, smtp). Driver kosong
+// atau tidak dikenal ditolak agar link reset password dan verifikasi tidak diam-diam
+// tercetak ke log aplikasi; driver log harus dipilih secara eksplisit.
+func NewSender(cfg config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, errors.New("mail: SMTP_HOST must be set for MAIL_DRIVER=smtp")
+		}
+		return &smtpSender{cfg: cfg}, nil
+	case config.MailDriverFile:
+		return &fileSender{dir: cfg.Dir, from: cfg.From}, nil
+	case config.MailDriverLog:
+		log.Printf("Warning: MAIL_DRIVER=log writes every email, including reset and verification links, to the application log")
+		return &logSender{from: cfg.From}, nil
+	case "":
+		return nil, errors.New("mail: MAIL_DRIVER must be set (log, file or smtp)")
+	default:
+		return nil, fmt.Errorf("mail: unknown MAIL_DRIVER %q", cfg.Driver)
+	}
+}
+
+// logSender hanya mencetak email ke log (untuk development lokal).
+type logSender struct {
+	from string
+}
+
+func (s *logSender) Send(msg Message) error {
+	log.Printf("[mail] from=%s to=%s subject=%q\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// fileSender menyimpan setiap email sebagai file .eml di direktori lokal.
+type fileSender struct {
+	dir  string
+	from string
+}
+
+func (s *fileSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	filename := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405"), uuid.New().String()[:8])
+	path := filepath.Join(s.dir, filename)
+	if err := os.WriteFile(path, buildMessage(s.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	log.Printf("[mail] %q untuk %s disimpan di %s", msg.Subject, msg.To, path)
+	return nil
+}
+
+type smtpSender struct {
+	cfg config.MailConfig
+}
+
+func (s *smtpSender) Send(msg Message) error {
+	addr := s.cfg.SMTPHost + ":" + s.cfg.SMTPPort
+
+	var auth smtp.Auth
+	if s.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.cfg.SMTPUsername, s.cfg.SMTPPassword, s.cfg.SMTPHost)
+	}
+
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{msg.To}, buildMessage(s.cfg.From, msg)); err != nil {
+		return fmt.Errorf("failed to send mail via smtp: %w", err)
+	}
+	return nil
+}
+
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
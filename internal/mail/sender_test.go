@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"testing"
+
+	"home-market/internal/config"
+)
+
+func TestNewSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.MailConfig
+		wantErr bool
+	}{
+		{name: "smtp", cfg: config.MailConfig{Driver: config.MailDriverSMTP, SMTPHost: "smtp.example.com"}},
+		{name: "smtp without host", cfg: config.MailConfig{Driver: config.MailDriverSMTP}, wantErr: true},
+		{name: "file", cfg: config.MailConfig{Driver: config.MailDriverFile, Dir: t.TempDir()}},
+		{name: "explicit log", cfg: config.MailConfig{Driver: config.MailDriverLog}},
+		{name: "empty driver", cfg: config.MailConfig{}, wantErr: true},
+		{name: "unknown driver", cfg: config.MailConfig{Driver: "sendgrid"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := NewSender(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && sender == nil {
+				t.Fatal("nil sender without error")
+			}
+		})
+	}
+}
+
+func TestLoadMailDevelopmentDefault(t *testing.T) {
+	t.Setenv("MAIL_DRIVER", "")
+	t.Setenv("APP_ENV", "development")
+	if cfg := config.LoadMail(); cfg.Driver != config.MailDriverFile {
+		t.Fatalf("driver = %q in development, want file", cfg.Driver)
+	}
+
+	t.Setenv("APP_ENV", "production")
+	if _, err := NewSender(config.LoadMail()); err == nil {
+		t.Fatal("empty MAIL_DRIVER accepted outside development")
+	}
+}
@@ -0,0 +1,141 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	"home-market/internal/oidc/oidctest"
+)
+
+func newMockProvider(t *testing.T) (*oidctest.Server, *Provider) {
+	t.Helper()
+	srv, err := oidctest.NewServer("home-market")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+
+	p := NewProvider(config.OIDCConfig{
+		IssuerURL:   srv.URL,
+		ClientID:    "home-market",
+		RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:      "openid email profile",
+	})
+	return srv, p
+}
+
+func TestProviderLoginFlow(t *testing.T) {
+	id := oidctest.Identity{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true}
+
+	tests := []struct {
+		name      string
+		forge     bool
+		nonce     string // nonce di id_token; kosong berarti sama dengan nonce request
+		verifier  string // code_verifier saat exchange; kosong berarti verifier yang benar
+		wantErrAt string // "", "exchange" atau "verify"
+	}{
+		{name: "valid"},
+		{name: "forged signature", forge: true, wantErrAt: "verify"},
+		{name: "nonce mismatch", nonce: "other-nonce", wantErrAt: "verify"},
+		{name: "wrong pkce verifier", verifier: "not-the-verifier", wantErrAt: "exchange"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, p := newMockProvider(t)
+			srv.ForgeSignature = tt.forge
+			srv.NonceOverride = tt.nonce
+			ctx := context.Background()
+
+			authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			u, _ := url.Parse(authURL)
+			if got := u.Query().Get("code_challenge"); got != CodeChallenge("verifier-1") {
+				t.Fatalf("code_challenge = %q", got)
+			}
+
+			code, state, err := srv.Authorize(authURL, id)
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+			if state != "state-1" {
+				t.Fatalf("state = %q, want state-1", state)
+			}
+
+			verifier := "verifier-1"
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			token, err := p.Exchange(ctx, code, verifier)
+			if tt.wantErrAt == "exchange" {
+				if err == nil {
+					t.Fatal("Exchange succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+
+			claims, err := p.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+			if tt.wantErrAt == "verify" {
+				if err == nil {
+					t.Fatal("VerifyIDToken succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if claims.Subject != id.Subject || claims.Email != id.Email || !claims.EmailVerified {
+				t.Fatalf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestProviderRejectsOtherAudience(t *testing.T) {
+	srv, p := newMockProvider(t)
+	other := NewProvider(config.OIDCConfig{IssuerURL: srv.URL, ClientID: "other-client"})
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "s", "n", "v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _, err := srv.Authorize(authURL, oidctest.Identity{Subject: "sub-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := p.Exchange(ctx, code, "v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.VerifyIDToken(ctx, token.IDToken, "n"); err == nil {
+		t.Fatal("id_token for another client was accepted")
+	}
+}
+
+func TestStateStoreTake(t *testing.T) {
+	store := NewStateStore()
+	store.Put("live", AuthRequest{Nonce: "n", ExpiresAt: time.Now().Add(time.Minute)})
+	store.Put("expired", AuthRequest{Nonce: "n", ExpiresAt: time.Now().Add(-time.Second)})
+
+	if _, ok := store.Take("live"); !ok {
+		t.Fatal("live state not found")
+	}
+	if _, ok := store.Take("live"); ok {
+		t.Fatal("state could be taken twice")
+	}
+	if _, ok := store.Take("expired"); ok {
+		t.Fatal("expired state was accepted")
+	}
+	if _, ok := store.Take("unknown"); ok {
+		t.Fatal("unknown state was accepted")
+	}
+}
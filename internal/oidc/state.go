@@ -0,0 +1,52 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+)
+
+// AuthRequest adalah data yang disimpan antara redirect ke provider dan callback.
+type AuthRequest struct {
+	Verifier  string
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// StateStore menyimpan AuthRequest per state secara in-process, sekali pakai.
+type StateStore struct {
+	mu      sync.Mutex
+	entries map[string]AuthRequest
+}
+
+func NewStateStore() *StateStore {
+	return &StateStore{entries: make(map[string]AuthRequest)}
+}
+
+func (s *StateStore) Put(state string, req AuthRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range s.entries {
+		if now.After(v.ExpiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[state] = req
+}
+
+// Take mengambil dan menghapus AuthRequest; false jika state tidak dikenal atau kedaluwarsa.
+func (s *StateStore) Take(state string) (AuthRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.entries[state]
+	if !ok {
+		return AuthRequest{}, false
+	}
+	delete(s.entries, state)
+	if time.Now().After(req.ExpiresAt) {
+		return AuthRequest{}, false
+	}
+	return req, true
+}
@@ -6,7 +6,10 @@ import (
 	"time"
 	entity "home-market/internal/domain" // Asumsi entity diimpor
 
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // PLACEHOLDERS
@@ -14,11 +17,18 @@ const (
 	DatabaseName     = "random_home_market"
 	CollectionStatus = "history_status"
 	CollectionNotifs = "notifications" // <--- TAMBAHKAN DEFINISI COLLECTION BARU
+	CollectionActivity = "activity_logs"
 )
 
 type LogRepository interface {
 	SaveHistoryStatus(doc *entity.HistoryStatus) error
 	SaveNotification(doc *entity.Notification) error
+	SaveActivityLog(doc *entity.ActivityLog) error
+	ListNotificationsByUser(userID uuid.UUID) ([]entity.Notification, error)
+	DeleteNotificationsByUser(userID uuid.UUID) (int64, error)
+	ListActivityLogsByUser(userID string) ([]entity.ActivityLog, error)
+	DeleteActivityLogsByUser(userID string) (int64, error)
+	ListHistoryStatus(relatedIDs []string, changedBy string) ([]entity.HistoryStatus, error)
 }
 
 type logRepository struct {
@@ -65,4 +75,108 @@ func (r *logRepository) SaveNotification(doc *entity.Notification) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *logRepository) SaveActivityLog(doc *entity.ActivityLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionActivity)
+
+	_, err := collection.InsertOne(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("failed to insert activity log to Mongo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *logRepository) ListNotificationsByUser(userID uuid.UUID) ([]entity.Notification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionNotifs)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications from Mongo: %w", err)
+	}
+
+	notifications := []entity.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to decode notifications from Mongo: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *logRepository) DeleteNotificationsByUser(userID uuid.UUID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionNotifs)
+
+	res, err := collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete notifications from Mongo: %w", err)
+	}
+	return res.DeletedCount, nil
+}
+
+// ListActivityLogsByUser mengambil activity log (lockout login/2FA) atas nama user, beserta IP dan device-nya.
+func (r *logRepository) ListActivityLogsByUser(userID string) ([]entity.ActivityLog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionActivity)
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity logs from Mongo: %w", err)
+	}
+
+	logs := []entity.ActivityLog{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, fmt.Errorf("failed to decode activity logs from Mongo: %w", err)
+	}
+	return logs, nil
+}
+
+func (r *logRepository) DeleteActivityLogsByUser(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionActivity)
+
+	res, err := collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete activity logs from Mongo: %w", err)
+	}
+	return res.DeletedCount, nil
+}
+
+// ListHistoryStatus mengambil riwayat status untuk objek tertentu (mis. order milik user)
+// atau yang diubah oleh user tertentu.
+func (r *logRepository) ListHistoryStatus(relatedIDs []string, changedBy string) ([]entity.HistoryStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.client.Database(DatabaseName).Collection(CollectionStatus)
+
+	or := bson.A{bson.M{"changed_by": changedBy}}
+	if len(relatedIDs) > 0 {
+		or = append(or, bson.M{"related_id": bson.M{"$in": relatedIDs}})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"$or": or}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history status from Mongo: %w", err)
+	}
+
+	history := []entity.HistoryStatus{}
+	if err := cursor.All(ctx, &history); err != nil {
+		return nil, fmt.Errorf("failed to decode history status from Mongo: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	entity "home-market/internal/domain"
+)
+
+type APIKeyRepository interface {
+	Create(key *entity.ShopAPIKey) error
+	ListByShop(shopID uuid.UUID) ([]entity.ShopAPIKey, error)
+	GetByHash(keyHash string) (*entity.ShopAPIKey, error)
+	Revoke(id, shopID uuid.UUID) (bool, error)
+	TouchLastUsed(id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, shop_id, created_by, name, key_prefix, key_hash, scopes, rate_limit_per_hour, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.ShopAPIKey, error) {
+	var key entity.ShopAPIKey
+	err := scanner.Scan(
+		&key.ID, &key.ShopID, &key.CreatedBy, &key.Name, &key.Prefix, &key.KeyHash,
+		pq.Array(&key.Scopes), &key.RateLimitPerHour, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) Create(key *entity.ShopAPIKey) error {
+	query := `
+		INSERT INTO shop_api_keys (id, shop_id, created_by, name, key_prefix, key_hash, scopes, rate_limit_per_hour, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+	`
+	_, err := r.db.Exec(query, key.ID, key.ShopID, key.CreatedBy, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes), key.RateLimitPerHour)
+	return err
+}
+
+func (r *apiKeyRepository) ListByShop(shopID uuid.UUID) ([]entity.ShopAPIKey, error) {
+	rows, err := r.db.Query(`SELECT `+apiKeyColumns+` FROM shop_api_keys WHERE shop_id = $1 ORDER BY created_at DESC`, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []entity.ShopAPIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepository) GetByHash(keyHash string) (*entity.ShopAPIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(`SELECT `+apiKeyColumns+` FROM shop_api_keys WHERE key_hash = $1`, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+func (r *apiKeyRepository) Revoke(id, shopID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(
+		`UPDATE shop_api_keys SET revoked_at = NOW() WHERE id = $1 AND shop_id = $2 AND revoked_at IS NULL`,
+		id, shopID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE shop_api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	entity "home-market/internal/domain"
 
 	"github.com/google/uuid"
@@ -14,6 +15,11 @@ type CategoryRepository interface {
 	CreateCategory(c *entity.Category) error
 	GetShopByUserID(userID uuid.UUID) (*entity.Shop, error)
 	ExistsByName(shopID uuid.UUID, name string) (bool, error)
+	ListByShop(shopID uuid.UUID) ([]entity.Category, error)
+	GetByID(id uuid.UUID) (*entity.Category, error)
+	UpdateCategory(c *entity.Category) error
+	CountItems(categoryID uuid.UUID) (int, error)
+	DeleteCategory(c *entity.Category, reassignTo *uuid.UUID) error
 }
 
 type categoryRepository struct {
@@ -57,13 +63,15 @@ func (r *categoryRepository) GetShopByUserID(userID uuid.UUID) (*entity.Shop, er
 // Insert kategori
 func (r *categoryRepository) CreateCategory(c *entity.Category) error {
 	query := `
-		INSERT INTO categories (id, shop_id, name, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO categories (id, shop_id, parent_id, name, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 	`
 	_, err := r.db.Exec(query,
 		c.ID,
 		c.ShopID,
+		c.ParentID,
 		c.Name,
+		c.SortOrder,
 	)
 
 	return err
@@ -87,3 +95,101 @@ func (r *categoryRepository) ExistsByName(shopID uuid.UUID, name string) (bool,
 	return exists, nil
 }
 
+const categoryColumns = `id, shop_id, parent_id, name, sort_order, created_at, updated_at`
+
+func scanCategory(scanner interface{ Scan(dest ...interface{}) error }) (*entity.Category, error) {
+	var c entity.Category
+	var parentID uuid.NullUUID
+	if err := scanner.Scan(&c.ID, &c.ShopID, &parentID, &c.Name, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.UUID
+	}
+	return &c, nil
+}
+
+func (r *categoryRepository) ListByShop(shopID uuid.UUID) ([]entity.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE shop_id = $1
+		ORDER BY sort_order, name
+	`
+	rows, err := r.db.Query(query, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []entity.Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *categoryRepository) GetByID(id uuid.UUID) (*entity.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
+	c, err := scanCategory(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *categoryRepository) UpdateCategory(c *entity.Category) error {
+	_, err := r.db.Exec(`
+		UPDATE categories
+		SET name = $1, parent_id = $2, sort_order = $3, updated_at = NOW()
+		WHERE id = $4
+	`, c.Name, c.ParentID, c.SortOrder, c.ID)
+	return err
+}
+
+// CountItems menghitung semua item (termasuk draft/inactive) yang masih mereferensikan kategori.
+func (r *categoryRepository) CountItems(categoryID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM items WHERE category_id = $1`, categoryID).Scan(&n)
+	return n, err
+}
+
+// DeleteCategory menghapus kategori toko; lihat deleteCategoryNode.
+func (r *categoryRepository) DeleteCategory(c *entity.Category, reassignTo *uuid.UUID) error {
+	return deleteCategoryNode(r.db, "categories", "category_id", c.ID, c.ParentID, reassignTo)
+}
+
+// deleteCategoryNode dipakai kategori toko (categories/items.category_id) dan taksonomi
+// global (global_categories/items.global_category_id) dalam satu transaksi: pindahkan
+// item ke reassignTo (jika ada), naikkan sub-kategori ke parent node yang dihapus, lalu
+// hapus node-nya. table dan itemColumn selalu konstanta dari repository, bukan input.
+func deleteCategoryNode(db *sql.DB, table, itemColumn string, id uuid.UUID, parentID, reassignTo *uuid.UUID) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if reassignTo != nil {
+		if _, err := tx.Exec(fmt.Sprintf(`
+			UPDATE items SET %[1]s = $1, updated_at = NOW() WHERE %[1]s = $2
+		`, itemColumn), *reassignTo, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`
+		UPDATE %s SET parent_id = $1, updated_at = NOW() WHERE parent_id = $2
+	`, table), parentID, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, table), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type GlobalCategoryRepository interface {
+	Create(c *entity.GlobalCategory) error
+	GetByID(id uuid.UUID) (*entity.GlobalCategory, error)
+	List() ([]entity.GlobalCategory, error)
+	Update(c *entity.GlobalCategory) error
+	SlugExists(slug string, excludeID uuid.UUID) (bool, error)
+	CountItems(id uuid.UUID) (int, error)
+	Delete(c *entity.GlobalCategory, reassignTo *uuid.UUID) error
+}
+
+type globalCategoryRepository struct {
+	db *sql.DB
+}
+
+func NewGlobalCategoryRepository(db *sql.DB) GlobalCategoryRepository {
+	return &globalCategoryRepository{db: db}
+}
+
+const globalCategoryColumns = `id, parent_id, name, slug, sort_order, created_at, updated_at`
+
+func scanGlobalCategory(scanner interface{ Scan(dest ...interface{}) error }) (*entity.GlobalCategory, error) {
+	var c entity.GlobalCategory
+	var parentID uuid.NullUUID
+	if err := scanner.Scan(&c.ID, &parentID, &c.Name, &c.Slug, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.UUID
+	}
+	return &c, nil
+}
+
+func (r *globalCategoryRepository) Create(c *entity.GlobalCategory) error {
+	_, err := r.db.Exec(`
+		INSERT INTO global_categories (id, parent_id, name, slug, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, c.ID, c.ParentID, c.Name, c.Slug, c.SortOrder, c.CreatedAt, c.UpdatedAt)
+	return err
+}
+
+func (r *globalCategoryRepository) GetByID(id uuid.UUID) (*entity.GlobalCategory, error) {
+	c, err := scanGlobalCategory(r.db.QueryRow(`SELECT `+globalCategoryColumns+` FROM global_categories WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *globalCategoryRepository) List() ([]entity.GlobalCategory, error) {
+	rows, err := r.db.Query(`SELECT ` + globalCategoryColumns + ` FROM global_categories ORDER BY sort_order, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []entity.GlobalCategory{}
+	for rows.Next() {
+		c, err := scanGlobalCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *globalCategoryRepository) Update(c *entity.GlobalCategory) error {
+	_, err := r.db.Exec(`
+		UPDATE global_categories
+		SET parent_id = $1, name = $2, slug = $3, sort_order = $4, updated_at = $5
+		WHERE id = $6
+	`, c.ParentID, c.Name, c.Slug, c.SortOrder, c.UpdatedAt, c.ID)
+	return err
+}
+
+func (r *globalCategoryRepository) SlugExists(slug string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM global_categories WHERE slug = $1 AND id <> $2)
+	`, slug, excludeID).Scan(&exists)
+	return exists, err
+}
+
+func (r *globalCategoryRepository) CountItems(id uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM items WHERE global_category_id = $1`, id).Scan(&n)
+	return n, err
+}
+
+// Delete menghapus node taksonomi; aturannya sama dengan kategori toko (deleteCategoryNode).
+func (r *globalCategoryRepository) Delete(c *entity.GlobalCategory, reassignTo *uuid.UUID) error {
+	return deleteCategoryNode(r.db, "global_categories", "global_category_id", c.ID, c.ParentID, reassignTo)
+}
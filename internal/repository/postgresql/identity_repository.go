@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+)
+
+type IdentityRepository interface {
+	GetByProviderSubject(provider, subject string) (*entity.UserIdentity, error)
+	Create(identity *entity.UserIdentity) error
+}
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) GetByProviderSubject(provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+	err := r.db.QueryRow(query, provider, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) Create(identity *entity.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`
+	_, err := r.db.Exec(query, identity.ID, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	return err
+}
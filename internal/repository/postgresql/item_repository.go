@@ -2,8 +2,11 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	entity "home-market/internal/domain"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type ItemRepository interface {
@@ -11,6 +14,12 @@ type ItemRepository interface {
 	CreateItemImage(img *entity.ItemImage) error
 	GetItemByID(id uuid.UUID) (*entity.Item, error)
     UpdateItem(item *entity.Item) error
+	ListActiveByShop(shopID uuid.UUID, limit int) ([]entity.Item, error)
+	ListInventory(shopID uuid.UUID, filter entity.InventoryFilter) ([]entity.Item, error)
+	ListByIDs(shopID uuid.UUID, ids []uuid.UUID) ([]entity.Item, error)
+	UpdateStatusBulk(shopID uuid.UUID, ids []uuid.UUID, status string) error
+	ListImageURLs(itemID uuid.UUID) ([]string, error)
+	PublishDraft(itemID uuid.UUID, images []entity.ItemImage) (bool, error)
 }
 
 type itemRepository struct {
@@ -21,14 +30,22 @@ func NewItemRepository(db *sql.DB) ItemRepository {
 	return &itemRepository{db: db}
 }
 
+// nullableCategory: draft dari penawaran belum punya kategori (uuid.Nil) dan disimpan sebagai NULL
+func nullableCategory(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
 func (r *itemRepository) CreateItem(item *entity.Item) error {
 	query := `
-		INSERT INTO items (id, shop_id, category_id, name, description, price, stock, condition, status, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,NOW(),NOW())
+		INSERT INTO items (id, shop_id, category_id, global_category_id, source_offer_id, name, description, price, stock, condition, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NOW(),NOW())
 	`
 
 	_, err := r.db.Exec(query,
-		item.ID, item.ShopID, item.CategoryID, item.Name,
+		item.ID, item.ShopID, nullableCategory(item.CategoryID), item.GlobalCategoryID, item.SourceOfferID, item.Name,
 		item.Description, item.Price, item.Stock, item.Condition,
 		item.Status,
 	)
@@ -47,11 +64,11 @@ func (r *itemRepository) CreateItemImage(img *entity.ItemImage) error {
 func (r *itemRepository) GetItemByID(id uuid.UUID) (*entity.Item, error) {
     var item entity.Item
     query := `
-        SELECT id, shop_id, category_id, name, description, price, stock, condition, status, created_at, updated_at
+        SELECT id, shop_id, category_id, global_category_id, source_offer_id, name, description, price, stock, condition, status, created_at, updated_at
         FROM items WHERE id = $1
     `
     err := r.db.QueryRow(query, id).Scan(
-        &item.ID, &item.ShopID, &item.CategoryID, &item.Name, &item.Description,
+        &item.ID, &item.ShopID, &item.CategoryID, &item.GlobalCategoryID, &item.SourceOfferID, &item.Name, &item.Description,
         &item.Price, &item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt,
     )
     if err == sql.ErrNoRows {
@@ -63,11 +80,195 @@ func (r *itemRepository) GetItemByID(id uuid.UUID) (*entity.Item, error) {
 func (r *itemRepository) UpdateItem(item *entity.Item) error {
     query := `
         UPDATE items
-        SET name=$1, description=$2, price=$3, stock=$4, condition=$5, status=$6, updated_at=NOW()
-        WHERE id=$7
+        SET name=$1, description=$2, price=$3, stock=$4, condition=$5, status=$6, global_category_id=$7, category_id=$8, updated_at=NOW()
+        WHERE id=$9
     `
     _, err := r.db.Exec(query,
-        item.Name, item.Description, item.Price, item.Stock, item.Condition, item.Status, item.ID,
+        item.Name, item.Description, item.Price, item.Stock, item.Condition, item.Status, item.GlobalCategoryID,
+        nullableCategory(item.CategoryID), item.ID,
     )
     return err
-}
\ No newline at end of file
+}
+
+// Katalog publik toko: item aktif yang masih ada stoknya, terbaru lebih dulu
+func (r *itemRepository) ListActiveByShop(shopID uuid.UUID, limit int) ([]entity.Item, error) {
+	query := `
+		SELECT id, shop_id, category_id, global_category_id, name, description, price, stock, condition, status, created_at, updated_at
+		FROM items
+		WHERE shop_id = $1 AND status = 'active' AND stock > 0
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, shopID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []entity.Item{}
+	for rows.Next() {
+		var item entity.Item
+		if err := rows.Scan(
+			&item.ID, &item.ShopID, &item.CategoryID, &item.GlobalCategoryID, &item.Name, &item.Description,
+			&item.Price, &item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+const itemColumns = `id, shop_id, category_id, global_category_id, source_offer_id, name, description, price, stock, condition, status, created_at, updated_at`
+
+func scanItems(rows *sql.Rows) ([]entity.Item, error) {
+	defer rows.Close()
+
+	items := []entity.Item{}
+	for rows.Next() {
+		var item entity.Item
+		if err := rows.Scan(
+			&item.ID, &item.ShopID, &item.CategoryID, &item.GlobalCategoryID, &item.SourceOfferID, &item.Name, &item.Description,
+			&item.Price, &item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+var inventorySorts = map[string]string{
+	"newest":     "created_at DESC",
+	"oldest":     "created_at ASC",
+	"updated":    "updated_at DESC",
+	"name":       "LOWER(name) ASC",
+	"price_asc":  "price ASC",
+	"price_desc": "price DESC",
+	"stock_asc":  "stock ASC",
+	"stock_desc": "stock DESC",
+}
+
+// ListInventory: semua item toko (termasuk draft, inactive, moderated) untuk tampilan seller.
+func (r *itemRepository) ListInventory(shopID uuid.UUID, filter entity.InventoryFilter) ([]entity.Item, error) {
+	args := []interface{}{shopID}
+	where := []string{"shop_id = $1"}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		where = append(where, "status = "+arg(filter.Status))
+	}
+	if filter.CategoryID != uuid.Nil {
+		// termasuk sub-kategori
+		where = append(where, `category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = `+arg(filter.CategoryID)+`
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree
+		)`)
+	}
+	switch filter.Stock {
+	case "in_stock":
+		where = append(where, "stock > 0")
+	case "low":
+		where = append(where, "stock BETWEEN 1 AND "+arg(entity.LowStockThreshold))
+	case "out":
+		where = append(where, "stock = 0")
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		p := arg("%" + q + "%")
+		where = append(where, "(name ILIKE "+p+" OR description ILIKE "+p+")")
+	}
+
+	order, ok := inventorySorts[filter.Sort]
+	if !ok {
+		order = inventorySorts["newest"]
+	}
+
+	query := `SELECT ` + itemColumns + ` FROM items WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY ` + order + `, id LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanItems(rows)
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+func (r *itemRepository) ListByIDs(shopID uuid.UUID, ids []uuid.UUID) ([]entity.Item, error) {
+	rows, err := r.db.Query(`
+		SELECT `+itemColumns+` FROM items WHERE shop_id = $1 AND id = ANY($2::uuid[])
+	`, shopID, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, err
+	}
+	return scanItems(rows)
+}
+
+func (r *itemRepository) UpdateStatusBulk(shopID uuid.UUID, ids []uuid.UUID, status string) error {
+	_, err := r.db.Exec(`
+		UPDATE items SET status = $1, updated_at = NOW()
+		WHERE shop_id = $2 AND id = ANY($3::uuid[]) AND status <> 'moderated'
+	`, status, shopID, pq.Array(uuidStrings(ids)))
+	return err
+}
+
+func (r *itemRepository) ListImageURLs(itemID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(`SELECT image_url FROM item_images WHERE item_id = $1 ORDER BY created_at`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := []string{}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+// PublishDraft menyimpan gambar tambahan dan mengubah draft menjadi active dalam satu transaksi;
+// false jika item sudah bukan draft.
+func (r *itemRepository) PublishDraft(itemID uuid.UUID, images []entity.ItemImage) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE items SET status = 'active', updated_at = NOW() WHERE id = $1 AND status = 'draft'
+	`, itemID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	for _, img := range images {
+		if _, err := tx.Exec(`
+			INSERT INTO item_images (id, item_id, image_url, created_at) VALUES ($1, $2, $3, NOW())
+		`, img.ID, itemID, img.ImageURL); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
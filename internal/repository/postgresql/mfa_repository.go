@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	GetByUserID(userID uuid.UUID) (*entity.UserMFA, error)
+	SavePending(userID uuid.UUID, secretEncrypted string) error
+	Enable(userID uuid.UUID) error
+	Delete(userID uuid.UUID) error
+	ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	UseTimeStep(userID uuid.UUID, step int64) (bool, error)
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetByUserID(userID uuid.UUID) (*entity.UserMFA, error) {
+	var mfa entity.UserMFA
+	query := `
+		SELECT user_id, secret_encrypted, enabled_at, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+	err := r.db.QueryRow(query, userID).Scan(
+		&mfa.UserID, &mfa.SecretEncrypted, &mfa.EnabledAt, &mfa.CreatedAt, &mfa.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SavePending menyimpan secret baru yang belum dikonfirmasi (enabled_at = NULL).
+func (r *mfaRepository) SavePending(userID uuid.UUID, secretEncrypted string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret_encrypted, enabled_at, created_at, updated_at)
+		VALUES ($1, $2, NULL, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_encrypted = EXCLUDED.secret_encrypted, enabled_at = NULL, last_used_step = NULL, updated_at = NOW()
+	`
+	_, err := r.db.Exec(query, userID, secretEncrypted)
+	return err
+}
+
+func (r *mfaRepository) Enable(userID uuid.UUID) error {
+	query := `UPDATE user_mfa SET enabled_at = NOW(), updated_at = NOW() WHERE user_id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+func (r *mfaRepository) Delete(userID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes menghapus semua recovery code lama dan menyimpan yang baru.
+func (r *mfaRepository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, NOW())`
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(query, uuid.New(), userID, hash); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	res, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UseTimeStep mencatat time step TOTP yang baru dipakai. Mengembalikan false jika step
+// tersebut (atau yang lebih baru) sudah pernah dipakai, yaitu indikasi replay.
+func (r *mfaRepository) UseTimeStep(userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa SET last_used_step = $2, updated_at = NOW()
+		WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+	res, err := r.db.Exec(query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
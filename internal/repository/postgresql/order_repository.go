@@ -18,6 +18,7 @@ type OrderRepository interface {
 	UpdateOrderStatus(orderID uuid.UUID, status string) error
 	UpdateOrderShipment(orderID uuid.UUID, courier string, receipt string) error
 	GetOrderItems(orderID uuid.UUID) ([]entity.OrderItem, error)
+	GetOrdersByBuyerID(buyerID uuid.UUID) ([]entity.Order, error)
 }
 
 // Struct koneksi untuk OrderRepository
@@ -34,15 +35,17 @@ func NewOrderRepository(db *sql.DB) OrderRepository {
 func (r *orderRepository) getItemByID(id uuid.UUID) (*entity.Item, error) {
 	var item entity.Item
 	query := `
-		SELECT id, shop_id, category_id, name, description, price, stock, condition, status, created_at, updated_at
+		SELECT id, shop_id, category_id, global_category_id, name, description, price, stock, condition, status, created_at, updated_at,
+			EXISTS(SELECT 1 FROM shops s WHERE s.id = items.shop_id AND s.verification_status = 'verified')
 		FROM items WHERE id = $1
 	`
 	// Perhatikan: CategoryID di Item struct harus berupa sql.NullUUID jika boleh NULL
 	// Jika CategoryID didefinisikan sebagai uuid.UUID di struct entity.Item,
 	// maka harus ada penanganan khusus jika nilainya NULL di DB.
 	err := r.db.QueryRow(query, id).Scan(
-		&item.ID, &item.ShopID, &item.CategoryID, &item.Name, &item.Description,
+		&item.ID, &item.ShopID, &item.CategoryID, &item.GlobalCategoryID, &item.Name, &item.Description,
 		&item.Price, &item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt,
+		&item.ShopVerified,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -55,9 +58,14 @@ func (r *orderRepository) GetMarketItems(filter entity.ItemFilter) ([]entity.Ite
 	var items []entity.Item
 	
 	baseQuery := `
-		SELECT id, shop_id, category_id, name, description, price, stock, condition, status, created_at, updated_at
+		SELECT id, shop_id, category_id, global_category_id, name, description, price, stock, condition, status, created_at, updated_at,
+			EXISTS(SELECT 1 FROM shops s WHERE s.id = items.shop_id AND s.verification_status = 'verified')
 		FROM items
 		WHERE status = 'active' AND stock > 0
+			AND shop_id NOT IN (
+				SELECT id FROM shops
+				WHERE vacation_mode AND (vacation_until IS NULL OR vacation_until > NOW())
+			)
 	`
 	args := []interface{}{}
 	whereClauses := []string{}
@@ -68,7 +76,26 @@ func (r *orderRepository) GetMarketItems(filter entity.ItemFilter) ([]entity.Ite
 		whereClauses = append(whereClauses, fmt.Sprintf("(name ILIKE '%s' OR description ILIKE '%s')", keyword, keyword))
 	}
 	if filter.CategoryID != uuid.Nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("category_id = '%s'", filter.CategoryID.String()))
+		// termasuk semua sub-kategori di bawahnya
+		whereClauses = append(whereClauses, fmt.Sprintf(`category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE id = '%s'
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree
+		)`, filter.CategoryID.String()))
+	}
+	if filter.GlobalCategoryID != uuid.Nil {
+		// browsing lintas toko lewat taksonomi global, termasuk sub-kategorinya
+		whereClauses = append(whereClauses, fmt.Sprintf(`global_category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM global_categories WHERE id = '%s'
+				UNION ALL
+				SELECT g.id FROM global_categories g JOIN tree t ON g.parent_id = t.id
+			)
+			SELECT id FROM tree
+		)`, filter.GlobalCategoryID.String()))
 	}
 	if filter.MinPrice > 0 {
 		whereClauses = append(whereClauses, fmt.Sprintf("price >= %f", filter.MinPrice))
@@ -76,6 +103,9 @@ func (r *orderRepository) GetMarketItems(filter entity.ItemFilter) ([]entity.Ite
 	if filter.MaxPrice > 0 {
 		whereClauses = append(whereClauses, fmt.Sprintf("price <= %f", filter.MaxPrice))
 	}
+	if filter.VerifiedOnly {
+		whereClauses = append(whereClauses, "shop_id IN (SELECT id FROM shops WHERE verification_status = 'verified')")
+	}
 
 	if len(whereClauses) > 0 {
 		baseQuery += " AND " + strings.Join(whereClauses, " AND ")
@@ -99,8 +129,8 @@ func (r *orderRepository) GetMarketItems(filter entity.ItemFilter) ([]entity.Ite
 		var item entity.Item
 		// Scan semua field (Asumsi struct entity.Item lengkap)
 		err := rows.Scan(
-			&item.ID, &item.ShopID, &item.CategoryID, &item.Name, &item.Description, &item.Price, 
-			&item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt,
+			&item.ID, &item.ShopID, &item.CategoryID, &item.GlobalCategoryID, &item.Name, &item.Description, &item.Price, 
+			&item.Stock, &item.Condition, &item.Status, &item.CreatedAt, &item.UpdatedAt, &item.ShopVerified,
 		)
 		if err != nil {
 			return nil, err
@@ -214,4 +244,33 @@ func (r *orderRepository) GetOrderItems(orderID uuid.UUID) ([]entity.OrderItem,
 		items = append(items, item)
 	}
 	return items, nil
-}
\ No newline at end of file
+}
+
+// Semua order milik buyer, dipakai untuk ekspor data pribadi
+func (r *orderRepository) GetOrdersByBuyerID(buyerID uuid.UUID) ([]entity.Order, error) {
+	var orders []entity.Order
+	query := `
+		SELECT id, buyer_id, shop_id, total_price, status, shipping_address, shipping_courier, COALESCE(shipping_receipt, ''), created_at, updated_at
+		FROM orders
+		WHERE buyer_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(query, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order entity.Order
+		err := rows.Scan(
+			&order.ID, &order.BuyerID, &order.ShopID, &order.TotalPrice, &order.Status,
+			&order.ShippingAddress, &order.ShippingCourier, &order.ShippingReceipt, &order.CreatedAt, &order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
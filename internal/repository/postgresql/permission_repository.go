@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type PermissionRepository interface {
+	List() ([]entity.Permission, error)
+	GetByID(id uuid.UUID) (*entity.Permission, error)
+	GetByName(name string) (*entity.Permission, error)
+	Create(perm *entity.Permission) error
+	Delete(id uuid.UUID) error
+	GetRoleIDs(permissionID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type permissionRepository struct {
+	db *sql.DB
+}
+
+func NewPermissionRepository(db *sql.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+const permissionColumns = `id, name, COALESCE(resource, ''), COALESCE(action, ''), COALESCE(description, '')`
+
+func (r *permissionRepository) List() ([]entity.Permission, error) {
+	rows, err := r.db.Query(`SELECT ` + permissionColumns + ` FROM permissions ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []entity.Permission
+	for rows.Next() {
+		var p entity.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *permissionRepository) get(query string, arg interface{}) (*entity.Permission, error) {
+	var p entity.Permission
+	err := r.db.QueryRow(query, arg).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *permissionRepository) GetByID(id uuid.UUID) (*entity.Permission, error) {
+	return r.get(`SELECT `+permissionColumns+` FROM permissions WHERE id = $1`, id)
+}
+
+func (r *permissionRepository) GetByName(name string) (*entity.Permission, error) {
+	return r.get(`SELECT `+permissionColumns+` FROM permissions WHERE name = $1`, name)
+}
+
+func (r *permissionRepository) Create(perm *entity.Permission) error {
+	query := `INSERT INTO permissions (id, name, resource, action, description) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.Exec(query, perm.ID, perm.Name, perm.Resource, perm.Action, perm.Description)
+	return err
+}
+
+// Delete menghapus permission beserta seluruh grant-nya ke role.
+func (r *permissionRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE permission_id = $1`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM permissions WHERE id = $1`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *permissionRepository) GetRoleIDs(permissionID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`SELECT role_id FROM role_permissions WHERE permission_id = $1`, permissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// anonymizedValue menggantikan data pribadi yang dihapus dari catatan yang tetap disimpan.
+const anonymizedValue = "[deleted]"
+
+type PrivacyRepository interface {
+	CreateDeletionRequest(req *entity.AccountDeletionRequest) error
+	GetPendingDeletion(userID uuid.UUID) (*entity.AccountDeletionRequest, error)
+	CancelDeletion(userID uuid.UUID) (bool, error)
+	ListDueDeletions(now time.Time) ([]entity.AccountDeletionRequest, error)
+	AnonymizeUser(requestID, userID uuid.UUID) ([]string, error)
+}
+
+type privacyRepository struct {
+	db *sql.DB
+}
+
+func NewPrivacyRepository(db *sql.DB) PrivacyRepository {
+	return &privacyRepository{db: db}
+}
+
+func (r *privacyRepository) CreateDeletionRequest(req *entity.AccountDeletionRequest) error {
+	query := `
+		INSERT INTO account_deletion_requests (id, user_id, requested_at, scheduled_for)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.db.Exec(query, req.ID, req.UserID, req.RequestedAt, req.ScheduledFor)
+	return err
+}
+
+func (r *privacyRepository) GetPendingDeletion(userID uuid.UUID) (*entity.AccountDeletionRequest, error) {
+	var req entity.AccountDeletionRequest
+	query := `
+		SELECT id, user_id, requested_at, scheduled_for, cancelled_at, completed_at
+		FROM account_deletion_requests
+		WHERE user_id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+	`
+	err := r.db.QueryRow(query, userID).Scan(
+		&req.ID, &req.UserID, &req.RequestedAt, &req.ScheduledFor, &req.CancelledAt, &req.CompletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *privacyRepository) CancelDeletion(userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE account_deletion_requests SET cancelled_at = NOW()
+		WHERE user_id = $1 AND cancelled_at IS NULL AND completed_at IS NULL
+	`
+	res, err := r.db.Exec(query, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *privacyRepository) ListDueDeletions(now time.Time) ([]entity.AccountDeletionRequest, error) {
+	query := `
+		SELECT id, user_id, requested_at, scheduled_for, cancelled_at, completed_at
+		FROM account_deletion_requests
+		WHERE cancelled_at IS NULL AND completed_at IS NULL AND scheduled_for <= $1
+		ORDER BY scheduled_for
+	`
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []entity.AccountDeletionRequest
+	for rows.Next() {
+		var req entity.AccountDeletionRequest
+		if err := rows.Scan(
+			&req.ID, &req.UserID, &req.RequestedAt, &req.ScheduledFor, &req.CancelledAt, &req.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// AnonymizeUser menghapus data pribadi user dalam satu transaksi. Order tetap
+// disimpan (nilai, status, item) untuk catatan keuangan, hanya alamat kirimnya
+// yang dihapus. Akun dinonaktifkan, semua kredensial dicabut, IP dan user agent
+// sesi dikosongkan, dan toko milik user ditutup. Dokumen KYC toko dihapus dari
+// database; storage_path-nya dikembalikan agar pemanggil menghapus file setelah
+// transaksi berhasil.
+func (r *privacyRepository) AnonymizeUser(requestID, userID uuid.UUID) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		DELETE FROM shop_verification_documents
+		WHERE verification_id IN (
+			SELECT v.id FROM shop_verifications v JOIN shops s ON s.id = v.shop_id WHERE s.user_id = $1
+		)
+		RETURNING storage_path
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	documentPaths := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		documentPaths = append(documentPaths, path)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`UPDATE orders SET shipping_address = $2, updated_at = NOW() WHERE buyer_id = $1`, []interface{}{userID, anonymizedValue}},
+		{`UPDATE offers SET location = $2, updated_at = NOW() WHERE giver_id = $1`, []interface{}{userID, anonymizedValue}},
+		{`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, []interface{}{userID}},
+		// sesi dicabut dan IP/device-nya dihapus, termasuk sesi lama yang sudah dicabut
+		{`UPDATE user_sessions SET revoked_at = COALESCE(revoked_at, NOW()), ip_address = '', user_agent = '' WHERE user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM user_tokens WHERE user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM user_identities WHERE user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM user_mfa WHERE user_id = $1`, []interface{}{userID}},
+		// toko milik user ditutup: item diarsipkan (nonaktif), API key dicabut, staf dan
+		// undangan dilepas. Nama toko tetap agar riwayat order pembeli masih terbaca.
+		{`UPDATE items SET status = 'inactive', updated_at = NOW() WHERE shop_id IN (SELECT id FROM shops WHERE user_id = $1) AND status IN ('draft', 'active')`, []interface{}{userID}},
+		{`UPDATE shop_api_keys SET revoked_at = NOW() WHERE shop_id IN (SELECT id FROM shops WHERE user_id = $1) AND revoked_at IS NULL`, []interface{}{userID}},
+		{`UPDATE shop_invitations SET status = 'revoked', responded_at = NOW() WHERE (user_id = $1 OR shop_id IN (SELECT id FROM shops WHERE user_id = $1)) AND status = 'pending'`, []interface{}{userID}},
+		{`DELETE FROM shop_members WHERE user_id = $1 OR shop_id IN (SELECT id FROM shops WHERE user_id = $1)`, []interface{}{userID}},
+		// keputusan verifikasi tetap sebagai catatan, catatan bebas dari seller dihapus
+		{`UPDATE shop_verifications SET note = '' WHERE shop_id IN (SELECT id FROM shops WHERE user_id = $1)`, []interface{}{userID}},
+		{`
+			UPDATE shops SET
+				description = '',
+				address = $2,
+				logo_url = '',
+				banner_url = '',
+				vacation_mode = FALSE,
+				vacation_until = NULL,
+				vacation_message = '',
+				updated_at = NOW()
+			WHERE user_id = $1
+		`, []interface{}{userID, anonymizedValue}},
+		// username dan email diganti nilai unik agar bisa dipakai mendaftar lagi
+		{`
+			UPDATE users SET
+				username = 'deleted_' || replace(id::text, '-', ''),
+				email = 'deleted_' || replace(id::text, '-', '') || '@deleted.invalid',
+				full_name = $2,
+				password_hash = '',
+				is_active = FALSE,
+				token_version = token_version + 1,
+				deleted_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1
+		`, []interface{}{userID, anonymizedValue}},
+		{`UPDATE account_deletion_requests SET completed_at = NOW() WHERE id = $1`, []interface{}{requestID}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return documentPaths, nil
+}
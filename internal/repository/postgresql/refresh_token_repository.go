@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *entity.RefreshToken) error
+	GetByHash(tokenHash string) (*entity.RefreshToken, error)
+	Rotate(oldID uuid.UUID, newToken *entity.RefreshToken) (bool, error)
+	RevokeFamily(familyID uuid.UUID) error
+	RevokeAllByUser(userID uuid.UUID) error
+}
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(token *entity.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, mfa_verified, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+	_, err := r.db.Exec(query, token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.MFAVerified)
+	return err
+}
+
+func (r *refreshTokenRepository) GetByHash(tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, mfa_verified, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.MFAVerified, &token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate menandai token lama sebagai revoked dan menyimpan penggantinya dalam satu transaksi.
+// Mengembalikan false jika token lama sudah lebih dulu dirotasi (indikasi token dipakai ulang).
+func (r *refreshTokenRepository) Rotate(oldID uuid.UUID, newToken *entity.RefreshToken) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(
+		`UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		newToken.ID, oldID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	insertQuery := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, mfa_verified, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+	if _, err := tx.Exec(insertQuery, newToken.ID, newToken.UserID, newToken.FamilyID, newToken.TokenHash, newToken.ExpiresAt, newToken.MFAVerified); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, familyID)
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeAllByUser(userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
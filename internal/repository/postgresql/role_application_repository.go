@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type RoleApplicationRepository interface {
+	Create(app *entity.RoleApplication) error
+	GetByID(id uuid.UUID) (*entity.RoleApplication, error)
+	ListByUserID(userID uuid.UUID) ([]entity.RoleApplication, error)
+	ListByStatus(status string) ([]entity.RoleApplication, error)
+	HasPending(userID, roleID uuid.UUID) (bool, error)
+	UpdateReview(app *entity.RoleApplication) (bool, error)
+	Approve(app *entity.RoleApplication) (bool, error)
+}
+
+type roleApplicationRepository struct {
+	db *sql.DB
+}
+
+func NewRoleApplicationRepository(db *sql.DB) RoleApplicationRepository {
+	return &roleApplicationRepository{db: db}
+}
+
+const roleApplicationSelect = `
+	SELECT ra.id, ra.user_id, ra.role_id, TRIM(r.name), ra.reason, ra.status,
+		ra.reviewed_by, ra.review_note, ra.reviewed_at, ra.created_at, ra.updated_at
+	FROM role_applications ra
+	JOIN roles r ON r.id = ra.role_id
+`
+
+const updateReviewQuery = `
+	UPDATE role_applications
+	SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = $4, updated_at = NOW()
+	WHERE id = $5 AND status = 'pending'
+`
+
+func scanRoleApplication(scanner interface{ Scan(dest ...interface{}) error }) (*entity.RoleApplication, error) {
+	var app entity.RoleApplication
+	err := scanner.Scan(
+		&app.ID, &app.UserID, &app.RoleID, &app.RoleName, &app.Reason, &app.Status,
+		&app.ReviewedBy, &app.ReviewNote, &app.ReviewedAt, &app.CreatedAt, &app.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *roleApplicationRepository) Create(app *entity.RoleApplication) error {
+	query := `
+		INSERT INTO role_applications (id, user_id, role_id, reason, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	`
+	_, err := r.db.Exec(query, app.ID, app.UserID, app.RoleID, app.Reason, app.Status)
+	return err
+}
+
+func (r *roleApplicationRepository) GetByID(id uuid.UUID) (*entity.RoleApplication, error) {
+	app, err := scanRoleApplication(r.db.QueryRow(roleApplicationSelect+` WHERE ra.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return app, err
+}
+
+func (r *roleApplicationRepository) ListByUserID(userID uuid.UUID) ([]entity.RoleApplication, error) {
+	return r.list(roleApplicationSelect+` WHERE ra.user_id = $1 ORDER BY ra.created_at DESC`, userID)
+}
+
+// ListByStatus mengembalikan semua pengajuan jika status kosong.
+func (r *roleApplicationRepository) ListByStatus(status string) ([]entity.RoleApplication, error) {
+	if status == "" {
+		return r.list(roleApplicationSelect + ` ORDER BY ra.created_at ASC`)
+	}
+	return r.list(roleApplicationSelect+` WHERE ra.status = $1 ORDER BY ra.created_at ASC`, status)
+}
+
+func (r *roleApplicationRepository) HasPending(userID, roleID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM role_applications
+			WHERE user_id = $1 AND role_id = $2 AND status = 'pending'
+		)
+	`
+	err := r.db.QueryRow(query, userID, roleID).Scan(&exists)
+	return exists, err
+}
+
+// UpdateReview menyimpan hasil review pengajuan yang masih pending; false jika sudah direview.
+func (r *roleApplicationRepository) UpdateReview(app *entity.RoleApplication) (bool, error) {
+	res, err := r.db.Exec(updateReviewQuery, app.Status, app.ReviewedBy, app.ReviewNote, app.ReviewedAt, app.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Approve menyimpan review dan memberikan role ke pemohon dalam satu transaksi,
+// sekaligus menaikkan token_version agar claims role di token lama tidak dipakai lagi.
+// Mengembalikan false jika pengajuan sudah tidak pending.
+func (r *roleApplicationRepository) Approve(app *entity.RoleApplication) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(updateReviewQuery, app.Status, app.ReviewedBy, app.ReviewNote, app.ReviewedAt, app.ID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return false, err
+	} else if n == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_roles (user_id, role_id, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT DO NOTHING
+	`, app.UserID, app.RoleID); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1`, app.UserID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *roleApplicationRepository) list(query string, args ...interface{}) ([]entity.RoleApplication, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := []entity.RoleApplication{}
+	for rows.Next() {
+		app, err := scanRoleApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, *app)
+	}
+	return apps, rows.Err()
+}
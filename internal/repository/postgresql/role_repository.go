@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type RoleRepository interface {
+	GetByName(name string) (*entity.Role, error)
+	GetByID(id uuid.UUID) (*entity.Role, error)
+	List() ([]entity.Role, error)
+	Create(role *entity.Role) error
+	Update(role *entity.Role) error
+	Delete(id uuid.UUID) error
+	CountUsers(roleID uuid.UUID) (int, error)
+	GetPermissions(roleID uuid.UUID) ([]entity.Permission, error)
+	GrantPermission(roleID, permissionID uuid.UUID) error
+	RevokePermission(roleID, permissionID uuid.UUID) (bool, error)
+	BumpTokenVersionByRole(roleID uuid.UUID) error
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetByName(name string) (*entity.Role, error) {
+	var role entity.Role
+	query := `SELECT id, TRIM(name), COALESCE(description, '') FROM roles WHERE name = $1`
+	err := r.db.QueryRow(query, name).Scan(&role.ID, &role.Name, &role.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByID(id uuid.UUID) (*entity.Role, error) {
+	var role entity.Role
+	query := `SELECT id, TRIM(name), COALESCE(description, '') FROM roles WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&role.ID, &role.Name, &role.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) List() ([]entity.Role, error) {
+	rows, err := r.db.Query(`SELECT id, TRIM(name), COALESCE(description, '') FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []entity.Role
+	for rows.Next() {
+		var role entity.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *roleRepository) Create(role *entity.Role) error {
+	query := `INSERT INTO roles (id, name, description) VALUES ($1, $2, $3)`
+	_, err := r.db.Exec(query, role.ID, role.Name, role.Description)
+	return err
+}
+
+func (r *roleRepository) Update(role *entity.Role) error {
+	query := `UPDATE roles SET name = $2, description = $3 WHERE id = $1`
+	_, err := r.db.Exec(query, role.ID, role.Name, role.Description)
+	return err
+}
+
+// Delete menghapus role beserta grant permission-nya. Pemanggil wajib memastikan
+// tidak ada user yang masih memegang role ini.
+func (r *roleRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM roles WHERE id = $1`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepository) CountUsers(roleID uuid.UUID) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(DISTINCT u.id) FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		WHERE u.role_id = $1 OR ur.role_id = $1`
+	err := r.db.QueryRow(query, roleID).Scan(&count)
+	return count, err
+}
+
+func (r *roleRepository) GetPermissions(roleID uuid.UUID) ([]entity.Permission, error) {
+	query := `
+		SELECT p.id, p.name, COALESCE(p.resource, ''), COALESCE(p.action, ''), COALESCE(p.description, '')
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.name`
+	rows, err := r.db.Query(query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []entity.Permission
+	for rows.Next() {
+		var p entity.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *roleRepository) GrantPermission(roleID, permissionID uuid.UUID) error {
+	query := `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(query, roleID, permissionID)
+	return err
+}
+
+func (r *roleRepository) RevokePermission(roleID, permissionID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`, roleID, permissionID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// BumpTokenVersionByRole memaksa semua pemegang role mengambil token baru
+// sehingga permission yang tertanam di JWT ikut diperbarui.
+func (r *roleRepository) BumpTokenVersionByRole(roleID uuid.UUID) error {
+	query := `
+		UPDATE users SET token_version = token_version + 1, updated_at = NOW()
+		WHERE role_id = $1 OR id IN (SELECT user_id FROM user_roles WHERE role_id = $1)`
+	_, err := r.db.Exec(query, roleID)
+	return err
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	Create(session *entity.UserSession) error
+	ListActiveByUser(userID uuid.UUID) ([]entity.UserSession, error)
+	ListByUser(userID uuid.UUID) ([]entity.UserSession, error)
+	Touch(id uuid.UUID) (bool, error)
+	Extend(id uuid.UUID, client entity.ClientInfo, expiresAt time.Time) error
+	Revoke(id, userID uuid.UUID) (bool, error)
+	RevokeByID(id uuid.UUID) error
+	RevokeAllByUser(userID uuid.UUID) error
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *entity.UserSession) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $5, $6)
+	`
+	_, err := r.db.Exec(query, session.ID, session.UserID, session.IPAddress, session.UserAgent, session.CreatedAt, session.ExpiresAt)
+	return err
+}
+
+func (r *sessionRepository) ListActiveByUser(userID uuid.UUID) ([]entity.UserSession, error) {
+	return r.list(sessionSelect+`
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, userID)
+}
+
+// ListByUser mengembalikan semua sesi user termasuk yang sudah dicabut atau kedaluwarsa (untuk ekspor data).
+func (r *sessionRepository) ListByUser(userID uuid.UUID) ([]entity.UserSession, error) {
+	return r.list(sessionSelect+` WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+const sessionSelect = `
+	SELECT id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at
+	FROM user_sessions
+`
+
+func (r *sessionRepository) list(query string, args ...interface{}) ([]entity.UserSession, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entity.UserSession{}
+	for rows.Next() {
+		var s entity.UserSession
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.IPAddress, &s.UserAgent, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// Touch memperbarui last_seen_at dan mengembalikan false jika sesi sudah dicabut atau tidak ada.
+func (r *sessionRepository) Touch(id uuid.UUID) (bool, error) {
+	query := `UPDATE user_sessions SET last_seen_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	res, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Extend dipanggil saat refresh: memperpanjang sesi dan mencatat IP/perangkat terakhir.
+func (r *sessionRepository) Extend(id uuid.UUID, client entity.ClientInfo, expiresAt time.Time) error {
+	query := `
+		UPDATE user_sessions SET ip_address = $2, user_agent = $3, last_seen_at = NOW(), expires_at = $4
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	_, err := r.db.Exec(query, id, client.IPAddress, client.UserAgent, expiresAt)
+	return err
+}
+
+// Revoke mencabut sesi milik user tertentu; false jika sesi tidak ditemukan.
+func (r *sessionRepository) Revoke(id, userID uuid.UUID) (bool, error) {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	res, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *sessionRepository) RevokeByID(id uuid.UUID) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *sessionRepository) RevokeAllByUser(userID uuid.UUID) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
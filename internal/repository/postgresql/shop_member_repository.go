@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type ShopMemberRepository interface {
+	GetByUserID(userID uuid.UUID) (*entity.ShopMember, error)
+	ListByShop(shopID uuid.UUID) ([]entity.ShopMember, error)
+	UpdateRole(shopID, userID uuid.UUID, role string) (bool, error)
+	Remove(shopID, userID uuid.UUID) (bool, error)
+	HasGlobalPermission(userID uuid.UUID, permission string) (bool, error)
+
+	CreateInvitation(inv *entity.ShopInvitation) error
+	GetInvitationByID(id uuid.UUID) (*entity.ShopInvitation, error)
+	HasPendingInvitation(shopID, userID uuid.UUID) (bool, error)
+	ListPendingByShop(shopID uuid.UUID) ([]entity.ShopInvitation, error)
+	ListPendingByUser(userID uuid.UUID) ([]entity.ShopInvitation, error)
+	RespondInvitation(id uuid.UUID, status string) (bool, error)
+	ExpirePendingInvitations(shopID, userID uuid.UUID) error
+	AcceptInvitation(inv *entity.ShopInvitation) error
+}
+
+type shopMemberRepository struct {
+	db *sql.DB
+}
+
+func NewShopMemberRepository(db *sql.DB) ShopMemberRepository {
+	return &shopMemberRepository{db: db}
+}
+
+const shopMemberColumns = `
+	m.shop_id, m.user_id, u.username, COALESCE(u.full_name, ''), m.role, m.created_at, m.updated_at
+`
+
+func scanShopMember(scanner interface{ Scan(dest ...interface{}) error }) (*entity.ShopMember, error) {
+	var m entity.ShopMember
+	err := scanner.Scan(&m.ShopID, &m.UserID, &m.Username, &m.FullName, &m.Role, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *shopMemberRepository) GetByUserID(userID uuid.UUID) (*entity.ShopMember, error) {
+	query := `SELECT ` + shopMemberColumns + `
+		FROM shop_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1
+	`
+	m, err := scanShopMember(r.db.QueryRow(query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+// HasGlobalPermission membaca permission RBAC langsung dari database (bukan dari claims JWT)
+// agar pencabutan role/permission oleh admin langsung memutus akses ke toko.
+func (r *shopMemberRepository) HasGlobalPermission(userID uuid.UUID, permission string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1
+			FROM user_roles ur
+			JOIN role_permissions rp ON rp.role_id = ur.role_id
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE ur.user_id = $1 AND p.name = $2
+		)
+	`, userID, permission).Scan(&ok)
+	return ok, err
+}
+
+func (r *shopMemberRepository) ListByShop(shopID uuid.UUID) ([]entity.ShopMember, error) {
+	query := `SELECT ` + shopMemberColumns + `
+		FROM shop_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.shop_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END, m.created_at
+	`
+	rows, err := r.db.Query(query, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []entity.ShopMember{}
+	for rows.Next() {
+		m, err := scanShopMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *m)
+	}
+	return members, rows.Err()
+}
+
+// UpdateRole tidak pernah mengubah baris owner.
+func (r *shopMemberRepository) UpdateRole(shopID, userID uuid.UUID, role string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE shop_members SET role = $1, updated_at = NOW()
+		WHERE shop_id = $2 AND user_id = $3 AND role <> 'owner'
+	`, role, shopID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Remove tidak pernah menghapus owner.
+func (r *shopMemberRepository) Remove(shopID, userID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`
+		DELETE FROM shop_members
+		WHERE shop_id = $1 AND user_id = $2 AND role <> 'owner'
+	`, shopID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *shopMemberRepository) CreateInvitation(inv *entity.ShopInvitation) error {
+	_, err := r.db.Exec(`
+		INSERT INTO shop_invitations (id, shop_id, user_id, role, invited_by, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, inv.ID, inv.ShopID, inv.UserID, inv.Role, inv.InvitedBy, inv.Status, inv.ExpiresAt, inv.CreatedAt)
+	return err
+}
+
+const shopInvitationColumns = `
+	i.id, i.shop_id, s.name, i.user_id, u.username, i.role, i.invited_by, i.status,
+	i.expires_at, i.responded_at, i.created_at
+`
+
+const shopInvitationJoins = `
+	FROM shop_invitations i
+	JOIN shops s ON s.id = i.shop_id
+	JOIN users u ON u.id = i.user_id
+`
+
+func scanShopInvitation(scanner interface{ Scan(dest ...interface{}) error }) (*entity.ShopInvitation, error) {
+	var inv entity.ShopInvitation
+	var respondedAt sql.NullTime
+	err := scanner.Scan(
+		&inv.ID, &inv.ShopID, &inv.ShopName, &inv.UserID, &inv.Username, &inv.Role, &inv.InvitedBy,
+		&inv.Status, &inv.ExpiresAt, &respondedAt, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if respondedAt.Valid {
+		inv.RespondedAt = &respondedAt.Time
+	}
+	return &inv, nil
+}
+
+func (r *shopMemberRepository) listInvitations(where string, arg interface{}) ([]entity.ShopInvitation, error) {
+	query := `SELECT ` + shopInvitationColumns + shopInvitationJoins + where + ` ORDER BY i.created_at DESC`
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []entity.ShopInvitation{}
+	for rows.Next() {
+		inv, err := scanShopInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+func (r *shopMemberRepository) GetInvitationByID(id uuid.UUID) (*entity.ShopInvitation, error) {
+	query := `SELECT ` + shopInvitationColumns + shopInvitationJoins + ` WHERE i.id = $1`
+	inv, err := scanShopInvitation(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return inv, err
+}
+
+func (r *shopMemberRepository) HasPendingInvitation(shopID, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM shop_invitations
+			WHERE shop_id = $1 AND user_id = $2 AND status = 'pending' AND expires_at > NOW()
+		)
+	`, shopID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *shopMemberRepository) ListPendingByShop(shopID uuid.UUID) ([]entity.ShopInvitation, error) {
+	return r.listInvitations(` WHERE i.shop_id = $1 AND i.status = 'pending' AND i.expires_at > NOW()`, shopID)
+}
+
+func (r *shopMemberRepository) ListPendingByUser(userID uuid.UUID) ([]entity.ShopInvitation, error) {
+	return r.listInvitations(` WHERE i.user_id = $1 AND i.status = 'pending' AND i.expires_at > NOW()`, userID)
+}
+
+// RespondInvitation menutup undangan pending (declined/revoked); false jika sudah tidak pending.
+func (r *shopMemberRepository) RespondInvitation(id uuid.UUID, status string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE shop_invitations SET status = $1, responded_at = NOW()
+		WHERE id = $2 AND status = 'pending'
+	`, status, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ExpirePendingInvitations menutup undangan pending yang sudah lewat expires_at.
+func (r *shopMemberRepository) ExpirePendingInvitations(shopID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE shop_invitations SET status = 'expired', responded_at = NOW()
+		WHERE shop_id = $1 AND user_id = $2 AND status = 'pending' AND expires_at <= NOW()
+	`, shopID, userID)
+	return err
+}
+
+// AcceptInvitation menandai undangan accepted dan menambahkan anggota dalam satu transaksi.
+// Constraint UNIQUE(user_id) di shop_members mencegah user bergabung ke dua toko.
+func (r *shopMemberRepository) AcceptInvitation(inv *entity.ShopInvitation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE shop_invitations SET status = 'accepted', responded_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, inv.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO shop_members (shop_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+	`, inv.ShopID, inv.UserID, inv.Role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
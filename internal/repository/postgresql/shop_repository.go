@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"time"
 	entity "home-market/internal/domain"
 	"github.com/google/uuid"
 )
@@ -12,6 +13,14 @@ type ShopRepository interface {
 	CreateShop(shop *entity.Shop) error
     GetShopOwnerID(shopID uuid.UUID) (uuid.UUID, error) // Dari ItemRepository sebelumnya
     IsCategoryOwnedByShop(categoryID, shopID uuid.UUID) (bool, error)
+	GetByID(shopID uuid.UUID) (*entity.Shop, error)
+	GetActiveByID(shopID uuid.UUID) (*entity.Shop, error)
+	UpdateShop(shop *entity.Shop) error
+	SearchShops(filter entity.ShopFilter) ([]entity.ShopSummary, error)
+	GetShopStats(shopID uuid.UUID) (*entity.ShopStats, error)
+	GetAvailability(shopID uuid.UUID) (*entity.ShopAvailability, []entity.OperatingSlot, error)
+	UpdateVacation(shopID uuid.UUID, enabled bool, until *time.Time, message string) error
+	ReplaceOperatingHours(shopID uuid.UUID, utcOffsetMinutes int, slots []entity.OperatingSlot) error
 }
 
 type shopRepository struct {
@@ -26,7 +35,8 @@ func (r *shopRepository) GetByUserID(userID uuid.UUID) (*entity.Shop, error) {
 	var shop entity.Shop
 
 	query := `
-		SELECT id, user_id, name, description, address, created_at, updated_at
+		SELECT id, user_id, name, description, address, logo_url, banner_url,
+			verification_status, verified_at, created_at, updated_at
 		FROM shops
 		WHERE user_id = $1
 	`
@@ -37,6 +47,10 @@ func (r *shopRepository) GetByUserID(userID uuid.UUID) (*entity.Shop, error) {
 		&shop.Name,
 		&shop.Description,
 		&shop.Address,
+		&shop.LogoURL,
+		&shop.BannerURL,
+		&shop.VerificationStatus,
+		&shop.VerifiedAt,
 		&shop.CreatedAt,
 		&shop.UpdatedAt,
 	)
@@ -51,21 +65,39 @@ func (r *shopRepository) GetByUserID(userID uuid.UUID) (*entity.Shop, error) {
 	return &shop, nil
 }
 
+// CreateShop sekaligus mendaftarkan pembuatnya sebagai anggota dengan role owner.
 func (r *shopRepository) CreateShop(shop *entity.Shop) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO shops (id, user_id, name, description, address, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 	`
 
-	_, err := r.db.Exec(query,
+	_, err = tx.Exec(query,
 		shop.ID,
 		shop.UserID,
 		shop.Name,
 		shop.Description,
 		shop.Address,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	memberQuery := `
+		INSERT INTO shop_members (shop_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, 'owner', NOW(), NOW())
+	`
+	if _, err := tx.Exec(memberQuery, shop.ID, shop.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // [internal/repository/postgresql/item_repository.go]
@@ -98,4 +130,200 @@ func (r *shopRepository) IsCategoryOwnedByShop(categoryID, shopID uuid.UUID) (bo
 
 	err := r.db.QueryRow(query, categoryID, shopID).Scan(&exists)
 	return exists, err
-}
\ No newline at end of file
+}
+
+func (r *shopRepository) GetByID(shopID uuid.UUID) (*entity.Shop, error) {
+	var shop entity.Shop
+
+	query := `
+		SELECT id, user_id, name, description, address, logo_url, banner_url,
+			verification_status, verified_at, created_at, updated_at
+		FROM shops
+		WHERE id = $1
+	`
+
+	err := r.db.QueryRow(query, shopID).Scan(
+		&shop.ID,
+		&shop.UserID,
+		&shop.Name,
+		&shop.Description,
+		&shop.Address,
+		&shop.LogoURL,
+		&shop.BannerURL,
+		&shop.VerificationStatus,
+		&shop.VerifiedAt,
+		&shop.CreatedAt,
+		&shop.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
+
+// GetActiveByID seperti GetByID, tetapi hanya toko dengan pemilik aktif (sama dengan
+// filter SearchShops) untuk halaman publik.
+func (r *shopRepository) GetActiveByID(shopID uuid.UUID) (*entity.Shop, error) {
+	var shop entity.Shop
+
+	query := `
+		SELECT s.id, s.user_id, s.name, s.description, s.address, s.logo_url, s.banner_url,
+			s.verification_status, s.verified_at, s.created_at, s.updated_at
+		FROM shops s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND u.is_active = TRUE
+	`
+
+	err := r.db.QueryRow(query, shopID).Scan(
+		&shop.ID,
+		&shop.UserID,
+		&shop.Name,
+		&shop.Description,
+		&shop.Address,
+		&shop.LogoURL,
+		&shop.BannerURL,
+		&shop.VerificationStatus,
+		&shop.VerifiedAt,
+		&shop.CreatedAt,
+		&shop.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &shop, nil
+}
+
+func (r *shopRepository) UpdateShop(shop *entity.Shop) error {
+	query := `
+		UPDATE shops
+		SET name = $1, description = $2, address = $3, logo_url = $4, banner_url = $5, updated_at = NOW()
+		WHERE id = $6
+	`
+	_, err := r.db.Exec(query, shop.Name, shop.Description, shop.Address, shop.LogoURL, shop.BannerURL, shop.ID)
+	return err
+}
+
+// SearchShops untuk direktori toko publik; hanya toko dengan pemilik aktif.
+func (r *shopRepository) SearchShops(filter entity.ShopFilter) ([]entity.ShopSummary, error) {
+	query := `
+		SELECT s.id, s.name, s.description, s.logo_url, s.verification_status = 'verified', s.created_at,
+			(SELECT COUNT(*) FROM items i WHERE i.shop_id = s.id AND i.status = 'active' AND i.stock > 0)
+		FROM shops s
+		JOIN users u ON u.id = s.user_id
+		WHERE u.is_active = TRUE AND ($1 = '' OR STRPOS(LOWER(s.name), LOWER($1)) > 0)
+		ORDER BY LOWER(s.name), s.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(query, filter.Keyword, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shops := []entity.ShopSummary{}
+	for rows.Next() {
+		var s entity.ShopSummary
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.LogoURL, &s.Verified, &s.CreatedAt, &s.ActiveItems); err != nil {
+			return nil, err
+		}
+		shops = append(shops, s)
+	}
+	return shops, rows.Err()
+}
+
+func (r *shopRepository) GetShopStats(shopID uuid.UUID) (*entity.ShopStats, error) {
+	var stats entity.ShopStats
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM items WHERE shop_id = $1 AND status = 'active' AND stock > 0),
+			(SELECT COUNT(*) FROM orders WHERE shop_id = $1 AND status = 'completed'),
+			(SELECT COALESCE(SUM(oi.quantity), 0)
+				FROM order_items oi JOIN orders o ON o.id = oi.order_id
+				WHERE o.shop_id = $1 AND o.status = 'completed')
+	`
+	err := r.db.QueryRow(query, shopID).Scan(&stats.ActiveItems, &stats.CompletedOrders, &stats.ItemsSold)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetAvailability mengembalikan data libur toko dan jadwal mentahnya; Hours dan IsOpen diisi service.
+func (r *shopRepository) GetAvailability(shopID uuid.UUID) (*entity.ShopAvailability, []entity.OperatingSlot, error) {
+	var a entity.ShopAvailability
+	var until sql.NullTime
+
+	query := `SELECT vacation_mode, vacation_until, vacation_message, utc_offset_minutes FROM shops WHERE id = $1`
+	err := r.db.QueryRow(query, shopID).Scan(&a.VacationMode, &until, &a.VacationMessage, &a.UTCOffsetMinutes)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if until.Valid {
+		a.VacationUntil = &until.Time
+	}
+
+	rows, err := r.db.Query(`
+		SELECT weekday, open_minute, close_minute
+		FROM shop_operating_hours
+		WHERE shop_id = $1
+		ORDER BY weekday, open_minute
+	`, shopID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	slots := []entity.OperatingSlot{}
+	for rows.Next() {
+		var slot entity.OperatingSlot
+		if err := rows.Scan(&slot.Weekday, &slot.OpenMinute, &slot.CloseMinute); err != nil {
+			return nil, nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return &a, slots, rows.Err()
+}
+
+func (r *shopRepository) UpdateVacation(shopID uuid.UUID, enabled bool, until *time.Time, message string) error {
+	query := `
+		UPDATE shops
+		SET vacation_mode = $1, vacation_until = $2, vacation_message = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	_, err := r.db.Exec(query, enabled, until, message, shopID)
+	return err
+}
+
+func (r *shopRepository) ReplaceOperatingHours(shopID uuid.UUID, utcOffsetMinutes int, slots []entity.OperatingSlot) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE shops SET utc_offset_minutes = $1, updated_at = NOW() WHERE id = $2`, utcOffsetMinutes, shopID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM shop_operating_hours WHERE shop_id = $1`, shopID); err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		_, err := tx.Exec(`
+			INSERT INTO shop_operating_hours (shop_id, weekday, open_minute, close_minute)
+			VALUES ($1, $2, $3, $4)
+		`, shopID, slot.Weekday, slot.OpenMinute, slot.CloseMinute)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
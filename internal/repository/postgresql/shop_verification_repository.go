@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type ShopVerificationRepository interface {
+	Submit(v *entity.ShopVerification) error
+	GetLatestByShop(shopID uuid.UUID) (*entity.ShopVerification, error)
+	ListByShop(shopID uuid.UUID) ([]entity.ShopVerification, error)
+	GetDocument(verificationID, documentID uuid.UUID) (*entity.VerificationDocument, error)
+	ListShops(filter entity.AdminShopFilter) ([]entity.AdminShopEntry, error)
+	Review(verificationID, reviewerID uuid.UUID, approved bool, note string) (bool, error)
+}
+
+type shopVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewShopVerificationRepository(db *sql.DB) ShopVerificationRepository {
+	return &shopVerificationRepository{db: db}
+}
+
+// Submit menyimpan pengajuan beserta dokumennya dan menandai toko pending dalam satu transaksi.
+func (r *shopVerificationRepository) Submit(v *entity.ShopVerification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO shop_verifications (id, shop_id, submitted_by, note, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, v.ID, v.ShopID, v.SubmittedBy, v.Note, v.Status, v.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range v.Documents {
+		_, err := tx.Exec(`
+			INSERT INTO shop_verification_documents
+				(id, verification_id, doc_type, storage_path, original_name, content_type, size_bytes, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, doc.ID, v.ID, doc.DocType, doc.StoragePath, doc.OriginalName, doc.ContentType, doc.SizeBytes, doc.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE shops SET verification_status = 'pending', updated_at = NOW() WHERE id = $1
+	`, v.ShopID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const verificationColumns = `
+	v.id, v.shop_id, s.name, v.submitted_by, v.note, v.status, v.reviewed_by, v.review_note, v.reviewed_at, v.created_at
+`
+
+func scanVerification(scanner interface{ Scan(dest ...interface{}) error }) (*entity.ShopVerification, error) {
+	var v entity.ShopVerification
+	var reviewedBy uuid.NullUUID
+	var reviewedAt sql.NullTime
+
+	err := scanner.Scan(
+		&v.ID, &v.ShopID, &v.ShopName, &v.SubmittedBy, &v.Note, &v.Status,
+		&reviewedBy, &v.ReviewNote, &reviewedAt, &v.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reviewedBy.Valid {
+		v.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewedAt.Valid {
+		v.ReviewedAt = &reviewedAt.Time
+	}
+	return &v, nil
+}
+
+func (r *shopVerificationRepository) GetLatestByShop(shopID uuid.UUID) (*entity.ShopVerification, error) {
+	v, err := scanVerification(r.db.QueryRow(`
+		SELECT `+verificationColumns+`
+		FROM shop_verifications v
+		JOIN shops s ON s.id = v.shop_id
+		WHERE v.shop_id = $1
+		ORDER BY v.created_at DESC
+		LIMIT 1
+	`, shopID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v.Documents, err = r.listDocuments(v.ID)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ListByShop semua pengajuan toko beserta dokumennya, terbaru lebih dulu (dipakai ekspor data).
+func (r *shopVerificationRepository) ListByShop(shopID uuid.UUID) ([]entity.ShopVerification, error) {
+	rows, err := r.db.Query(`
+		SELECT `+verificationColumns+`
+		FROM shop_verifications v
+		JOIN shops s ON s.id = v.shop_id
+		WHERE v.shop_id = $1
+		ORDER BY v.created_at DESC
+	`, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	verifications := []entity.ShopVerification{}
+	for rows.Next() {
+		v, err := scanVerification(rows)
+		if err != nil {
+			return nil, err
+		}
+		verifications = append(verifications, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range verifications {
+		verifications[i].Documents, err = r.listDocuments(verifications[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return verifications, nil
+}
+
+func (r *shopVerificationRepository) listDocuments(verificationID uuid.UUID) ([]entity.VerificationDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT id, verification_id, doc_type, storage_path, original_name, content_type, size_bytes, created_at
+		FROM shop_verification_documents
+		WHERE verification_id = $1
+		ORDER BY created_at
+	`, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []entity.VerificationDocument{}
+	for rows.Next() {
+		var doc entity.VerificationDocument
+		if err := rows.Scan(
+			&doc.ID, &doc.VerificationID, &doc.DocType, &doc.StoragePath, &doc.OriginalName,
+			&doc.ContentType, &doc.SizeBytes, &doc.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+func (r *shopVerificationRepository) GetDocument(verificationID, documentID uuid.UUID) (*entity.VerificationDocument, error) {
+	var doc entity.VerificationDocument
+	err := r.db.QueryRow(`
+		SELECT id, verification_id, doc_type, storage_path, original_name, content_type, size_bytes, created_at
+		FROM shop_verification_documents
+		WHERE id = $1 AND verification_id = $2
+	`, documentID, verificationID).Scan(
+		&doc.ID, &doc.VerificationID, &doc.DocType, &doc.StoragePath, &doc.OriginalName,
+		&doc.ContentType, &doc.SizeBytes, &doc.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ListShops untuk antrian admin; pengajuan pending terlama tampil lebih dulu.
+func (r *shopVerificationRepository) ListShops(filter entity.AdminShopFilter) ([]entity.AdminShopEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.name, s.user_id, u.username, s.verification_status, s.verified_at, p.created_at, s.created_at
+		FROM shops s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN shop_verifications p ON p.shop_id = s.id AND p.status = 'pending'
+		WHERE ($1 = '' OR s.verification_status = $1)
+		ORDER BY p.created_at ASC NULLS LAST, s.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, filter.VerificationStatus, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shops := []entity.AdminShopEntry{}
+	for rows.Next() {
+		var e entity.AdminShopEntry
+		var verifiedAt, submittedAt sql.NullTime
+		if err := rows.Scan(
+			&e.ID, &e.Name, &e.OwnerID, &e.OwnerUsername, &e.VerificationStatus,
+			&verifiedAt, &submittedAt, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if verifiedAt.Valid {
+			e.VerifiedAt = &verifiedAt.Time
+		}
+		if submittedAt.Valid {
+			e.SubmittedAt = &submittedAt.Time
+		}
+		shops = append(shops, e)
+	}
+	return shops, rows.Err()
+}
+
+// Review menutup pengajuan pending dan memperbarui status toko; false jika pengajuan sudah tidak pending.
+func (r *shopVerificationRepository) Review(verificationID, reviewerID uuid.UUID, approved bool, note string) (bool, error) {
+	status, shopStatus := "rejected", entity.ShopRejected
+	if approved {
+		status, shopStatus = "approved", entity.ShopVerified
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var shopID uuid.UUID
+	err = tx.QueryRow(`
+		UPDATE shop_verifications
+		SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = 'pending'
+		RETURNING shop_id
+	`, status, reviewerID, note, verificationID).Scan(&shopID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE shops
+		SET verification_status = $1,
+			verified_at = CASE WHEN $1 = 'verified' THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $2
+	`, shopStatus, shopID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrUserNotFound dikembalikan GetByUsername/GetByID/GetTokenState jika user tidak ada.
+var ErrUserNotFound = errors.New("user not found")
+
 type UserRepository interface {
 	GetByUsername(username string) (*entity.User, string, error)
 	GetPermissionsByRoleID(roleID uuid.UUID) ([]string, error)
@@ -16,6 +19,17 @@ type UserRepository interface {
 	CreateUser(user *entity.User) error
 	ListAllUsers() ([]entity.User, error) // FR-ADMIN-01
     UpdateUserStatus(userID uuid.UUID, isActive bool) error // FR-ADMIN-03
+	GetTokenState(userID uuid.UUID) (int, bool, error)
+	IncrementTokenVersion(userID uuid.UUID) error
+	UpdatePassword(userID uuid.UUID, passwordHash string) error
+	UpdatePasswordHash(userID uuid.UUID, passwordHash string) error
+	MarkEmailVerified(userID uuid.UUID) error
+	GetRoleNamesByUserID(userID uuid.UUID) ([]string, error)
+	GetPermissionsByUserID(userID uuid.UUID) ([]string, error)
+	AddUserRole(userID, roleID uuid.UUID) error
+	RemoveUserRole(userID, roleID uuid.UUID) (bool, error)
+	UpdateProfile(userID uuid.UUID, username, fullName string) error
+	UpdateEmail(userID uuid.UUID, email string) error
 }
 
 type userRepository struct {
@@ -33,7 +47,7 @@ func (r *userRepository) GetByUsername(username string) (*entity.User, string, e
 	query := `
 		SELECT 
 			u.id, u.username, u.email, u.password_hash, 
-			u.full_name, u.role_id, u.is_active,
+			u.full_name, u.role_id, u.is_active, u.token_version, u.email_verified_at,
 			TRIM(r.name) AS roleName
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
@@ -48,12 +62,14 @@ func (r *userRepository) GetByUsername(username string) (*entity.User, string, e
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
 		&roleName,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", errors.New("user not found")
+			return nil, "", ErrUserNotFound
 		}
 		return nil, "", err
 	}
@@ -91,7 +107,7 @@ func (r *userRepository) GetByID(id uuid.UUID) (*entity.User, error) {
 	var user entity.User
 
 	query := `
-		SELECT id, username, email, full_name, role_id, is_active
+		SELECT id, username, email, full_name, role_id, is_active, token_version, email_verified_at
 		FROM users
 		WHERE id = $1
 	`
@@ -103,10 +119,12 @@ func (r *userRepository) GetByID(id uuid.UUID) (*entity.User, error) {
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -118,7 +136,7 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 	var user entity.User
 
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active
+		SELECT id, username, email, password_hash, full_name, role_id, is_active, email_verified_at
 		FROM users
 		WHERE email = $1
 	`
@@ -131,6 +149,7 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 		&user.FullName,
 		&user.RoleID,
 		&user.IsActive,
+		&user.EmailVerifiedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -143,12 +162,17 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 }
 
 func (r *userRepository) CreateUser(user *entity.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 	`
 
-	_, err := r.db.Exec(query,
+	_, err = tx.Exec(query,
 		user.ID,
 		user.Username,
 		user.Email,
@@ -157,8 +181,18 @@ func (r *userRepository) CreateUser(user *entity.User) error {
 		user.RoleID,
 		user.IsActive,
 	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	return err
+	// role utama juga dicatat di user_roles
+	if _, err := tx.Exec(`INSERT INTO user_roles (user_id, role_id, created_at) VALUES ($1, $2, NOW())`, user.ID, user.RoleID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *userRepository) ListAllUsers() ([]entity.User, error) {
@@ -166,8 +200,9 @@ func (r *userRepository) ListAllUsers() ([]entity.User, error) {
     var users []entity.User 
 
     query := `
-        SELECT id, name, email is_active, created_at, updated_at
+        SELECT id, username, email, full_name, role_id, is_active, created_at, updated_at
         FROM users
+        ORDER BY created_at DESC
     `
     // Eksekusi query
     rows, err := r.db.Query(query)
@@ -183,7 +218,7 @@ func (r *userRepository) ListAllUsers() ([]entity.User, error) {
         // Asumsi struct entity.User mencakup field is_active dll.
         // Anda perlu menyesuaikan Scan() ini agar sesuai dengan struct entity.User Anda.
         err := rows.Scan(
-            &user.ID, &user.FullName, &user.Email, &user.IsActive, 
+            &user.ID, &user.Username, &user.Email, &user.FullName, &user.RoleID, &user.IsActive,
             &user.CreatedAt, &user.UpdatedAt,
         )
         if err != nil {
@@ -202,7 +237,171 @@ func (r *userRepository) ListAllUsers() ([]entity.User, error) {
 }
 
 func (r *userRepository) UpdateUserStatus(userID uuid.UUID, isActive bool) error {
-    query := `UPDATE users SET is_active = $1 WHERE id = $2`
+    // token_version dinaikkan agar token yang sedang beredar langsung tidak berlaku
+    query := `UPDATE users SET is_active = $1, token_version = token_version + 1, updated_at = NOW() WHERE id = $2`
     _, err := r.db.Exec(query, isActive, userID)
     return err
-}
\ No newline at end of file
+}
+
+func (r *userRepository) GetTokenState(userID uuid.UUID) (int, bool, error) {
+	var version int
+	var isActive bool
+
+	query := `SELECT token_version, is_active FROM users WHERE id = $1`
+	err := r.db.QueryRow(query, userID).Scan(&version, &isActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, ErrUserNotFound
+		}
+		return 0, false, err
+	}
+
+	return version, isActive, nil
+}
+
+func (r *userRepository) IncrementTokenVersion(userID uuid.UUID) error {
+	query := `UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+// UpdatePassword sekaligus menaikkan token_version agar sesi lama tidak berlaku.
+func (r *userRepository) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.Exec(query, passwordHash, userID)
+	return err
+}
+
+// UpdatePasswordHash mengganti hash untuk password yang sama (rehash ke parameter baru),
+// jadi token_version tidak dinaikkan dan sesi tetap berlaku.
+func (r *userRepository) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.Exec(query, passwordHash, userID)
+	return err
+}
+
+func (r *userRepository) MarkEmailVerified(userID uuid.UUID) error {
+	query := `UPDATE users SET email_verified_at = NOW(), updated_at = NOW() WHERE id = $1 AND email_verified_at IS NULL`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+func (r *userRepository) GetRoleNamesByUserID(userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT TRIM(r.name)
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		roles = append(roles, name)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetPermissionsByUserID menggabungkan permission dari semua role milik user (tanpa duplikat).
+func (r *userRepository) GetPermissionsByUserID(userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT p.name
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permName string
+		if err := rows.Scan(&permName); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permName)
+	}
+
+	return permissions, rows.Err()
+}
+
+// AddUserRole sekaligus menaikkan token_version agar claims role di token lama tidak dipakai lagi.
+func (r *userRepository) AddUserRole(userID, roleID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_roles (user_id, role_id, created_at) VALUES ($1, $2, NOW()) ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(query, userID, roleID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveUserRole mencabut role dari user dan menaikkan token_version agar
+// claims lama tidak bisa dipakai lagi. Mengembalikan false jika user tidak memegang role tsb.
+func (r *userRepository) RemoveUserRole(userID, roleID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if n == 0 {
+		tx.Rollback()
+		return false, nil
+	}
+	if _, err := tx.Exec(`UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *userRepository) UpdateProfile(userID uuid.UUID, username, fullName string) error {
+	query := `UPDATE users SET username = $2, full_name = $3, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(query, userID, username, fullName)
+	return err
+}
+
+// UpdateEmail mengganti email yang sudah dikonfirmasi lewat link ke alamat baru,
+// sehingga email baru langsung dianggap terverifikasi.
+func (r *userRepository) UpdateEmail(userID uuid.UUID, email string) error {
+	query := `UPDATE users SET email = $2, email_verified_at = NOW(), updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(query, userID, email)
+	return err
+}
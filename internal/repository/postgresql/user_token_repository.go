@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	entity "home-market/internal/domain"
+	"github.com/google/uuid"
+)
+
+type UserTokenRepository interface {
+	Create(token *entity.UserToken) error
+	GetByHash(purpose string, tokenHash string) (*entity.UserToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+	RecordFailedAttempt(id uuid.UUID, maxAttempts int) error
+	InvalidateByUser(userID uuid.UUID, purpose string) error
+	GetLatestCreatedAt(userID uuid.UUID, purpose string) (*time.Time, error)
+}
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(token *entity.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (id, user_id, purpose, token_hash, payload, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+	_, err := r.db.Exec(query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.Payload, token.ExpiresAt)
+	return err
+}
+
+func (r *userTokenRepository) GetByHash(purpose string, tokenHash string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	query := `
+		SELECT id, user_id, purpose, token_hash, payload, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE purpose = $1 AND token_hash = $2
+	`
+	err := r.db.QueryRow(query, purpose, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash,
+		&token.Payload, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed menandai token terpakai. Mengembalikan false jika token sudah dipakai sebelumnya,
+// sehingga dua request bersamaan tidak bisa memakai token yang sama.
+func (r *userTokenRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`UPDATE user_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RecordFailedAttempt menambah hitungan percobaan gagal dan menandai token terpakai
+// saat hitungan mencapai maxAttempts. Dilakukan dalam satu UPDATE agar percobaan
+// bersamaan tetap terhitung semua.
+func (r *userTokenRepository) RecordFailedAttempt(id uuid.UUID, maxAttempts int) error {
+	_, err := r.db.Exec(`
+		UPDATE user_tokens
+		SET attempts = attempts + 1,
+			used_at = CASE WHEN attempts + 1 >= $2 THEN NOW() END
+		WHERE id = $1 AND used_at IS NULL
+	`, id, maxAttempts)
+	return err
+}
+
+func (r *userTokenRepository) InvalidateByUser(userID uuid.UUID, purpose string) error {
+	query := `UPDATE user_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	_, err := r.db.Exec(query, userID, purpose)
+	return err
+}
+
+// GetLatestCreatedAt dipakai untuk throttling pengiriman ulang token.
+func (r *userTokenRepository) GetLatestCreatedAt(userID uuid.UUID, purpose string) (*time.Time, error) {
+	var latest *time.Time
+	query := `SELECT MAX(created_at) FROM user_tokens WHERE user_id = $1 AND purpose = $2`
+	if err := r.db.QueryRow(query, userID, purpose).Scan(&latest); err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
@@ -7,17 +7,21 @@ import (
 	repo "home-market/internal/repository/postgresql"
 )
 
+var ErrItemNotModerated = errors.New("item is not moderated")
+
 type AdminService struct {
-	userRepo repo.UserRepository
-	itemRepo repo.ItemRepository
+	userRepo     repo.UserRepository
+	itemRepo     repo.ItemRepository
+	sessionGuard *SessionGuard
+	loginLimiter *LoginLimiter
 }
 
-func NewAdminService(userRepo repo.UserRepository, itemRepo repo.ItemRepository) *AdminService {
-	return &AdminService{userRepo: userRepo, itemRepo: itemRepo}
+func NewAdminService(userRepo repo.UserRepository, itemRepo repo.ItemRepository, sessionGuard *SessionGuard, loginLimiter *LoginLimiter) *AdminService {
+	return &AdminService{userRepo: userRepo, itemRepo: itemRepo, sessionGuard: sessionGuard, loginLimiter: loginLimiter}
 }
 
 // @Summary      Get List of All Users
-// @Description  Retrieves a list of all users in the system (Admin access only).
+// @Description  Retrieves a list of all users in the system (requires user:read).
 // @Tags         Admin
 // @Accept       json
 // @Produce      json
@@ -26,15 +30,12 @@ func NewAdminService(userRepo repo.UserRepository, itemRepo repo.ItemRepository)
 // @Failure      403  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /admin/users [get]
-func (s *AdminService) ListUsers(role string) ([]entity.User, error) {
-	if role != "admin" {
-		return nil, errors.New("unauthorized: admin access required")
-	}
+func (s *AdminService) ListUsers() ([]entity.User, error) {
 	return s.userRepo.ListAllUsers()
 }
 
 // @Summary      Block or Unblock User Account
-// @Description  Sets the 'is_active' status of a target user (Admin access only).
+// @Description  Sets the 'is_active' status of a target user (requires user:manage).
 // @Tags         Admin
 // @Accept       json
 // @Produce      json
@@ -46,15 +47,38 @@ func (s *AdminService) ListUsers(role string) ([]entity.User, error) {
 // @Failure      403  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /admin/users/{id}/status [patch]
-func (s *AdminService) BlockUser(adminRole string, targetUserID uuid.UUID, isActive bool) error {
-	if adminRole != "admin" {
-		return errors.New("unauthorized: admin access required")
+func (s *AdminService) BlockUser(targetUserID uuid.UUID, isActive bool) error {
+	if err := s.userRepo.UpdateUserStatus(targetUserID, isActive); err != nil {
+		return err
+	}
+	s.sessionGuard.Invalidate(targetUserID)
+	return nil
+}
+
+// @Summary      Unlock User Login
+// @Description  Clears the temporary login and 2FA lockout of a user after too many failed attempts (requires user:manage).
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Target User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/users/{id}/unlock [post]
+func (s *AdminService) UnlockUser(adminID uuid.UUID, targetUserID uuid.UUID, client entity.ClientInfo) error {
+	user, err := s.userRepo.GetByID(targetUserID)
+	if err != nil {
+		return err
 	}
-	return s.userRepo.UpdateUserStatus(targetUserID, isActive)
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	s.loginLimiter.Unlock(user.Username, user.ID.String(), adminID.String(), client)
+	return nil
 }
 
-// @Summary      Moderate Item (Set Inactive)
-// @Description  Sets the status of a specific item to 'inactive' due to policy violation (Admin access only).
+// @Summary      Moderate Item
+// @Description  Sets the status of a specific item to 'moderated' due to policy violation (requires item:moderate). Sellers cannot change the status of a moderated item.
 // @Tags         Admin
 // @Accept       json
 // @Produce      json
@@ -66,19 +90,44 @@ func (s *AdminService) BlockUser(adminRole string, targetUserID uuid.UUID, isAct
 // @Failure      404  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /admin/items/{id}/moderate [patch]
-func (s *AdminService) ModerateItem(adminRole string, itemID uuid.UUID) error {
-	if adminRole != "admin" {
-		return errors.New("unauthorized: admin access required")
+func (s *AdminService) ModerateItem(itemID uuid.UUID) error {
+	item, err := s.itemRepo.GetItemByID(itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return errors.New("item not found")
 	}
 
+	item.Status = entity.ItemStatusModerated
+	return s.itemRepo.UpdateItem(item)
+}
+
+// @Summary      Restore Moderated Item
+// @Description  Lifts moderation from an item (requires item:moderate). The item becomes 'inactive' so the seller decides when to list it again. Items moderated before the 'moderated' status existed were stored as plain 'inactive', indistinguishable from items archived by their seller, and are left as they are.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Item ID to restore"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Item is not moderated"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/items/{id}/unmoderate [patch]
+func (s *AdminService) UnmoderateItem(itemID uuid.UUID) error {
 	item, err := s.itemRepo.GetItemByID(itemID)
 	if err != nil {
 		return err
 	}
 	if item == nil {
-		return errors.New("item not found")
+		return ErrItemNotFound
+	}
+	if item.Status != entity.ItemStatusModerated {
+		return ErrItemNotModerated
 	}
 
-	item.Status = "inactive"
+	item.Status = entity.ItemStatusInactive
 	return s.itemRepo.UpdateItem(item)
 }
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAPIKey      = errors.New("invalid or revoked api key")
+	ErrInvalidAPIKeyScope = errors.New("unknown api key scope")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyLimitTooHigh = errors.New("rate_limit_per_hour exceeds the allowed maximum")
+)
+
+// APIKeyQuotaError dikembalikan saat kuota per jam sebuah key habis.
+type APIKeyQuotaError struct {
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *APIKeyQuotaError) Error() string {
+	return "api key hourly quota exceeded"
+}
+
+func (e *APIKeyQuotaError) QuotaLimit() int         { return e.Limit }
+func (e *APIKeyQuotaError) QuotaResetAt() time.Time { return e.ResetAt }
+
+const (
+	apiKeyPrefix             = "hm_"
+	defaultAPIKeyHourlyLimit = 1000
+	maxAPIKeyHourlyLimit     = 10000
+	// last_used_at cukup diperbarui sekali per menit, bukan setiap request
+	apiKeyTouchInterval = time.Minute
+)
+
+var validAPIScopes = map[string]bool{
+	entity.APIScopeItemsRead:  true,
+	entity.APIScopeItemsWrite: true,
+}
+
+type apiKeyUsage struct {
+	windowStart time.Time
+	count       int
+	lastTouch   time.Time
+}
+
+type APIKeyService struct {
+	apiKeyRepo repo.APIKeyRepository
+	shopRepo   repo.ShopRepository
+	memberRepo repo.ShopMemberRepository
+	userRepo   repo.UserRepository
+
+	// kuota per jam (fixed window) disimpan in-process: hitungan hilang saat restart dan
+	// tidak dibagi antar instance, jadi dengan N instance batas efektifnya hingga N kali
+	// rate_limit_per_hour. Cukup untuk deployment satu instance; untuk lebih dari itu
+	// hitungan perlu dipindah ke penyimpanan bersama (mis. Redis).
+	mu    sync.Mutex
+	usage map[uuid.UUID]*apiKeyUsage
+}
+
+func NewAPIKeyService(apiKeyRepo repo.APIKeyRepository, shopRepo repo.ShopRepository, memberRepo repo.ShopMemberRepository, userRepo repo.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		shopRepo:   shopRepo,
+		memberRepo: memberRepo,
+		userRepo:   userRepo,
+		usage:      make(map[uuid.UUID]*apiKeyUsage),
+	}
+}
+
+// @Summary      Create Shop API Key
+// @Description  Creates an API key for the seller's shop. The raw key is returned only once; send it in the X-API-Key header. The hourly quota is counted per server instance and resets when the server restarts.
+// @Tags         Shop/API Keys
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.CreateAPIKeyInput true "Key name, scopes (items:read, items:write) and hourly quota"
+// @Success      201  {object}  entity.APIKeyCreatedResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /shops/me/api-keys [post]
+func (s *APIKeyService) Create(userID uuid.UUID, input entity.CreateAPIKeyInput) (*entity.APIKeyCreatedResponse, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !validAPIScopes[scope] {
+			return nil, ErrInvalidAPIKeyScope
+		}
+		scopes = append(scopes, scope)
+	}
+
+	limit := input.RateLimitPerHour
+	if limit == 0 {
+		limit = defaultAPIKeyHourlyLimit
+	}
+	if limit > maxAPIKeyHourlyLimit {
+		return nil, ErrAPIKeyLimitTooHigh
+	}
+
+	secret, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	raw := apiKeyPrefix + secret
+
+	key := &entity.ShopAPIKey{
+		ID:               uuid.New(),
+		ShopID:           shop.ID,
+		CreatedBy:        userID,
+		Name:             input.Name,
+		Prefix:           raw[:len(apiKeyPrefix)+8],
+		KeyHash:          utils.HashToken(raw),
+		Scopes:           scopes,
+		RateLimitPerHour: limit,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	return &entity.APIKeyCreatedResponse{Key: raw, APIKey: key}, nil
+}
+
+// @Summary      List Shop API Keys
+// @Description  Lists the API keys of the seller's shop, including revoked ones and their last-used time.
+// @Tags         Shop/API Keys
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.ShopAPIKey
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /shops/me/api-keys [get]
+func (s *APIKeyService) List(userID uuid.UUID) ([]entity.ShopAPIKey, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyRepo.ListByShop(shop.ID)
+}
+
+// @Summary      Revoke Shop API Key
+// @Description  Revokes an API key of the seller's shop. Requests using it are rejected immediately.
+// @Tags         Shop/API Keys
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /shops/me/api-keys/{id} [delete]
+func (s *APIKeyService) Revoke(userID uuid.UUID, keyID uuid.UUID) error {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageAPIKeys)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.apiKeyRepo.Revoke(keyID, shop.ID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+
+	s.mu.Lock()
+	delete(s.usage, keyID)
+	s.mu.Unlock()
+	return nil
+}
+
+// AuthenticateAPIKey memvalidasi key mentah dari header X-API-Key dan kuotanya, lalu
+// mengembalikan principal yang bertindak atas nama pemilik toko. Scope diperiksa middleware.
+func (s *APIKeyService) AuthenticateAPIKey(raw string) (*entity.APIKeyPrincipal, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(utils.HashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	ownerID, err := s.shopRepo.GetShopOwnerID(key.ShopID)
+	if err != nil {
+		return nil, err
+	}
+	// key ikut mati jika pemilik toko diblokir
+	_, isActive, err := s.userRepo.GetTokenState(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive {
+		return nil, ErrInactiveAccount
+	}
+
+	remaining, resetAt, touch, err := s.consume(key)
+	if err != nil {
+		return nil, err
+	}
+	if touch {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID); err != nil {
+			log.Printf("Warning: failed to update last_used_at for api key %s: %v", key.ID.String(), err)
+		}
+	}
+
+	return &entity.APIKeyPrincipal{
+		KeyID:     key.ID,
+		ShopID:    key.ShopID,
+		UserID:    ownerID,
+		Scopes:    key.Scopes,
+		Limit:     key.RateLimitPerHour,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// consume menghitung satu request pada window jam berjalan.
+func (s *APIKeyService) consume(key *entity.ShopAPIKey) (int, time.Time, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.usage[key.ID]
+	if !ok || now.Sub(u.windowStart) >= time.Hour {
+		lastTouch := time.Time{}
+		if ok {
+			lastTouch = u.lastTouch
+		}
+		u = &apiKeyUsage{windowStart: now, lastTouch: lastTouch}
+		s.usage[key.ID] = u
+	}
+
+	resetAt := u.windowStart.Add(time.Hour)
+	if u.count >= key.RateLimitPerHour {
+		return 0, resetAt, false, &APIKeyQuotaError{Limit: key.RateLimitPerHour, ResetAt: resetAt}
+	}
+	u.count++
+
+	touch := now.Sub(u.lastTouch) >= apiKeyTouchInterval
+	if touch {
+		u.lastTouch = now
+	}
+	return key.RateLimitPerHour - u.count, resetAt, touch, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+// startSession membuat user aktif lalu membuka sesi baru tanpa melewati password/2FA.
+func (f *authFixture) startSession(t *testing.T, mfaVerified bool) (*entity.User, *entity.LoginResponse) {
+	t.Helper()
+	user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "buyer")
+	resp, err := f.auth.startSession(user, "buyer", mfaVerified, entity.ClientInfo{})
+	if err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+	return user, resp
+}
+
+// storedRefresh mengambil baris refresh token dari token mentahnya.
+func (f *authFixture) storedRefresh(t *testing.T, raw string) *entity.RefreshToken {
+	t.Helper()
+	token, _ := f.refresh.GetByHash(utils.HashToken(raw))
+	if token == nil {
+		t.Fatalf("refresh token %q not stored", raw)
+	}
+	return token
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare mengembalikan refresh token yang dikirim ke Refresh
+		prepare func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string
+		wantErr error
+		// familyRevoked: seluruh sesi harus dicabut setelah Refresh
+		familyRevoked bool
+	}{
+		{
+			name: "rotates the refresh token",
+			prepare: func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string {
+				return login.RefreshToken
+			},
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string {
+				return "not-a-refresh-token"
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string {
+				stored := f.storedRefresh(t, login.RefreshToken)
+				f.refresh.tokens[stored.ID].ExpiresAt = time.Now().Add(-time.Minute)
+				return login.RefreshToken
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "reusing a rotated token revokes the session",
+			prepare: func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string {
+				if _, err := f.auth.Refresh(login.RefreshToken, entity.ClientInfo{}); err != nil {
+					t.Fatalf("first refresh: %v", err)
+				}
+				return login.RefreshToken
+			},
+			wantErr:       ErrRefreshTokenReused,
+			familyRevoked: true,
+		},
+		{
+			name: "inactive user revokes the session",
+			prepare: func(t *testing.T, f *authFixture, user *entity.User, login *entity.LoginResponse) string {
+				f.users.UpdateUserStatus(user.ID, false)
+				return login.RefreshToken
+			},
+			wantErr:       ErrInactiveAccount,
+			familyRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user, login := f.startSession(t, false)
+			familyID := f.storedRefresh(t, login.RefreshToken).FamilyID
+
+			resp, err := f.auth.Refresh(tt.prepare(t, f, user, login), entity.ClientInfo{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Refresh: %v", err)
+				}
+				if resp.RefreshToken == login.RefreshToken {
+					t.Fatal("refresh token was not rotated")
+				}
+				next := f.storedRefresh(t, resp.RefreshToken)
+				prev := f.storedRefresh(t, login.RefreshToken)
+				if next.FamilyID != familyID || prev.RevokedAt == nil || prev.ReplacedBy == nil || *prev.ReplacedBy != next.ID {
+					t.Fatalf("rotation not recorded: prev=%+v next=%+v", prev, next)
+				}
+				claims, err := utils.ValidateToken(resp.Token)
+				if err != nil {
+					t.Fatalf("access token: %v", err)
+				}
+				if claims.SessionID != familyID {
+					t.Fatalf("sid = %s, want %s", claims.SessionID, familyID)
+				}
+			}
+
+			sessions, _ := f.sessions.ListActiveByUser(user.ID)
+			if revoked := len(sessions) == 0; revoked != tt.familyRevoked {
+				t.Fatalf("session revoked = %v, want %v", revoked, tt.familyRevoked)
+			}
+			if tt.familyRevoked {
+				for _, token := range f.refresh.tokens {
+					if token.FamilyID == familyID && token.RevokedAt == nil {
+						t.Fatalf("token %s of the family is still active", token.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	f := newAuthFixture(t)
+	user, login := f.startSession(t, false)
+
+	if err := f.auth.Logout(login.RefreshToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if sessions, _ := f.sessions.ListActiveByUser(user.ID); len(sessions) != 0 {
+		t.Fatalf("active sessions after logout: %+v", sessions)
+	}
+	if _, err := f.auth.Refresh(login.RefreshToken, entity.ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("refresh after logout: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if err := f.auth.Logout("not-a-refresh-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	legacy, err := utils.NewPasswordHasher(config.PasswordConfig{Algorithm: config.PasswordAlgorithmBcrypt, BcryptCost: 4}).Hash("rahasia-kuat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := utils.HashPassword("rahasia-kuat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		stored     string
+		password   string
+		wantErr    error
+		wantRehash bool
+	}{
+		{name: "legacy bcrypt hash is upgraded", stored: legacy, password: "rahasia-kuat-1", wantRehash: true},
+		{name: "current argon2id hash is kept", stored: current, password: "rahasia-kuat-1"},
+		{name: "wrong password keeps the legacy hash", stored: legacy, password: "salah", wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", PasswordHash: tt.stored, IsActive: true}, "buyer")
+
+			_, _, err := f.auth.Login("ana", tt.password, entity.ClientInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			after := f.users.get(user.ID).PasswordHash
+			if rehashed := after != tt.stored; rehashed != tt.wantRehash {
+				t.Fatalf("rehashed = %v, want %v (hash %q)", rehashed, tt.wantRehash, after)
+			}
+			if tt.wantRehash {
+				if !strings.HasPrefix(after, "$argon2id$") || !utils.CheckPasswordHash(tt.password, after) {
+					t.Fatalf("new hash %q does not verify as argon2id", after)
+				}
+			}
+		})
+	}
+}
+
+func TestForgotPasswordThrottle(t *testing.T) {
+	tests := []struct {
+		name string
+		// lastSent: umur link reset terakhir; nol berarti belum pernah dikirim
+		lastSent time.Duration
+		email    string
+		wantSent int
+	}{
+		{name: "first request", email: "ana@example.com", wantSent: 1},
+		{name: "within the window", lastSent: 10 * time.Second, email: "ana@example.com", wantSent: 0},
+		{name: "after the window", lastSent: 2 * passwordResetResendWindow, email: "ana@example.com", wantSent: 1},
+		{name: "unknown email", email: "budi@example.com", wantSent: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "buyer")
+			if tt.lastSent > 0 {
+				f.tokens.Create(&entity.UserToken{
+					ID: uuid.New(), UserID: user.ID, Purpose: entity.TokenPurposePasswordReset, TokenHash: "old",
+					ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-tt.lastSent),
+				})
+			}
+
+			// throttle tidak boleh terlihat dari respons agar email terdaftar tidak bisa ditebak
+			if err := f.auth.ForgotPassword(tt.email); err != nil {
+				t.Fatalf("ForgotPassword: %v", err)
+			}
+			if len(f.mailer.sent) != tt.wantSent {
+				t.Fatalf("sent %d emails, want %d", len(f.mailer.sent), tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestUpdateProfileValidatesTrimmedFields(t *testing.T) {
+	str := func(s string) *string { return &s }
+	dbErr := errors.New("connection reset")
+
+	tests := []struct {
+		name        string
+		input       entity.UpdateProfileInput
+		usernameErr error
+		wantErr     error
+		wantName    string
+	}{
+		{name: "blank username", input: entity.UpdateProfileInput{Username: str("   ")}, wantErr: ErrInvalidUsername, wantName: "ana"},
+		{name: "username too short after trim", input: entity.UpdateProfileInput{Username: str("  ab  ")}, wantErr: ErrInvalidUsername, wantName: "ana"},
+		{name: "blank full name", input: entity.UpdateProfileInput{FullName: str("   ")}, wantErr: ErrInvalidFullName, wantName: "ana"},
+		{name: "trimmed username", input: entity.UpdateProfileInput{Username: str("  anabel ")}, wantName: "anabel"},
+		{name: "username taken", input: entity.UpdateProfileInput{Username: str("budi")}, wantErr: ErrUsernameTaken, wantName: "ana"},
+		{name: "lookup error", input: entity.UpdateProfileInput{Username: str("anabel")}, usernameErr: dbErr, wantErr: dbErr, wantName: "ana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user := f.users.add(&entity.User{Username: "ana", FullName: "Ana", IsActive: true}, "buyer")
+			f.users.add(&entity.User{Username: "budi", IsActive: true}, "buyer")
+			f.users.usernameErr = tt.usernameErr
+
+			_, err := f.auth.UpdateProfile(user.ID, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProfile error = %v, want %v", err, tt.wantErr)
+			}
+			stored := f.users.get(user.ID)
+			if stored.Username != tt.wantName || stored.FullName != "Ana" {
+				t.Errorf("stored username %q, full name %q; want %q, %q", stored.Username, stored.FullName, tt.wantName, "Ana")
+			}
+		})
+	}
+}
@@ -0,0 +1,1129 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/internal/mail"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+// Repository in-memory untuk test service. Hanya perilaku yang dipakai service yang ditiru;
+// query kompleks (filter, join) tetap diuji lewat database sungguhan.
+
+func TestMain(m *testing.M) {
+	// key set JWT dimuat sekali per proses (sync.Once), jadi secret harus ada sebelum test pertama
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+	// hasher password global juga sekali per proses; parameter argon2id diperkecil agar test cepat
+	os.Setenv("PASSWORD_ARGON2_MEMORY_KB", "1024")
+	os.Setenv("PASSWORD_ARGON2_ITERATIONS", "1")
+	os.Exit(m.Run())
+}
+
+type fakeUserRepo struct {
+	mu          sync.Mutex
+	users       map[uuid.UUID]*entity.User
+	roles       map[uuid.UUID][]string
+	roleNames   map[uuid.UUID]string
+	permissions map[string][]string
+	// usernameErr dikembalikan GetByUsername jika diisi (simulasi database bermasalah)
+	usernameErr error
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{
+		users:       make(map[uuid.UUID]*entity.User),
+		roles:       make(map[uuid.UUID][]string),
+		roleNames:   make(map[uuid.UUID]string),
+		permissions: make(map[string][]string),
+	}
+}
+
+// add menyimpan user dengan role utama roleName.
+func (r *fakeUserRepo) add(u *entity.User, roleName string) *entity.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	if u.RoleID == uuid.Nil {
+		u.RoleID = uuid.New()
+	}
+	cp := *u
+	r.users[u.ID] = &cp
+	r.roleNames[u.RoleID] = roleName
+	r.roles[u.ID] = []string{roleName}
+	return u
+}
+
+func (r *fakeUserRepo) get(id uuid.UUID) *entity.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok {
+		cp := *u
+		return &cp
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*entity.User, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.usernameErr != nil {
+		return nil, "", r.usernameErr
+	}
+	for _, u := range r.users {
+		if u.Username == username {
+			cp := *u
+			return &cp, r.roleNames[u.RoleID], nil
+		}
+	}
+	return nil, "", repo.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetPermissionsByRoleID(roleID uuid.UUID) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.permissions[r.roleNames[roleID]], nil
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*entity.User, error) {
+	if u := r.get(id); u != nil {
+		return u, nil
+	}
+	return nil, repo.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) CreateUser(user *entity.User) error {
+	r.mu.Lock()
+	roleName := r.roleNames[user.RoleID]
+	r.mu.Unlock()
+	if roleName == "" {
+		roleName = "buyer"
+	}
+	r.add(user, roleName)
+	return nil
+}
+
+func (r *fakeUserRepo) ListAllUsers() ([]entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := []entity.User{}
+	for _, u := range r.users {
+		users = append(users, *u)
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) update(id uuid.UUID, fn func(u *entity.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return repo.ErrUserNotFound
+	}
+	fn(u)
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateUserStatus(userID uuid.UUID, isActive bool) error {
+	return r.update(userID, func(u *entity.User) { u.IsActive = isActive; u.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) GetTokenState(userID uuid.UUID) (int, bool, error) {
+	u := r.get(userID)
+	if u == nil {
+		return 0, false, repo.ErrUserNotFound
+	}
+	return u.TokenVersion, u.IsActive, nil
+}
+
+func (r *fakeUserRepo) IncrementTokenVersion(userID uuid.UUID) error {
+	return r.update(userID, func(u *entity.User) { u.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) UpdatePassword(userID uuid.UUID, passwordHash string) error {
+	return r.update(userID, func(u *entity.User) { u.PasswordHash = passwordHash; u.TokenVersion++ })
+}
+
+func (r *fakeUserRepo) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	return r.update(userID, func(u *entity.User) { u.PasswordHash = passwordHash })
+}
+
+func (r *fakeUserRepo) MarkEmailVerified(userID uuid.UUID) error {
+	now := time.Now()
+	return r.update(userID, func(u *entity.User) { u.EmailVerifiedAt = &now })
+}
+
+func (r *fakeUserRepo) GetRoleNamesByUserID(userID uuid.UUID) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.roles[userID]...), nil
+}
+
+func (r *fakeUserRepo) GetPermissionsByUserID(userID uuid.UUID) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	perms := []string{}
+	for _, role := range r.roles[userID] {
+		perms = append(perms, r.permissions[role]...)
+	}
+	return perms, nil
+}
+
+func (r *fakeUserRepo) AddUserRole(userID, roleID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[userID] = append(r.roles[userID], r.roleNames[roleID])
+	return nil
+}
+
+func (r *fakeUserRepo) RemoveUserRole(userID, roleID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := r.roleNames[roleID]
+	for i, role := range r.roles[userID] {
+		if role == name {
+			r.roles[userID] = append(r.roles[userID][:i], r.roles[userID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeUserRepo) UpdateProfile(userID uuid.UUID, username, fullName string) error {
+	return r.update(userID, func(u *entity.User) { u.Username = username; u.FullName = fullName })
+}
+
+func (r *fakeUserRepo) UpdateEmail(userID uuid.UUID, email string) error {
+	return r.update(userID, func(u *entity.User) { u.Email = email; u.EmailVerifiedAt = nil })
+}
+
+type fakeIdentityRepo struct {
+	identities []entity.UserIdentity
+}
+
+func (r *fakeIdentityRepo) GetByProviderSubject(provider, subject string) (*entity.UserIdentity, error) {
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			cp := i
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeIdentityRepo) Create(identity *entity.UserIdentity) error {
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+type fakeMFARepo struct {
+	mu       sync.Mutex
+	mfa      map[uuid.UUID]*entity.UserMFA
+	lastStep map[uuid.UUID]int64
+	recovery map[uuid.UUID]map[string]bool // hash -> sudah dipakai
+}
+
+func newFakeMFARepo() *fakeMFARepo {
+	return &fakeMFARepo{
+		mfa:      make(map[uuid.UUID]*entity.UserMFA),
+		lastStep: make(map[uuid.UUID]int64),
+		recovery: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (r *fakeMFARepo) GetByUserID(userID uuid.UUID) (*entity.UserMFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.mfa[userID]; ok {
+		cp := *m
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeMFARepo) SavePending(userID uuid.UUID, secretEncrypted string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mfa[userID] = &entity.UserMFA{UserID: userID, SecretEncrypted: secretEncrypted, CreatedAt: time.Now()}
+	delete(r.lastStep, userID)
+	return nil
+}
+
+func (r *fakeMFARepo) Enable(userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.mfa[userID].EnabledAt = &now
+	return nil
+}
+
+func (r *fakeMFARepo) Delete(userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mfa, userID)
+	delete(r.recovery, userID)
+	return nil
+}
+
+func (r *fakeMFARepo) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recovery[userID] = make(map[string]bool)
+	for _, h := range codeHashes {
+		r.recovery[userID][h] = false
+	}
+	return nil
+}
+
+func (r *fakeMFARepo) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.recovery[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recovery[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeMFARepo) UseTimeStep(userID uuid.UUID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.lastStep[userID]; ok && last >= step {
+		return false, nil
+	}
+	r.lastStep[userID] = step
+	return true, nil
+}
+
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*entity.UserSession
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: make(map[uuid.UUID]*entity.UserSession)}
+}
+
+func (r *fakeSessionRepo) Create(session *entity.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *session
+	r.sessions[session.ID] = &cp
+	return nil
+}
+
+func (r *fakeSessionRepo) ListActiveByUser(userID uuid.UUID) ([]entity.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.UserSession{}
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			list = append(list, *s)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeSessionRepo) ListByUser(userID uuid.UUID) ([]entity.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.UserSession{}
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			list = append(list, *s)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeSessionRepo) Touch(id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	return ok && s.RevokedAt == nil, nil
+}
+
+func (r *fakeSessionRepo) Extend(id uuid.UUID, client entity.ClientInfo, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok && s.RevokedAt == nil {
+		s.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) revoke(match func(s *entity.UserSession) bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	revoked := false
+	for _, s := range r.sessions {
+		if s.RevokedAt == nil && match(s) {
+			s.RevokedAt = &now
+			revoked = true
+		}
+	}
+	return revoked
+}
+
+func (r *fakeSessionRepo) Revoke(id, userID uuid.UUID) (bool, error) {
+	return r.revoke(func(s *entity.UserSession) bool { return s.ID == id && s.UserID == userID }), nil
+}
+
+func (r *fakeSessionRepo) RevokeByID(id uuid.UUID) error {
+	r.revoke(func(s *entity.UserSession) bool { return s.ID == id })
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeAllByUser(userID uuid.UUID) error {
+	r.revoke(func(s *entity.UserSession) bool { return s.UserID == userID })
+	return nil
+}
+
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*entity.RefreshToken
+}
+
+func newFakeRefreshTokenRepo() *fakeRefreshTokenRepo {
+	return &fakeRefreshTokenRepo{tokens: make(map[uuid.UUID]*entity.RefreshToken)}
+}
+
+func (r *fakeRefreshTokenRepo) Create(token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *token
+	r.tokens[token.ID] = &cp
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetByHash(tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRefreshTokenRepo) Rotate(oldID uuid.UUID, newToken *entity.RefreshToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedBy = &newToken.ID
+	cp := *newToken
+	r.tokens[newToken.ID] = &cp
+	return true, nil
+}
+
+func (r *fakeRefreshTokenRepo) revoke(match func(t *entity.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &now
+		}
+	}
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(familyID uuid.UUID) error {
+	r.revoke(func(t *entity.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeAllByUser(userID uuid.UUID) error {
+	r.revoke(func(t *entity.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+type fakeUserTokenRepo struct {
+	mu       sync.Mutex
+	tokens   map[uuid.UUID]*entity.UserToken
+	attempts map[uuid.UUID]int
+}
+
+func newFakeUserTokenRepo() *fakeUserTokenRepo {
+	return &fakeUserTokenRepo{tokens: make(map[uuid.UUID]*entity.UserToken), attempts: make(map[uuid.UUID]int)}
+}
+
+func (r *fakeUserTokenRepo) Create(token *entity.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *token
+	r.tokens[token.ID] = &cp
+	return nil
+}
+
+func (r *fakeUserTokenRepo) GetByHash(purpose string, tokenHash string) (*entity.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserTokenRepo) MarkUsed(id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[id]
+	if !ok || t.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return true, nil
+}
+
+func (r *fakeUserTokenRepo) RecordFailedAttempt(id uuid.UUID, maxAttempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[id]
+	if !ok || t.UsedAt != nil {
+		return nil
+	}
+	r.attempts[id]++
+	if r.attempts[id] >= maxAttempts {
+		now := time.Now()
+		t.UsedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeUserTokenRepo) InvalidateByUser(userID uuid.UUID, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserTokenRepo) GetLatestCreatedAt(userID uuid.UUID, purpose string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *time.Time
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && (latest == nil || t.CreatedAt.After(*latest)) {
+			created := t.CreatedAt
+			latest = &created
+		}
+	}
+	return latest, nil
+}
+
+type fakeLogRepo struct {
+	mu            sync.Mutex
+	history       []entity.HistoryStatus
+	notifications []entity.Notification
+	activities    []entity.ActivityLog
+}
+
+func (r *fakeLogRepo) SaveHistoryStatus(doc *entity.HistoryStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = append(r.history, *doc)
+	return nil
+}
+
+func (r *fakeLogRepo) SaveNotification(doc *entity.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, *doc)
+	return nil
+}
+
+func (r *fakeLogRepo) SaveActivityLog(doc *entity.ActivityLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.activities = append(r.activities, *doc)
+	return nil
+}
+
+func (r *fakeLogRepo) ListNotificationsByUser(userID uuid.UUID) ([]entity.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.Notification{}
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			list = append(list, n)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeLogRepo) DeleteNotificationsByUser(userID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.notifications[:0]
+	for _, n := range r.notifications {
+		if n.UserID != userID {
+			kept = append(kept, n)
+		}
+	}
+	deleted := int64(len(r.notifications) - len(kept))
+	r.notifications = kept
+	return deleted, nil
+}
+
+func (r *fakeLogRepo) ListActivityLogsByUser(userID string) ([]entity.ActivityLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.ActivityLog{}
+	for _, a := range r.activities {
+		if a.UserID == userID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeLogRepo) DeleteActivityLogsByUser(userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.activities[:0]
+	for _, a := range r.activities {
+		if a.UserID != userID {
+			kept = append(kept, a)
+		}
+	}
+	deleted := int64(len(r.activities) - len(kept))
+	r.activities = kept
+	return deleted, nil
+}
+
+func (r *fakeLogRepo) ListHistoryStatus(relatedIDs []string, changedBy string) ([]entity.HistoryStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[string]bool, len(relatedIDs))
+	for _, id := range relatedIDs {
+		wanted[id] = true
+	}
+	list := []entity.HistoryStatus{}
+	for _, h := range r.history {
+		if wanted[h.RelatedID] || h.ChangedBy == changedBy {
+			list = append(list, h)
+		}
+	}
+	return list, nil
+}
+
+// actions mengembalikan nama action activity log yang tersimpan, berurutan.
+func (r *fakeLogRepo) actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actions := make([]string, 0, len(r.activities))
+	for _, a := range r.activities {
+		actions = append(actions, a.Action)
+	}
+	return actions
+}
+
+// fakeMailer menyimpan email yang dikirim alih-alih mengirimkannya.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// authFixture merangkai AuthService dengan repository in-memory.
+type authFixture struct {
+	users    *fakeUserRepo
+	refresh  *fakeRefreshTokenRepo
+	sessions *fakeSessionRepo
+	tokens   *fakeUserTokenRepo
+	mfa      *fakeMFARepo
+	logs     *fakeLogRepo
+	mailer   *fakeMailer
+
+	mfaService *MFAService
+	auth       *AuthService
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	f := &authFixture{
+		users:    newFakeUserRepo(),
+		refresh:  newFakeRefreshTokenRepo(),
+		sessions: newFakeSessionRepo(),
+		tokens:   newFakeUserTokenRepo(),
+		mfa:      newFakeMFARepo(),
+		logs:     &fakeLogRepo{},
+		mailer:   &fakeMailer{},
+	}
+
+	mfaCfg, err := config.LoadMFA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPasswordPolicy(config.PasswordConfig{
+		Algorithm: config.PasswordAlgorithmBcrypt, BcryptCost: 4, MinLength: 8, MaxLength: 72,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.mfaService = NewMFAService(f.mfa, f.users, mfaCfg)
+	f.auth = NewAuthService(
+		f.users, f.refresh, f.sessions, f.tokens,
+		f.mailer,
+		NewSessionGuard(f.users, f.sessions),
+		f.mfaService,
+		NewLoginLimiter(NewMemoryLoginAttemptStore(), config.LoadLoginProtection(), f.logs),
+		policy,
+		uuid.New(),
+	)
+	return f
+}
+
+// Repository toko di bawah menanamkan interface aslinya: method yang tidak ditiru
+// akan panic jika terpanggil, sehingga test langsung menunjukkan dependensi baru.
+
+type fakeShopRepo struct {
+	repo.ShopRepository
+	mu    sync.Mutex
+	shops map[uuid.UUID]*entity.Shop
+	// categoryShop memetakan kategori ke tokonya untuk IsCategoryOwnedByShop
+	categoryShop map[uuid.UUID]uuid.UUID
+}
+
+func (r *fakeShopRepo) GetByID(shopID uuid.UUID) (*entity.Shop, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.shops[shopID]; ok {
+		cp := *s
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeShopRepo) GetByUserID(userID uuid.UUID) (*entity.Shop, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.shops {
+		if s.UserID == userID {
+			cp := *s
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeShopRepo) CreateShop(shop *entity.Shop) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *shop
+	r.shops[shop.ID] = &cp
+	return nil
+}
+
+func (r *fakeShopRepo) UpdateShop(shop *entity.Shop) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *shop
+	r.shops[shop.ID] = &cp
+	return nil
+}
+
+func (r *fakeShopRepo) GetShopOwnerID(shopID uuid.UUID) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.shops[shopID]; ok {
+		return s.UserID, nil
+	}
+	return uuid.Nil, nil
+}
+
+// GetAvailability: toko fixture selalu buka tanpa jadwal operasional.
+func (r *fakeShopRepo) GetAvailability(shopID uuid.UUID) (*entity.ShopAvailability, []entity.OperatingSlot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.shops[shopID]; !ok {
+		return nil, nil, nil
+	}
+	return &entity.ShopAvailability{}, nil, nil
+}
+
+func (r *fakeShopRepo) IsCategoryOwnedByShop(categoryID, shopID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	owner, ok := r.categoryShop[categoryID]
+	return ok && owner == shopID, nil
+}
+
+type fakeShopMemberRepo struct {
+	repo.ShopMemberRepository
+	mu      sync.Mutex
+	members map[uuid.UUID]*entity.ShopMember
+	// revoked: user yang permission RBAC global-nya sudah dicabut admin
+	revoked map[uuid.UUID]bool
+}
+
+func (r *fakeShopMemberRepo) GetByUserID(userID uuid.UUID) (*entity.ShopMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.members[userID]; ok {
+		cp := *m
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeShopMemberRepo) HasGlobalPermission(userID uuid.UUID, permission string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.revoked[userID], nil
+}
+
+type fakeCategoryRepo struct {
+	repo.CategoryRepository
+	mu         sync.Mutex
+	categories []entity.Category
+}
+
+func (r *fakeCategoryRepo) CreateCategory(c *entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = append(r.categories, *c)
+	return nil
+}
+
+func (r *fakeCategoryRepo) ExistsByName(shopID uuid.UUID, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.categories {
+		if c.ShopID == shopID && strings.EqualFold(c.Name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeCategoryRepo) ListByShop(shopID uuid.UUID) ([]entity.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.Category{}
+	for _, c := range r.categories {
+		if c.ShopID == shopID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeCategoryRepo) UpdateCategory(c *entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.categories {
+		if r.categories[i].ID == c.ID {
+			r.categories[i] = *c
+		}
+	}
+	return nil
+}
+
+type fakeItemRepo struct {
+	repo.ItemRepository
+	mu     sync.Mutex
+	items  map[uuid.UUID]*entity.Item
+	images map[uuid.UUID][]string
+}
+
+func (r *fakeItemRepo) GetItemByID(id uuid.UUID) (*entity.Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if it, ok := r.items[id]; ok {
+		cp := *it
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeItemRepo) ListByIDs(shopID uuid.UUID, ids []uuid.UUID) ([]entity.Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []entity.Item{}
+	for _, id := range ids {
+		if it, ok := r.items[id]; ok && it.ShopID == shopID {
+			list = append(list, *it)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeItemRepo) UpdateStatusBulk(shopID uuid.UUID, ids []uuid.UUID, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if it, ok := r.items[id]; ok && it.ShopID == shopID {
+			it.Status = status
+		}
+	}
+	return nil
+}
+
+func (r *fakeItemRepo) ListImageURLs(itemID uuid.UUID) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.images[itemID]...), nil
+}
+
+func (r *fakeItemRepo) PublishDraft(itemID uuid.UUID, images []entity.ItemImage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	it, ok := r.items[itemID]
+	if !ok || it.Status != entity.ItemStatusDraft {
+		return false, nil
+	}
+	it.Status = entity.ItemStatusActive
+	for _, img := range images {
+		r.images[itemID] = append(r.images[itemID], img.ImageURL)
+	}
+	return true, nil
+}
+
+type fakeOfferRepo struct {
+	repo.OfferRepository
+	offers map[uuid.UUID]*entity.Offer
+}
+
+func (r *fakeOfferRepo) GetOfferByID(id uuid.UUID) (*entity.Offer, error) {
+	if o, ok := r.offers[id]; ok {
+		cp := *o
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+// fakeOrderRepo membaca item dari fakeItemRepo dan mengisi badge ShopVerified dari
+// status verifikasi toko, seperti subquery EXISTS di query aslinya.
+type fakeOrderRepo struct {
+	repo.OrderRepository
+	shops  *fakeShopRepo
+	items  *fakeItemRepo
+	orders []entity.Order
+}
+
+func (r *fakeOrderRepo) GetItemForOrder(itemID uuid.UUID) (*entity.Item, error) {
+	item, err := r.items.GetItemByID(itemID)
+	if err != nil || item == nil {
+		return item, err
+	}
+	shop, err := r.shops.GetByID(item.ShopID)
+	if err != nil {
+		return nil, err
+	}
+	item.ShopVerified = shop != nil && shop.VerificationStatus == entity.ShopVerified
+	return item, nil
+}
+
+func (r *fakeOrderRepo) GetOrdersByBuyerID(buyerID uuid.UUID) ([]entity.Order, error) {
+	list := []entity.Order{}
+	for _, o := range r.orders {
+		if o.BuyerID == buyerID {
+			list = append(list, o)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeOrderRepo) GetOrderItems(orderID uuid.UUID) ([]entity.OrderItem, error) {
+	return []entity.OrderItem{}, nil
+}
+
+func (r *fakeOrderRepo) CreateOrderTransaction(order *entity.Order, orderItems []entity.OrderItem) error {
+	r.orders = append(r.orders, *order)
+	return nil
+}
+
+func (r *fakeOfferRepo) GetOffersByGiverID(giverID uuid.UUID) ([]entity.Offer, error) {
+	list := []entity.Offer{}
+	for _, o := range r.offers {
+		if o.GiverID == giverID {
+			list = append(list, *o)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeOfferRepo) GetOffersBySellerID(sellerID uuid.UUID) ([]entity.Offer, error) {
+	list := []entity.Offer{}
+	for _, o := range r.offers {
+		if o.SellerID == sellerID {
+			list = append(list, *o)
+		}
+	}
+	return list, nil
+}
+
+// fakePrivacyRepo hanya mencatat user yang dianonimkan; isi query anonimisasi
+// diuji lewat database sungguhan.
+type fakePrivacyRepo struct {
+	repo.PrivacyRepository
+	due        []entity.AccountDeletionRequest
+	anonymized []uuid.UUID
+}
+
+func (r *fakePrivacyRepo) ListDueDeletions(now time.Time) ([]entity.AccountDeletionRequest, error) {
+	return r.due, nil
+}
+
+func (r *fakePrivacyRepo) AnonymizeUser(requestID, userID uuid.UUID) ([]string, error) {
+	r.anonymized = append(r.anonymized, userID)
+	return nil, nil
+}
+
+// shopFixture merangkai ShopItemService untuk satu toko dengan owner dan satu staf packer.
+type shopFixture struct {
+	shop       *entity.Shop
+	ownerID    uuid.UUID
+	staffID    uuid.UUID
+	shops      *fakeShopRepo
+	members    *fakeShopMemberRepo
+	categories *fakeCategoryRepo
+	items      *fakeItemRepo
+	offers     *fakeOfferRepo
+	logs       *fakeLogRepo
+	service    *ShopItemService
+}
+
+func newShopFixture(t *testing.T, requireVerifiedShop bool) *shopFixture {
+	t.Helper()
+	f := &shopFixture{
+		shop:       &entity.Shop{ID: uuid.New(), UserID: uuid.New(), Name: "Toko Ana", VerificationStatus: entity.ShopVerified},
+		staffID:    uuid.New(),
+		shops:      &fakeShopRepo{shops: make(map[uuid.UUID]*entity.Shop), categoryShop: make(map[uuid.UUID]uuid.UUID)},
+		members:    &fakeShopMemberRepo{members: make(map[uuid.UUID]*entity.ShopMember), revoked: make(map[uuid.UUID]bool)},
+		categories: &fakeCategoryRepo{},
+		items:      &fakeItemRepo{items: make(map[uuid.UUID]*entity.Item), images: make(map[uuid.UUID][]string)},
+		offers:     &fakeOfferRepo{offers: make(map[uuid.UUID]*entity.Offer)},
+		logs:       &fakeLogRepo{},
+	}
+	f.ownerID = f.shop.UserID
+	f.shops.shops[f.shop.ID] = f.shop
+	f.members.members[f.ownerID] = &entity.ShopMember{ShopID: f.shop.ID, UserID: f.ownerID, Role: entity.ShopRoleOwner}
+	f.members.members[f.staffID] = &entity.ShopMember{ShopID: f.shop.ID, UserID: f.staffID, Role: entity.ShopRolePacker}
+
+	f.service = NewShopItemService(
+		f.shops, f.members, f.categories, nil, f.items, nil, f.offers, f.logs, requireVerifiedShop,
+	)
+	return f
+}
+
+// addCategory menyimpan kategori toko fixture di bawah parent (nil untuk tingkat atas).
+func (f *shopFixture) addCategory(name string, parent *entity.Category) *entity.Category {
+	c := entity.Category{ID: uuid.New(), ShopID: f.shop.ID, Name: name}
+	if parent != nil {
+		parentID := parent.ID
+		c.ParentID = &parentID
+	}
+	f.categories.CreateCategory(&c)
+	f.shops.categoryShop[c.ID] = f.shop.ID
+	return &c
+}
+
+// addItem menyimpan item milik toko fixture dengan status dan gambar tertentu.
+func (f *shopFixture) addItem(item entity.Item, images ...string) *entity.Item {
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	if item.ShopID == uuid.Nil {
+		item.ShopID = f.shop.ID
+	}
+	f.items.items[item.ID] = &item
+	f.items.images[item.ID] = images
+	return &item
+}
+
+// fakeRoleApplicationRepo meniru update bersyarat status = 'pending'. GetByID selalu
+// mengembalikan salinan saat pengajuan dibuat, seperti admin yang membaca sebelum
+// admin lain selesai mereview.
+type fakeRoleApplicationRepo struct {
+	repo.RoleApplicationRepository
+	mu       sync.Mutex
+	apps     map[uuid.UUID]*entity.RoleApplication
+	snapshot map[uuid.UUID]entity.RoleApplication
+	users    *fakeUserRepo
+}
+
+func (r *fakeRoleApplicationRepo) Create(app *entity.RoleApplication) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *app
+	r.apps[app.ID] = &cp
+	r.snapshot[app.ID] = *app
+	return nil
+}
+
+func (r *fakeRoleApplicationRepo) GetByID(id uuid.UUID) (*entity.RoleApplication, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if app, ok := r.snapshot[id]; ok {
+		return &app, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleApplicationRepo) UpdateReview(app *entity.RoleApplication) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.apps[app.ID]
+	if !ok || stored.Status != "pending" {
+		return false, nil
+	}
+	cp := *app
+	r.apps[app.ID] = &cp
+	return true, nil
+}
+
+func (r *fakeRoleApplicationRepo) Approve(app *entity.RoleApplication) (bool, error) {
+	ok, err := r.UpdateReview(app)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, r.users.AddUserRole(app.UserID, app.RoleID)
+}
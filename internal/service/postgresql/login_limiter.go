@@ -0,0 +1,248 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginThrottledError dikembalikan saat username atau IP sedang dalam masa
+// backoff/lockout. RetryAfter dipakai handler untuk header Retry-After.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return "too many failed login attempts, account temporarily locked"
+	}
+	return "too many failed login attempts, please wait before retrying"
+}
+
+// LoginAttempt adalah state gagal login untuk satu key (username atau IP).
+type LoginAttempt struct {
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil time.Time
+	Locked       bool
+}
+
+// LoginAttemptStore menyimpan state gagal login. Default-nya in-process;
+// deployment multi-instance bisa mengganti dengan store bersama.
+type LoginAttemptStore interface {
+	Get(key string) (LoginAttempt, bool)
+	Save(key string, attempt LoginAttempt, ttl time.Duration)
+	Delete(key string)
+}
+
+type memoryAttemptEntry struct {
+	attempt   LoginAttempt
+	expiresAt time.Time
+}
+
+type memoryLoginAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryAttemptEntry
+	writes  int
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{entries: make(map[string]memoryAttemptEntry)}
+}
+
+func (m *memoryLoginAttemptStore) Get(key string) (LoginAttempt, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return LoginAttempt{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(m.entries, key)
+		return LoginAttempt{}, false
+	}
+	return entry.attempt, true
+}
+
+func (m *memoryLoginAttemptStore) Save(key string, attempt LoginAttempt, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryAttemptEntry{attempt: attempt, expiresAt: time.Now().Add(ttl)}
+
+	// bersihkan entry kedaluwarsa sesekali agar map tidak tumbuh tanpa batas
+	m.writes++
+	if m.writes%1000 == 0 {
+		now := time.Now()
+		for k, e := range m.entries {
+			if now.After(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+	}
+}
+
+func (m *memoryLoginAttemptStore) Delete(key string) {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+}
+
+// LoginLimiter menerapkan exponential backoff dan lockout sementara per username dan per IP.
+type LoginLimiter struct {
+	store   LoginAttemptStore
+	cfg     config.LoginProtectionConfig
+	logRepo mongorepo.LogRepository
+}
+
+func NewLoginLimiter(store LoginAttemptStore, cfg config.LoginProtectionConfig, logRepo mongorepo.LogRepository) *LoginLimiter {
+	return &LoginLimiter{store: store, cfg: cfg, logRepo: logRepo}
+}
+
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func mfaKey(userID string) string {
+	return "mfa:" + userID
+}
+
+// Check menolak percobaan login jika username atau IP masih diblokir.
+func (l *LoginLimiter) Check(username string, client entity.ClientInfo) error {
+	return l.check(usernameKey(username), client)
+}
+
+// CheckMFA menolak verifikasi kode 2FA jika user atau IP masih diblokir.
+func (l *LoginLimiter) CheckMFA(userID string, client entity.ClientInfo) error {
+	return l.check(mfaKey(userID), client)
+}
+
+func (l *LoginLimiter) check(key string, client entity.ClientInfo) error {
+	now := time.Now()
+	keys := []string{key}
+	if client.IPAddress != "" {
+		keys = append(keys, ipKey(client.IPAddress))
+	}
+
+	var throttled *LoginThrottledError
+	for _, key := range keys {
+		attempt, ok := l.store.Get(key)
+		if !ok || !now.Before(attempt.BlockedUntil) {
+			continue
+		}
+		wait := attempt.BlockedUntil.Sub(now)
+		if throttled == nil || wait > throttled.RetryAfter {
+			throttled = &LoginThrottledError{RetryAfter: wait, Locked: attempt.Locked}
+		}
+	}
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+// RecordFailure mencatat gagal login; userID kosong jika username tidak dikenal.
+func (l *LoginLimiter) RecordFailure(username string, userID string, client entity.ClientInfo) {
+	if l.recordFailure(usernameKey(username), l.cfg.MaxAttempts) {
+		l.logActivity(userID, "login_locked", "username="+strings.ToLower(strings.TrimSpace(username)), client)
+	}
+	if client.IPAddress != "" && l.recordFailure(ipKey(client.IPAddress), l.cfg.IPMaxAttempts) {
+		l.logActivity(userID, "login_locked_ip", "ip="+client.IPAddress, client)
+	}
+}
+
+// RecordMFAFailure mencatat kode 2FA yang salah. Hitungan disimpan per user, bukan
+// per challenge, sehingga login ulang untuk mendapat challenge baru tidak mereset jatahnya.
+func (l *LoginLimiter) RecordMFAFailure(userID string, client entity.ClientInfo) {
+	if l.recordFailure(mfaKey(userID), l.cfg.MaxAttempts) {
+		l.logActivity(userID, "mfa_locked", "user_id="+userID, client)
+	}
+	if client.IPAddress != "" && l.recordFailure(ipKey(client.IPAddress), l.cfg.IPMaxAttempts) {
+		l.logActivity(userID, "login_locked_ip", "ip="+client.IPAddress, client)
+	}
+}
+
+// RecordSuccess mereset hitungan gagal untuk username dan kode 2FA user. Dipanggil
+// setelah login benar-benar selesai (termasuk 2FA). Hitungan per IP dibiarkan
+// berkurang sendiri lewat window agar satu akun valid tidak membuka blokir IP.
+func (l *LoginLimiter) RecordSuccess(username string, userID string) {
+	l.store.Delete(usernameKey(username))
+	l.store.Delete(mfaKey(userID))
+}
+
+// Unlock menghapus lockout username dan 2FA user (dipakai admin).
+func (l *LoginLimiter) Unlock(username string, userID string, adminID string, client entity.ClientInfo) {
+	l.store.Delete(usernameKey(username))
+	l.store.Delete(mfaKey(userID))
+	l.logActivity(adminID, "login_unlocked", "username="+strings.ToLower(strings.TrimSpace(username)), client)
+}
+
+// recordFailure mengembalikan true jika kegagalan ini memicu lockout.
+func (l *LoginLimiter) recordFailure(key string, threshold int) bool {
+	now := time.Now()
+	attempt, _ := l.store.Get(key)
+
+	// mulai hitungan baru jika lockout sudah lewat atau kegagalan terakhir sudah di luar window
+	if (attempt.Locked && !now.Before(attempt.BlockedUntil)) || now.Sub(attempt.LastFailure) > l.cfg.Window {
+		attempt = LoginAttempt{}
+	}
+
+	attempt.Failures++
+	attempt.LastFailure = now
+
+	lockedNow := false
+	if attempt.Failures >= threshold {
+		lockedNow = !attempt.Locked
+		attempt.Locked = true
+		attempt.BlockedUntil = now.Add(l.cfg.LockoutDuration)
+	} else {
+		attempt.BlockedUntil = now.Add(l.backoff(attempt.Failures))
+	}
+
+	ttl := l.cfg.Window
+	if until := attempt.BlockedUntil.Sub(now); until > ttl {
+		ttl = until
+	}
+	l.store.Save(key, attempt, ttl)
+	return lockedNow
+}
+
+func (l *LoginLimiter) backoff(failures int) time.Duration {
+	delay := l.cfg.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= l.cfg.MaxDelay {
+			return l.cfg.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (l *LoginLimiter) logActivity(userID string, action string, detail string, client entity.ClientInfo) {
+	doc := &entity.ActivityLog{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Action:    action,
+		Module:    "auth",
+		Detail:    detail,
+		IPAddress: client.IPAddress,
+		Device:    client.UserAgent,
+		CreatedAt: time.Now(),
+	}
+	if err := l.logRepo.SaveActivityLog(doc); err != nil {
+		log.Printf("Warning: failed to save activity log %s: %v", action, err)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/pkg"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	// BaseDelay nol: kegagalan di bawah ambang tidak memblokir, sehingga yang diuji hanya lockout
+	noBackoff := config.LoginProtectionConfig{
+		MaxAttempts: 3, IPMaxAttempts: 5, LockoutDuration: 15 * time.Minute, Window: 15 * time.Minute,
+	}
+	withBackoff := noBackoff
+	withBackoff.BaseDelay = 10 * time.Second
+	withBackoff.MaxDelay = 15 * time.Second
+
+	alice := entity.ClientInfo{IPAddress: "10.0.0.1"}
+	type attempt struct {
+		username string
+		client   entity.ClientInfo
+		success  bool
+	}
+	fail := func(username string, client entity.ClientInfo, n int) []attempt {
+		list := make([]attempt, n)
+		for i := range list {
+			list[i] = attempt{username: username, client: client}
+		}
+		return list
+	}
+	// satu IP mencoba banyak username, masing-masing di bawah ambang per username
+	spray := append(append(fail("a", alice, 2), fail("b", alice, 2)...), fail("c", alice, 1)...)
+
+	tests := []struct {
+		name     string
+		cfg      config.LoginProtectionConfig
+		attempts []attempt
+		// check dipanggil untuk username/IP ini setelah semua percobaan
+		checkUser   string
+		checkClient entity.ClientInfo
+		wantLocked  bool
+		// wantBlocked tanpa wantLocked berarti sedang dalam backoff
+		wantBlocked bool
+		maxWait     time.Duration
+		wantActions []string
+	}{
+		{
+			name:        "below threshold without backoff",
+			cfg:         noBackoff,
+			attempts:    fail("ana", alice, 2),
+			checkUser:   "ana",
+			checkClient: alice,
+			wantActions: []string{},
+		},
+		{
+			name:        "backoff after a failure",
+			cfg:         withBackoff,
+			attempts:    fail("ana", alice, 1),
+			checkUser:   "ana",
+			checkClient: alice,
+			wantBlocked: true,
+			maxWait:     10 * time.Second,
+			wantActions: []string{},
+		},
+		{
+			name:        "backoff doubles up to the maximum",
+			cfg:         withBackoff,
+			attempts:    fail("ana", alice, 2),
+			checkUser:   "ana",
+			checkClient: alice,
+			wantBlocked: true,
+			maxWait:     15 * time.Second,
+			wantActions: []string{},
+		},
+		{
+			name:        "username locked at threshold",
+			cfg:         noBackoff,
+			attempts:    fail("ana", alice, 3),
+			checkUser:   "ANA ",
+			checkClient: entity.ClientInfo{IPAddress: "10.0.0.2"},
+			wantLocked:  true,
+			wantBlocked: true,
+			maxWait:     15 * time.Minute,
+			wantActions: []string{"login_locked"},
+		},
+		{
+			name:        "lockout is logged once",
+			cfg:         noBackoff,
+			attempts:    fail("ana", alice, 4),
+			checkUser:   "ana",
+			checkClient: alice,
+			wantLocked:  true,
+			wantBlocked: true,
+			maxWait:     15 * time.Minute,
+			wantActions: []string{"login_locked"},
+		},
+		{
+			name: "success resets the username counter",
+			cfg:  noBackoff,
+			attempts: append(append(fail("ana", alice, 2), attempt{username: "ana", client: alice, success: true}),
+				fail("ana", alice, 2)...),
+			checkUser:   "ana",
+			checkClient: alice,
+			wantActions: []string{},
+		},
+		{
+			name:        "ip locked across usernames",
+			cfg:         noBackoff,
+			attempts:    spray,
+			checkUser:   "d",
+			checkClient: alice,
+			wantLocked:  true,
+			wantBlocked: true,
+			maxWait:     15 * time.Minute,
+			wantActions: []string{"login_locked_ip"},
+		},
+		{
+			name:        "other ip is not affected by an ip lock",
+			cfg:         noBackoff,
+			attempts:    spray,
+			checkUser:   "d",
+			checkClient: entity.ClientInfo{IPAddress: "10.0.0.2"},
+			wantActions: []string{"login_locked_ip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &fakeLogRepo{}
+			limiter := NewLoginLimiter(NewMemoryLoginAttemptStore(), tt.cfg, logs)
+			for _, a := range tt.attempts {
+				if a.success {
+					limiter.RecordSuccess(a.username, "")
+				} else {
+					limiter.RecordFailure(a.username, "", a.client)
+				}
+			}
+
+			err := limiter.Check(tt.checkUser, tt.checkClient)
+			var throttled *LoginThrottledError
+			if blocked := errors.As(err, &throttled); blocked != tt.wantBlocked {
+				t.Fatalf("Check = %v, want blocked=%v", err, tt.wantBlocked)
+			}
+			if throttled != nil {
+				if throttled.Locked != tt.wantLocked {
+					t.Fatalf("locked = %v, want %v", throttled.Locked, tt.wantLocked)
+				}
+				if throttled.RetryAfter <= 0 || throttled.RetryAfter > tt.maxWait {
+					t.Fatalf("retry after %v, want (0, %v]", throttled.RetryAfter, tt.maxWait)
+				}
+			}
+			if got := logs.actions(); !slices.Equal(got, tt.wantActions) {
+				t.Fatalf("activity log = %v, want %v", got, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestLoginLimiterWindowAndUnlock(t *testing.T) {
+	cfg := config.LoginProtectionConfig{MaxAttempts: 2, IPMaxAttempts: 10, LockoutDuration: time.Minute, Window: time.Minute}
+	store := NewMemoryLoginAttemptStore()
+	limiter := NewLoginLimiter(store, cfg, &fakeLogRepo{})
+
+	// kegagalan yang sudah di luar window tidak dihitung
+	store.Save(usernameKey("ana"), LoginAttempt{Failures: 1, LastFailure: time.Now().Add(-2 * time.Minute)}, time.Hour)
+	limiter.RecordFailure("ana", "", entity.ClientInfo{})
+	if err := limiter.Check("ana", entity.ClientInfo{}); err != nil {
+		t.Fatalf("stale failure counted: %v", err)
+	}
+
+	limiter.RecordFailure("ana", "", entity.ClientInfo{})
+	if err := limiter.Check("ana", entity.ClientInfo{}); err == nil {
+		t.Fatal("expected lockout")
+	}
+	limiter.Unlock("ana", "", "admin-id", entity.ClientInfo{})
+	if err := limiter.Check("ana", entity.ClientInfo{}); err != nil {
+		t.Fatalf("still locked after Unlock: %v", err)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	f := newAuthFixture(t)
+	hash, err := utils.HashPassword("rahasia-kuat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", PasswordHash: hash, IsActive: true}, "buyer")
+	max := config.LoadLoginProtection().MaxAttempts
+
+	// IP berbeda tiap percobaan agar yang terkunci adalah username, bukan IP
+	for i := 0; i < max; i++ {
+		client := entity.ClientInfo{IPAddress: fmt.Sprintf("10.0.0.%d", i+1)}
+		if _, _, err := f.auth.Login("ana", "salah", client); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+		// backoff di antara percobaan dilewati; yang diuji hanya ambang lockout
+		attempt, _ := f.auth.loginLimiter.store.Get(usernameKey("ana"))
+		if !attempt.Locked {
+			attempt.BlockedUntil = time.Time{}
+			f.auth.loginLimiter.store.Save(usernameKey("ana"), attempt, time.Hour)
+		}
+	}
+
+	_, _, err = f.auth.Login("ana", "rahasia-kuat-1", entity.ClientInfo{IPAddress: "10.0.1.1"})
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) || !throttled.Locked {
+		t.Fatalf("err = %v, want a lockout", err)
+	}
+	if got := f.logs.actions(); !slices.Equal(got, []string{"login_locked"}) {
+		t.Fatalf("activity log = %v", got)
+	}
+}
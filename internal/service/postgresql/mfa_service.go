@@ -0,0 +1,223 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFASetupMissing   = errors.New("two-factor setup has not been started")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired mfa token")
+)
+
+const recoveryCodeCount = 10
+
+type MFAService struct {
+	mfaRepo  repo.MFARepository
+	userRepo repo.UserRepository
+	cfg      config.MFAConfig
+}
+
+func NewMFAService(mfaRepo repo.MFARepository, userRepo repo.UserRepository, cfg config.MFAConfig) *MFAService {
+	return &MFAService{mfaRepo: mfaRepo, userRepo: userRepo, cfg: cfg}
+}
+
+// @Summary      Start 2FA Enrollment
+// @Description  Generates a new TOTP secret and provisioning URI (render it as a QR code). 2FA becomes active only after /auth/2fa/enable.
+// @Tags         Auth/2FA
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  entity.MFASetupResponse
+// @Failure      409  {object}  map[string]interface{} "2FA already enabled"
+// @Router       /auth/2fa/setup [post]
+func (s *MFAService) Setup(userID uuid.UUID) (*entity.MFASetupResponse, error) {
+	existing, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := utils.EncryptString(s.cfg.EncryptionKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SavePending(userID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &entity.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.cfg.Issuer, user.Email, secret),
+	}, nil
+}
+
+// @Summary      Enable 2FA
+// @Description  Confirms enrollment with a code from the authenticator app and returns one-time recovery codes. The codes are shown only once.
+// @Tags         Auth/2FA
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.MFACodeInput true "TOTP code"
+// @Success      200  {object}  map[string]interface{} "Returns recovery_codes"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /auth/2fa/enable [post]
+func (s *MFAService) Enable(userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, ErrMFASetupMissing
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	ok, err := s.checkTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.replaceRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(userID); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// @Summary      Disable 2FA
+// @Description  Turns off two-factor authentication. Requires a current TOTP code or an unused recovery code.
+// @Tags         Auth/2FA
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.MFACodeInput true "TOTP code or recovery code"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Router       /auth/2fa/disable [post]
+func (s *MFAService) Disable(userID uuid.UUID, code, recoveryCode string) error {
+	ok, err := s.VerifyCode(userID, code, recoveryCode)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	return s.mfaRepo.Delete(userID)
+}
+
+// @Summary      Regenerate Recovery Codes
+// @Description  Invalidates all previous recovery codes and returns a fresh set. Requires a current TOTP code.
+// @Tags         Auth/2FA
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.MFACodeInput true "TOTP code"
+// @Success      200  {object}  map[string]interface{} "Returns recovery_codes"
+// @Failure      400  {object}  map[string]interface{}
+// @Router       /auth/2fa/recovery-codes [post]
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	ok, err := s.VerifyCode(userID, code, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+func (s *MFAService) IsEnabled(userID uuid.UUID) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa != nil && mfa.EnabledAt != nil, nil
+}
+
+// VerifyCode menerima kode TOTP atau recovery code (recovery code hanya bisa dipakai sekali).
+func (s *MFAService) VerifyCode(userID uuid.UUID, code, recoveryCode string) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return false, err
+	}
+	if mfa == nil || mfa.EnabledAt == nil {
+		return false, ErrMFANotEnabled
+	}
+
+	if recoveryCode != "" {
+		return s.mfaRepo.UseRecoveryCode(userID, utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+	return s.checkTOTP(mfa, code)
+}
+
+// checkTOTP menerima setiap time step paling banyak sekali, sehingga kode yang sama
+// tidak bisa dipakai ulang selama masih berada di jendela validnya.
+func (s *MFAService) checkTOTP(mfa *entity.UserMFA, code string) (bool, error) {
+	secret, err := utils.DecryptString(s.cfg.EncryptionKey, mfa.SecretEncrypted)
+	if err != nil {
+		return false, err
+	}
+	step, ok := utils.MatchTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return s.mfaRepo.UseTimeStep(mfa.UserID, step)
+}
+
+func (s *MFAService) replaceRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(buf)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, utils.HashToken(raw))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
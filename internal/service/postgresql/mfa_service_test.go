@@ -0,0 +1,314 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+// currentTOTP menghitung kode TOTP (RFC 6238, SHA1, 6 digit, 30 detik) untuk saat ini.
+func currentTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// enableMFA menjalankan Setup dan Enable untuk user, lalu mengembalikan secret dan
+// recovery code. Time step yang dipakai Enable direset agar kode berikutnya bisa dipakai.
+func (f *authFixture) enableMFA(t *testing.T, userID uuid.UUID) (string, []string) {
+	t.Helper()
+	setup, err := f.mfaService.Setup(userID)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	codes, err := f.mfaService.Enable(userID, currentTOTP(t, setup.Secret))
+	if err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	delete(f.mfa.lastStep, userID)
+	return setup.Secret, codes
+}
+
+func TestMFAVerifyCode(t *testing.T) {
+	tests := []struct {
+		name string
+		// input mengembalikan kode TOTP dan recovery code yang diverifikasi
+		input   func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string)
+		want    bool
+		wantErr error
+	}{
+		{
+			name: "current totp code",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				return currentTOTP(t, secret), ""
+			},
+			want: true,
+		},
+		{
+			name: "totp code cannot be replayed",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				code := currentTOTP(t, secret)
+				if ok, err := f.mfaService.VerifyCode(userID, code, ""); !ok || err != nil {
+					t.Fatalf("first use = (%v, %v)", ok, err)
+				}
+				return code, ""
+			},
+		},
+		{
+			name: "wrong totp code",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				code := currentTOTP(t, secret)
+				return code[:5] + string('0'+(code[5]-'0'+1)%10), ""
+			},
+		},
+		{
+			name: "recovery code is normalized",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				return "", " " + strings.ToUpper(recovery[0]) + " "
+			},
+			want: true,
+		},
+		{
+			name: "recovery code is single use",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				if ok, err := f.mfaService.VerifyCode(userID, "", recovery[1]); !ok || err != nil {
+					t.Fatalf("first use = (%v, %v)", ok, err)
+				}
+				return "", recovery[1]
+			},
+		},
+		{
+			name: "disabled 2fa",
+			input: func(t *testing.T, f *authFixture, userID uuid.UUID, secret string, recovery []string) (string, string) {
+				f.mfa.Delete(userID)
+				return currentTOTP(t, secret), ""
+			},
+			wantErr: ErrMFANotEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "seller")
+			secret, recovery := f.enableMFA(t, user.ID)
+			if len(recovery) != recoveryCodeCount {
+				t.Fatalf("got %d recovery codes, want %d", len(recovery), recoveryCodeCount)
+			}
+
+			code, recoveryCode := tt.input(t, f, user.ID, secret, recovery)
+			ok, err := f.mfaService.VerifyCode(user.ID, code, recoveryCode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.want {
+				t.Fatalf("VerifyCode = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestMFAEnableRequiresPendingSetup(t *testing.T) {
+	f := newAuthFixture(t)
+	user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "seller")
+
+	if _, err := f.mfaService.Enable(user.ID, "123456"); !errors.Is(err, ErrMFASetupMissing) {
+		t.Fatalf("err = %v, want ErrMFASetupMissing", err)
+	}
+	secret, _ := f.enableMFA(t, user.ID)
+	if _, err := f.mfaService.Setup(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Fatalf("err = %v, want ErrMFAAlreadyEnabled", err)
+	}
+	if stored, _ := f.mfa.GetByUserID(user.ID); stored.SecretEncrypted == secret {
+		t.Fatal("TOTP secret is stored in plaintext")
+	}
+}
+
+func TestMFAClaimSurvivesRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// viaMFA: sesi dibuka lewat /auth/2fa/verify; jika false, sesi dibuka sebelum 2FA aktif
+		viaMFA  bool
+		wantMFA bool
+	}{
+		{name: "session opened with 2fa", viaMFA: true, wantMFA: true},
+		{name: "session opened before 2fa was enabled", viaMFA: false, wantMFA: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "seller")
+
+			var refreshToken string
+			if tt.viaMFA {
+				secret, _ := f.enableMFA(t, user.ID)
+				resp, challenge, err := f.auth.LoginWithIdentity(user.ID, entity.ClientInfo{})
+				if err != nil || resp != nil || challenge == nil {
+					t.Fatalf("login = (%v, %v, %v), want an MFA challenge", resp, challenge, err)
+				}
+				login, err := f.auth.VerifyMFA(entity.MFAVerifyInput{MFAToken: challenge.MFAToken, Code: currentTOTP(t, secret)}, entity.ClientInfo{})
+				if err != nil {
+					t.Fatalf("VerifyMFA: %v", err)
+				}
+				refreshToken = login.RefreshToken
+			} else {
+				resp, _, err := f.auth.LoginWithIdentity(user.ID, entity.ClientInfo{})
+				if err != nil || resp == nil {
+					t.Fatalf("login = (%v, %v)", resp, err)
+				}
+				f.enableMFA(t, user.ID)
+				refreshToken = resp.RefreshToken
+			}
+
+			refreshed, err := f.auth.Refresh(refreshToken, entity.ClientInfo{})
+			if err != nil {
+				t.Fatalf("Refresh: %v", err)
+			}
+			claims, err := utils.ValidateToken(refreshed.Token)
+			if err != nil {
+				t.Fatalf("access token: %v", err)
+			}
+			if claims.MFA != tt.wantMFA {
+				t.Fatalf("mfa claim = %v, want %v", claims.MFA, tt.wantMFA)
+			}
+		})
+	}
+}
+
+func TestVerifyMFAChallengeIsSingleUse(t *testing.T) {
+	f := newAuthFixture(t)
+	user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "seller")
+	_, recovery := f.enableMFA(t, user.ID)
+
+	_, challenge, err := f.auth.LoginWithIdentity(user.ID, entity.ClientInfo{})
+	if err != nil || challenge == nil {
+		t.Fatalf("login = (%v, %v)", challenge, err)
+	}
+	input := entity.MFAVerifyInput{MFAToken: challenge.MFAToken, RecoveryCode: recovery[0]}
+	if _, err := f.auth.VerifyMFA(input, entity.ClientInfo{}); err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	input.RecoveryCode = recovery[1]
+	if _, err := f.auth.VerifyMFA(input, entity.ClientInfo{}); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Fatalf("err = %v, want ErrInvalidMFAToken", err)
+	}
+}
+
+func TestVerifyMFAAttempts(t *testing.T) {
+	// BaseDelay nol agar kode berikutnya bisa langsung dicoba; yang diuji jatah percobaan
+	noBackoff := config.LoginProtectionConfig{
+		MaxAttempts: 5, IPMaxAttempts: 100, LockoutDuration: 15 * time.Minute, Window: 15 * time.Minute,
+	}
+	withBackoff := noBackoff
+	withBackoff.BaseDelay = time.Minute
+	withBackoff.MaxDelay = time.Minute
+
+	tests := []struct {
+		name string
+		cfg  config.LoginProtectionConfig
+		// wrong berisi jumlah kode salah untuk setiap challenge berturut-turut;
+		// kode benar dikirim dengan challenge terakhir
+		wrong   []int
+		wantErr error
+		// wantThrottled: kode benar ditolak karena backoff/lockout per user
+		wantThrottled bool
+	}{
+		{name: "correct code first", cfg: noBackoff, wrong: []int{0}},
+		{name: "challenge survives a wrong code", cfg: noBackoff, wrong: []int{mfaChallengeMaxAttempts - 1}},
+		{name: "challenge is burned after its attempts", cfg: noBackoff, wrong: []int{mfaChallengeMaxAttempts}, wantErr: ErrInvalidMFAToken},
+		{name: "new challenges keep the per-user count", cfg: noBackoff, wrong: []int{2, 2, 1, 0}, wantThrottled: true},
+		{name: "backoff after a wrong code", cfg: withBackoff, wrong: []int{1}, wantThrottled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			f.auth.loginLimiter = NewLoginLimiter(NewMemoryLoginAttemptStore(), tt.cfg, f.logs)
+			user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", IsActive: true}, "seller")
+			secret, _ := f.enableMFA(t, user.ID)
+
+			var mfaToken string
+			for _, wrong := range tt.wrong {
+				_, challenge, err := f.auth.LoginWithIdentity(user.ID, entity.ClientInfo{})
+				if err != nil || challenge == nil {
+					t.Fatalf("login = (%v, %v)", challenge, err)
+				}
+				mfaToken = challenge.MFAToken
+				for i := 0; i < wrong; i++ {
+					if _, err := f.auth.VerifyMFA(entity.MFAVerifyInput{MFAToken: mfaToken, Code: "000000"}, entity.ClientInfo{}); !errors.Is(err, ErrInvalidMFACode) {
+						t.Fatalf("wrong code %d: err = %v, want ErrInvalidMFACode", i+1, err)
+					}
+				}
+			}
+
+			_, err := f.auth.VerifyMFA(entity.MFAVerifyInput{MFAToken: mfaToken, Code: currentTOTP(t, secret)}, entity.ClientInfo{})
+			var throttled *LoginThrottledError
+			if errors.As(err, &throttled) != tt.wantThrottled {
+				t.Fatalf("err = %v, want throttled=%v", err, tt.wantThrottled)
+			}
+			if !tt.wantThrottled && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err := f.auth.loginLimiter.CheckMFA(user.ID.String(), entity.ClientInfo{}); err != nil {
+					t.Fatalf("failures not reset after a successful 2FA: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginResetsFailuresOnlyAfterMFA(t *testing.T) {
+	f := newAuthFixture(t)
+	hash, err := utils.HashPassword("rahasia-kuat-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := f.users.add(&entity.User{Username: "ana", Email: "ana@example.com", PasswordHash: hash, IsActive: true}, "seller")
+	secret, _ := f.enableMFA(t, user.ID)
+	f.auth.loginLimiter = NewLoginLimiter(NewMemoryLoginAttemptStore(), config.LoginProtectionConfig{
+		MaxAttempts: 2, IPMaxAttempts: 100, LockoutDuration: 15 * time.Minute, Window: 15 * time.Minute,
+	}, f.logs)
+
+	// password benar yang berhenti di challenge 2FA tidak mereset hitungan gagal password
+	f.auth.Login("ana", "salah", entity.ClientInfo{})
+	_, challenge, err := f.auth.Login("ana", "rahasia-kuat-1", entity.ClientInfo{})
+	if err != nil || challenge == nil {
+		t.Fatalf("login = (%v, %v), want an MFA challenge", challenge, err)
+	}
+	f.auth.Login("ana", "salah", entity.ClientInfo{})
+	var throttled *LoginThrottledError
+	if _, _, err := f.auth.Login("ana", "rahasia-kuat-1", entity.ClientInfo{}); !errors.As(err, &throttled) {
+		t.Fatalf("err = %v, want a lockout", err)
+	}
+
+	// 2FA yang berhasil menyelesaikan login dan mereset hitungannya
+	if _, err := f.auth.VerifyMFA(entity.MFAVerifyInput{MFAToken: challenge.MFAToken, Code: currentTOTP(t, secret)}, entity.ClientInfo{}); err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	if err := f.auth.loginLimiter.Check("ana", entity.ClientInfo{}); err != nil {
+		t.Fatalf("still locked after a completed login: %v", err)
+	}
+}
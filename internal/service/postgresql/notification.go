@@ -0,0 +1,31 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// saveNotification menyimpan notifikasi in-app untuk userID. Gagal menyimpan hanya
+// dicatat agar aksi utama (order, penawaran, dst.) tidak ikut gagal.
+func saveNotification(logRepo mongorepo.LogRepository, userID uuid.UUID, title string, message string, notiType string, relatedID uuid.UUID) {
+	noti := &entity.Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Title:     title,
+		Message:   message,
+		Type:      notiType,
+		RelatedID: relatedID,
+		IsRead:    false,
+		CreatedAt: time.Now(),
+	}
+
+	if err := logRepo.SaveNotification(noti); err != nil {
+		log.Printf("Warning: failed to save notification for user %s: %v", userID.String(), err)
+	}
+}
@@ -14,30 +14,34 @@ import (
 )
 
 var (
-	ErrNotGiver         = errors.New("access denied: only giver role is allowed")
+	ErrNotGiver         = errors.New("access denied: offer:create permission is required")
 	ErrOfferNotFound    = errors.New("offer not found")
 	ErrOfferStatus      = errors.New("offer is not in pending status")
 	ErrNotSellerOrOwner = errors.New("unauthorized: access denied or you are not the owner")
 )
 
 type OfferService struct {
-	offerRepo repo.OfferRepository
-	itemRepo  repo.ItemRepository
-	shopRepo  repo.ShopRepository
-	logRepo   mongorepo.LogRepository
+	offerRepo  repo.OfferRepository
+	itemRepo   repo.ItemRepository
+	shopRepo   repo.ShopRepository
+	memberRepo repo.ShopMemberRepository
+	logRepo    mongorepo.LogRepository
 }
 
-func NewOfferService(offerRepo repo.OfferRepository, itemRepo repo.ItemRepository, shopRepo repo.ShopRepository, logRepo mongorepo.LogRepository) *OfferService {
+func NewOfferService(offerRepo repo.OfferRepository, itemRepo repo.ItemRepository, shopRepo repo.ShopRepository, memberRepo repo.ShopMemberRepository, logRepo mongorepo.LogRepository) *OfferService {
 	return &OfferService{
-		offerRepo: offerRepo,
-		itemRepo:  itemRepo,
-		shopRepo:  shopRepo,
-		logRepo:   logRepo,
+		offerRepo:  offerRepo,
+		itemRepo:   itemRepo,
+		shopRepo:   shopRepo,
+		memberRepo: memberRepo,
+		logRepo:    logRepo,
 	}
 }
 
+// checkSellerOwnership mengembalikan toko user jika ia owner atau staf dengan izin handle_offers.
+// Penawaran ditujukan ke user pemilik toko (shop.UserID), bukan ke staf.
 func (s *OfferService) checkSellerOwnership(userID uuid.UUID) (*entity.Shop, error) {
-	shop, err := s.shopRepo.GetByUserID(userID)
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermHandleOffers)
 	if err != nil {
 		return nil, err
 	}
@@ -52,17 +56,18 @@ func (s *OfferService) createDraftItemFromOffer(offer *entity.Offer, shopID uuid
         price = offer.ExpectedPrice 
     }
 	return &entity.Item{
-		ID:          uuid.New(),
-		ShopID:      shopID,
-		CategoryID:  uuid.Nil,
-		Name:        offer.ItemName,
-		Description: fmt.Sprintf("Draft dari Penawaran: %s. Kondisi: %s. Lokasi Awal: %s.", offer.Description, offer.Condition, offer.Location),
-		Price:       price,
-		Stock:       1,
-		Condition:   offer.Condition,
-		Status:      "draft",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:            uuid.New(),
+		ShopID:        shopID,
+		CategoryID:    uuid.Nil,
+		SourceOfferID: &offer.ID,
+		Name:          offer.ItemName,
+		Description:   fmt.Sprintf("Draft dari Penawaran: %s. Kondisi: %s. Lokasi Awal: %s.", offer.Description, offer.Condition, offer.Location),
+		Price:         price,
+		Stock:         1,
+		Condition:     offer.Condition,
+		Status:        "draft",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 }
 
@@ -101,11 +106,7 @@ func (s *OfferService) createAndSaveNotification(userID uuid.UUID, title string,
 // @Failure      403  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /offers [post]
-func (s *OfferService) CreateOffer(userID uuid.UUID, role string, input entity.CreateOfferInput, imageURL string) (*entity.Offer, error) {
-	if role != "giver" {
-		return nil, ErrNotGiver
-	}
-
+func (s *OfferService) CreateOffer(userID uuid.UUID, input entity.CreateOfferInput, imageURL string) (*entity.Offer, error) {
 	var sellerID uuid.UUID
 	if input.SellerIDStr != "" {
 		id, err := uuid.Parse(input.SellerIDStr)
@@ -146,7 +147,7 @@ func (s *OfferService) CreateOffer(userID uuid.UUID, role string, input entity.C
 }
 
 // @Summary      View My Outgoing Offers
-// @Description  Allows the Giver to view the status of all offers they have created.
+// @Description  Allows a user with the offer:create permission (e.g. a Giver) to view the status of all offers they have created.
 // @Tags         Offers
 // @Accept       json
 // @Produce      json
@@ -155,15 +156,15 @@ func (s *OfferService) CreateOffer(userID uuid.UUID, role string, input entity.C
 // @Failure      403  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /offers/my [get]
-func (s *OfferService) GetMyOffers(userID uuid.UUID, role string) ([]entity.Offer, error) {
-	if role != "giver" {
+func (s *OfferService) GetMyOffers(userID uuid.UUID, permissions []string) ([]entity.Offer, error) {
+	if !HasPermission(permissions, entity.PermOfferCreate) {
 		return nil, ErrNotGiver
 	}
 	return s.offerRepo.GetOffersByGiverID(userID)
 }
 
 // @Summary      View Seller Offer Inbox
-// @Description  Allows a Seller to view pending offers directed to them or general open offers.
+// @Description  Allows the shop owner or staff with the handle_offers shop permission to view pending offers directed to the shop owner or general open offers.
 // @Tags         Offers
 // @Accept       json
 // @Produce      json
@@ -173,19 +174,12 @@ func (s *OfferService) GetMyOffers(userID uuid.UUID, role string) ([]entity.Offe
 // @Failure      403  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /offers/inbox [get]
-func (s *OfferService) GetOffersToSeller(userID uuid.UUID, role string) ([]entity.Offer, error) {
-	if role != "seller" {
-		return nil, errors.New("access denied: only seller can view offers")
-	}
-
-	shop, err := s.shopRepo.GetByUserID(userID)
+func (s *OfferService) GetOffersToSeller(userID uuid.UUID) ([]entity.Offer, error) {
+	shop, err := s.checkSellerOwnership(userID)
 	if err != nil {
 		return nil, err
 	}
-	if shop == nil {
-		return nil, ErrNoShopOwned
-	}
-	return s.offerRepo.GetOffersBySellerID(userID)
+	return s.offerRepo.GetOffersBySellerID(shop.UserID)
 }
 
 // @Summary      Accept Offer and Create Item Draft
@@ -208,9 +202,6 @@ func (s *OfferService) AcceptOffer(userID uuid.UUID, offerID uuid.UUID, input en
 	if err != nil {
 		return nil, nil, err
 	}
-	if shop == nil {
-		return nil, nil, ErrNoShopOwned
-	}
 
 	offer, err := s.offerRepo.GetOfferByID(offerID)
 	if err != nil {
@@ -220,7 +211,7 @@ func (s *OfferService) AcceptOffer(userID uuid.UUID, offerID uuid.UUID, input en
 		return nil, nil, ErrOfferNotFound
 	}
 
-	if offer.SellerID != uuid.Nil && offer.SellerID != userID {
+	if offer.SellerID != uuid.Nil && offer.SellerID != shop.UserID {
 		return nil, nil, ErrNotSellerOrOwner
 	}
 
@@ -273,10 +264,9 @@ func (s *OfferService) AcceptOffer(userID uuid.UUID, offerID uuid.UUID, input en
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /offers/{id}/reject [post]
 func (s *OfferService) RejectOffer(userID uuid.UUID, offerID uuid.UUID) (*entity.Offer, error) {
-	if shop, err := s.checkSellerOwnership(userID); err != nil {
+	shop, err := s.checkSellerOwnership(userID)
+	if err != nil {
 		return nil, err
-	} else if shop == nil {
-		return nil, ErrNoShopOwned
 	}
 
 	offer, err := s.offerRepo.GetOfferByID(offerID)
@@ -286,6 +276,9 @@ func (s *OfferService) RejectOffer(userID uuid.UUID, offerID uuid.UUID) (*entity
 	if offer == nil {
 		return nil, ErrOfferNotFound
 	}
+	if offer.SellerID != uuid.Nil && offer.SellerID != shop.UserID {
+		return nil, ErrNotSellerOrOwner
+	}
 	if offer.Status != "pending" {
 		return nil, ErrOfferStatus
 	}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	entity "home-market/internal/domain"
+	"home-market/internal/oidc"
+	repo "home-market/internal/repository/postgresql"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOIDCDisabled          = errors.New("external login is not configured")
+	ErrOIDCInvalidState      = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed       = errors.New("external login failed")
+	ErrOIDCEmailMissing      = errors.New("identity provider did not return a verified email")
+	ErrOIDCAccountUnverified = errors.New("an account with this email exists but its email is not verified; sign in with password and verify the email before using external login")
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9_.]+`)
+
+type OIDCService struct {
+	provider      *oidc.Provider
+	states        *oidc.StateStore
+	identityRepo  repo.IdentityRepository
+	userRepo      repo.UserRepository
+	authService   *AuthService
+	defaultRoleID uuid.UUID
+}
+
+func NewOIDCService(
+	provider *oidc.Provider,
+	states *oidc.StateStore,
+	identityRepo repo.IdentityRepository,
+	userRepo repo.UserRepository,
+	authService *AuthService,
+	defaultRoleID uuid.UUID,
+) *OIDCService {
+	return &OIDCService{
+		provider:      provider,
+		states:        states,
+		identityRepo:  identityRepo,
+		userRepo:      userRepo,
+		authService:   authService,
+		defaultRoleID: defaultRoleID,
+	}
+}
+
+// @Summary      Start External Login
+// @Description  Starts the OpenID Connect authorization code + PKCE flow. Redirects to the identity provider, or returns the URL as JSON with mode=json.
+// @Tags         Auth/OIDC
+// @Produce      json
+// @Param        mode  query  string  false  "Set to 'json' to receive the authorization URL instead of a redirect"
+// @Success      302
+// @Success      200  {object}  entity.OIDCStartResponse
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /auth/oidc/login [get]
+func (s *OIDCService) Start(ctx context.Context) (string, error) {
+	if !s.provider.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+
+	state, err := utils.GenerateOpaqueToken(24)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateOpaqueToken(24)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	s.states.Put(state, oidc.AuthRequest{
+		Verifier:  verifier,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(oidcStateTTL),
+	})
+	return authURL, nil
+}
+
+// @Summary      External Login Callback
+// @Description  Completes the OpenID Connect login. Links the identity to an existing account whose email is verified (accounts with an unverified email are refused) or provisions a new buyer account, then returns the normal login response (or an MFA challenge). In cookie mode the tokens are set as cookies exactly like password login.
+// @Tags         Auth/OIDC
+// @Produce      json
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "State returned by the provider"
+// @Success      200  {object}  entity.LoginResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Account with this email is not verified"
+// @Router       /auth/oidc/callback [get]
+func (s *OIDCService) Callback(ctx context.Context, code, state string, client entity.ClientInfo) (*entity.LoginResponse, *entity.MFAChallenge, error) {
+	if !s.provider.Enabled() {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	authReq, ok := s.states.Take(state)
+	if !ok {
+		return nil, nil, ErrOIDCInvalidState
+	}
+
+	token, err := s.provider.Exchange(ctx, code, authReq.Verifier)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, authReq.Nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	userID, err := s.resolveUser(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.authService.LoginWithIdentity(userID, client)
+}
+
+// resolveUser mencari user untuk identitas eksternal: identitas yang sudah tertaut,
+// lalu user lokal dengan email sama yang sudah diverifikasi, terakhir membuat akun buyer baru.
+func (s *OIDCService) resolveUser(claims *oidc.IDTokenClaims) (uuid.UUID, error) {
+	provider := s.provider.Issuer()
+
+	identity, err := s.identityRepo.GetByProviderSubject(provider, claims.Subject)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if identity != nil {
+		return identity.UserID, nil
+	}
+
+	// linking dan provisioning hanya dengan email yang diverifikasi provider,
+	// agar tidak bisa mengambil alih akun lokal dengan email milik orang lain
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return uuid.Nil, ErrOIDCEmailMissing
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if user == nil {
+		user, err = s.provisionUser(claims, email)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	} else if user.EmailVerifiedAt == nil {
+		// akun lokal dengan email belum terverifikasi bisa jadi didaftarkan orang lain
+		// (pre-account hijacking): menautkannya akan membuat password pendaftar itu
+		// tetap membuka akun korban, jadi tidak ditautkan
+		return uuid.Nil, ErrOIDCAccountUnverified
+	}
+
+	identity = &entity.UserIdentity{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return uuid.Nil, err
+	}
+	return user.ID, nil
+}
+
+// provisionUser membuat akun buyer baru tanpa password yang bisa dipakai login lokal.
+func (s *OIDCService) provisionUser(claims *oidc.IDTokenClaims, email string) (*entity.User, error) {
+	if s.defaultRoleID == uuid.Nil {
+		return nil, errors.New("default role 'buyer' is not set")
+	}
+
+	username, err := s.availableUsername(claims, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// password acak yang tidak pernah diberikan ke user; user bisa memakai
+	// lupa password jika ingin login lokal
+	randomPassword, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := &entity.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        email,
+		FullName:     fullName,
+		PasswordHash: hashed,
+		RoleID:       s.defaultRoleID,
+		IsActive:     true,
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername menurunkan username dari preferred_username atau email,
+// dengan akhiran angka jika sudah dipakai.
+func (s *OIDCService) availableUsername(claims *oidc.IDTokenClaims, email string) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameSanitizer.ReplaceAllString(strings.ToLower(base), "_"), "_.")
+	if len(base) < 3 {
+		base = "user_" + base
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	candidate := base
+	for i := 1; i <= 50; i++ {
+		_, _, err := s.userRepo.GetByUsername(candidate)
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", errors.New("could not find an available username")
+}
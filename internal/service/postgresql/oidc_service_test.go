@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+	entity "home-market/internal/domain"
+	"home-market/internal/oidc"
+	"home-market/internal/oidc/oidctest"
+
+	"github.com/google/uuid"
+)
+
+type oidcFixture struct {
+	*authFixture
+	server     *oidctest.Server
+	identities *fakeIdentityRepo
+	service    *OIDCService
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	srv, err := oidctest.NewServer("home-market")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+
+	f := &oidcFixture{authFixture: newAuthFixture(t), server: srv, identities: &fakeIdentityRepo{}}
+	provider := oidc.NewProvider(config.OIDCConfig{
+		IssuerURL:   srv.URL,
+		ClientID:    "home-market",
+		RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:      "openid email profile",
+	})
+	buyerRoleID := uuid.New()
+	f.users.roleNames[buyerRoleID] = "buyer"
+	f.service = NewOIDCService(provider, oidc.NewStateStore(), f.identities, f.users, f.auth, buyerRoleID)
+	return f
+}
+
+// login menjalankan alur lengkap: Start, persetujuan di provider, lalu Callback.
+func (f *oidcFixture) login(t *testing.T, id oidctest.Identity) (*entity.LoginResponse, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, err := f.service.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	code, state, err := f.server.Authorize(authURL, id)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	resp, _, err := f.service.Callback(ctx, code, state, entity.ClientInfo{})
+	return resp, err
+}
+
+func TestOIDCCallback(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name     string
+		existing *entity.User // user lokal yang sudah ada sebelum login
+		linked   bool         // identitas sudah tertaut ke user existing
+		identity oidctest.Identity
+		wantErr  error
+		check    func(t *testing.T, f *oidcFixture, resp *entity.LoginResponse)
+	}{
+		{
+			name:     "links existing user by verified email",
+			existing: &entity.User{Username: "ana", Email: "Ana@Example.com", IsActive: true, EmailVerifiedAt: &verifiedAt},
+			identity: oidctest.Identity{Subject: "sub-ana", Email: "ana@example.com", EmailVerified: true},
+			check: func(t *testing.T, f *oidcFixture, resp *entity.LoginResponse) {
+				if resp.User.Username != "ana" {
+					t.Fatalf("logged in as %q, want ana", resp.User.Username)
+				}
+				if len(f.identities.identities) != 1 || f.identities.identities[0].UserID != resp.User.ID {
+					t.Fatalf("identities = %+v", f.identities.identities)
+				}
+			},
+		},
+		{
+			// pendaftar dengan email korban tidak boleh ikut memegang akun yang ditautkan
+			name:     "local account with unverified email is not linked",
+			existing: &entity.User{Username: "ana", Email: "ana@example.com", IsActive: true},
+			identity: oidctest.Identity{Subject: "sub-ana", Email: "ana@example.com", EmailVerified: true},
+			wantErr:  ErrOIDCAccountUnverified,
+		},
+		{
+			name:     "unverified email is not linked",
+			existing: &entity.User{Username: "ana", Email: "ana@example.com", IsActive: true},
+			identity: oidctest.Identity{Subject: "sub-ana", Email: "ana@example.com"},
+			wantErr:  ErrOIDCEmailMissing,
+		},
+		{
+			name:     "linked identity wins over email",
+			existing: &entity.User{Username: "ana", Email: "ana@example.com", IsActive: true},
+			linked:   true,
+			identity: oidctest.Identity{Subject: "sub-ana", Email: "changed@example.com"},
+			check: func(t *testing.T, f *oidcFixture, resp *entity.LoginResponse) {
+				if resp.User.Username != "ana" {
+					t.Fatalf("logged in as %q, want ana", resp.User.Username)
+				}
+				if len(f.identities.identities) != 1 {
+					t.Fatalf("identity linked twice: %+v", f.identities.identities)
+				}
+			},
+		},
+		{
+			name:     "provisions buyer with free username",
+			existing: &entity.User{Username: "budi", Email: "other@example.com", IsActive: true},
+			identity: oidctest.Identity{Subject: "sub-budi", Email: "budi@example.com", EmailVerified: true, Name: "Budi"},
+			check: func(t *testing.T, f *oidcFixture, resp *entity.LoginResponse) {
+				if resp.User.Username != "budi1" {
+					t.Fatalf("username = %q, want budi1", resp.User.Username)
+				}
+				u := f.users.get(resp.User.ID)
+				if u.FullName != "Budi" || u.EmailVerifiedAt == nil || u.RoleID != f.service.defaultRoleID {
+					t.Fatalf("provisioned user = %+v", u)
+				}
+			},
+		},
+		{
+			name:     "inactive linked user is rejected",
+			existing: &entity.User{Username: "ana", Email: "ana@example.com", EmailVerifiedAt: &verifiedAt},
+			identity: oidctest.Identity{Subject: "sub-ana", Email: "ana@example.com", EmailVerified: true},
+			wantErr:  ErrInactiveAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			if tt.existing != nil {
+				u := f.users.add(tt.existing, "buyer")
+				if tt.linked {
+					f.identities.Create(&entity.UserIdentity{
+						ID: uuid.New(), UserID: u.ID, Provider: f.server.URL, Subject: tt.identity.Subject, CreatedAt: time.Now(),
+					})
+				}
+			}
+
+			resp, err := f.login(t, tt.identity)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Callback: %v", err)
+			}
+			if resp.Token == "" || resp.RefreshToken == "" {
+				t.Fatalf("missing tokens in %+v", resp)
+			}
+			tt.check(t, f, resp)
+		})
+	}
+}
+
+func TestOIDCCallbackRejectsBadStateAndToken(t *testing.T) {
+	id := oidctest.Identity{Subject: "sub-1", Email: "ana@example.com", EmailVerified: true}
+
+	t.Run("unknown state", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		authURL, _ := f.service.Start(context.Background())
+		code, _, _ := f.server.Authorize(authURL, id)
+		_, _, err := f.service.Callback(context.Background(), code, "forged-state", entity.ClientInfo{})
+		if !errors.Is(err, ErrOIDCInvalidState) {
+			t.Fatalf("err = %v, want ErrOIDCInvalidState", err)
+		}
+	})
+
+	t.Run("state cannot be replayed", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		authURL, _ := f.service.Start(context.Background())
+		code, state, _ := f.server.Authorize(authURL, id)
+		if _, _, err := f.service.Callback(context.Background(), code, state, entity.ClientInfo{}); err != nil {
+			t.Fatalf("first callback: %v", err)
+		}
+		_, _, err := f.service.Callback(context.Background(), code, state, entity.ClientInfo{})
+		if !errors.Is(err, ErrOIDCInvalidState) {
+			t.Fatalf("err = %v, want ErrOIDCInvalidState", err)
+		}
+	})
+
+	t.Run("forged id_token signature", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		f.server.ForgeSignature = true
+		if _, err := f.login(t, id); !errors.Is(err, ErrOIDCLoginFailed) {
+			t.Fatalf("err = %v, want ErrOIDCLoginFailed", err)
+		}
+	})
+
+	t.Run("nonce from another request", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		f.server.NonceOverride = "stale-nonce"
+		if _, err := f.login(t, id); !errors.Is(err, ErrOIDCLoginFailed) {
+			t.Fatalf("err = %v, want ErrOIDCLoginFailed", err)
+		}
+	})
+}
+
+func TestOIDCUsernameLookupError(t *testing.T) {
+	f := newOIDCFixture(t)
+	dbErr := errors.New("connection reset")
+	f.users.usernameErr = dbErr
+
+	_, err := f.login(t, oidctest.Identity{Subject: "sub-1", Email: "new@example.com", EmailVerified: true})
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("err = %v, want the repository error", err)
+	}
+	if len(f.identities.identities) != 0 {
+		t.Fatal("identity was linked despite the failed lookup")
+	}
+}
@@ -20,14 +20,19 @@ var ValidOrderStatuses = map[string]bool{
 type OrderService struct {
 	orderRepo repo.OrderRepository
 	shopRepo  repo.ShopRepository 
+	memberRepo repo.ShopMemberRepository
 	logRepo   mongorepo.LogRepository 
+	// jika true, marketplace hanya menampilkan item dari toko terverifikasi
+	requireVerifiedShop bool
 }
 
-func NewOrderService(orderRepo repo.OrderRepository, shopRepo repo.ShopRepository, logRepo mongorepo.LogRepository) *OrderService {
+func NewOrderService(orderRepo repo.OrderRepository, shopRepo repo.ShopRepository, memberRepo repo.ShopMemberRepository, logRepo mongorepo.LogRepository, requireVerifiedShop bool) *OrderService {
 	return &OrderService{
 		orderRepo: orderRepo,
 		shopRepo: shopRepo,
+		memberRepo: memberRepo,
 		logRepo: logRepo,
+		requireVerifiedShop: requireVerifiedShop,
 	}
 }
 
@@ -49,25 +54,30 @@ func (s *OrderService) createAndSaveNotification(userID uuid.UUID, title string,
 }
 
 // @Summary      Get Marketplace Items
-// @Description  Retrieves a list of active items from the marketplace, filtered by keyword, category, and price range.
+// @Description  Retrieves a list of active items from the marketplace, filtered by keyword, category, and price range. Items of shops on vacation are hidden. Each item carries a shop_verified badge; verified_only limits results to verified shops.
 // @Tags         Marketplace
 // @Accept       json
 // @Produce      json
 // @Param        keyword query string false "Search keyword"
-// @Param        category_id query string false "Filter by Category ID (UUID)"
+// @Param        category_id query string false "Filter by shop Category ID (UUID), including its sub-categories"
+// @Param        global_category_id query string false "Browse by global taxonomy category (UUID) across all shops, including its sub-categories"
 // @Param        min_price query number false "Minimum price filter"
 // @Param        max_price query number false "Maximum price filter"
+// @Param        verified_only query boolean false "Only items from verified shops"
 // @Param        limit query integer false "Limit (default 10)"
 // @Param        offset query integer false "Offset"
 // @Success      200  {array}   entity.Item
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /market/items [get]
 func (s *OrderService) GetMarketplaceItems(filter entity.ItemFilter) ([]entity.Item, error) {
+	if s.requireVerifiedShop {
+		filter.VerifiedOnly = true
+	}
 	return s.orderRepo.GetMarketItems(filter)
 }
 
 // @Summary      Get Item Detail
-// @Description  Retrieves detailed information for a single active item in the marketplace.
+// @Description  Retrieves detailed information for a single active item in the marketplace. Items of unverified shops are hidden while the verified-shop policy is on.
 // @Tags         Marketplace
 // @Accept       json
 // @Produce      json
@@ -81,12 +91,18 @@ func (s *OrderService) GetItemDetail(itemID uuid.UUID) (*entity.Item, error) {
 	if err != nil {
 		return nil, err
 	}
-	if item == nil || item.Status != "active" {
+	if item == nil || item.Status != "active" || !s.isListed(item) {
 		return nil, errors.New("item not found or inactive")
 	}
 	return item, nil
 }
 
+// isListed menerapkan REQUIRE_VERIFIED_SHOP_TO_LIST pada item yang diakses langsung lewat ID,
+// sama seperti filter di GetMarketplaceItems.
+func (s *OrderService) isListed(item *entity.Item) bool {
+	return !s.requireVerifiedShop || item.ShopVerified
+}
+
 // @Summary      Create New Order
 // @Description  Allows a Buyer to create a new order, performing stock validation and decrement within a transaction. Supports only single-shop orders currently.
 // @Tags         Orders
@@ -95,7 +111,7 @@ func (s *OrderService) GetItemDetail(itemID uuid.UUID) (*entity.Item, error) {
 // @Security     ApiKeyAuth
 // @Param        input body entity.CreateOrderInput true "Order details"
 // @Success      201  {object}  entity.Order
-// @Failure      400  {object}  map[string]interface{} "Validation error (stock, multi-shop)"
+// @Failure      400  {object}  map[string]interface{} "Validation error (stock, multi-shop, shop on vacation or closed, or shop not verified while the verified-shop policy is on)"
 // @Failure      403  {object}  map[string]interface{} "Forbidden (not buyer)"
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /orders [post]
@@ -105,7 +121,7 @@ func (s *OrderService) CreateOrder(buyerID uuid.UUID, input entity.CreateOrderIn
 	for _, itemInput := range input.Items {
 		item, err := s.orderRepo.GetItemForOrder(itemInput.ItemID)
 		if err != nil { return nil, errors.New("database error during item fetch") }
-		if item == nil || item.Status != "active" || item.Stock < itemInput.Quantity {
+		if item == nil || item.Status != "active" || !s.isListed(item) || item.Stock < itemInput.Quantity {
 			return nil, errors.New("invalid item, insufficient stock, or item inactive")
 		}
 
@@ -119,6 +135,13 @@ func (s *OrderService) CreateOrder(buyerID uuid.UUID, input entity.CreateOrderIn
 		return nil, errors.New("multi-shop orders are not supported in a single transaction yet")
 	}
 
+	// toko yang sedang libur atau di luar jam operasional tidak menerima order
+	for id := range shopItems {
+		if err := checkShopOpen(s.shopRepo, id); err != nil {
+			return nil, err
+		}
+	}
+
 	var shopID uuid.UUID
 	var itemsForOrder []entity.OrderItem
 	var totalPrice float64
@@ -162,7 +185,7 @@ func (s *OrderService) CreateOrder(buyerID uuid.UUID, input entity.CreateOrderIn
 }
 
 // @Summary      Update Order Status
-// @Description  Allows Seller or Admin to update the status of an order.
+// @Description  Allows shop members with the fulfil_orders shop permission (owner, manager, packer) or an admin to update the status of an order.
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
@@ -174,7 +197,7 @@ func (s *OrderService) CreateOrder(buyerID uuid.UUID, input entity.CreateOrderIn
 // @Failure      403  {object}  map[string]interface{} "Unauthorized"
 // @Failure      404  {object}  map[string]interface{} "Order not found"
 // @Router       /orders/{id}/status [patch]
-func (s *OrderService) UpdateOrderStatus(userID uuid.UUID, role string, orderID uuid.UUID, status string) (*entity.Order, error) {
+func (s *OrderService) UpdateOrderStatus(userID uuid.UUID, permissions []string, orderID uuid.UUID, status string) (*entity.Order, error) {
 	if !ValidOrderStatuses[status] {
 		return nil, errors.New("invalid status value")
 	}
@@ -182,12 +205,12 @@ func (s *OrderService) UpdateOrderStatus(userID uuid.UUID, role string, orderID
     order, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil { return nil, err }
 	if order == nil { return nil, errors.New("order not found") }
-	shop, _ := s.shopRepo.GetByUserID(userID)
-	isOwner := shop != nil && order.ShopID == shop.ID
-	isAdmin := role == "admin"
+	isStaff, err := canActForShop(s.memberRepo, userID, order.ShopID, entity.ShopPermFulfilOrders)
+	if err != nil { return nil, err }
+	isAdmin := HasPermission(permissions, entity.PermOrderManageAny)
 	
-	if !isOwner && !isAdmin {
-		return nil, errors.New("unauthorized: you are not the shop owner or admin")
+	if !isStaff && !isAdmin {
+		return nil, errors.New("unauthorized: you are not allowed to fulfil orders of this shop")
 	}
 	if err := s.orderRepo.UpdateOrderStatus(orderID, status); err != nil { return nil, err }
     order.Status = status 
@@ -202,7 +225,7 @@ func (s *OrderService) UpdateOrderStatus(userID uuid.UUID, role string, orderID
 }
 
 // @Summary      Input Shipping Receipt
-// @Description  Allows Seller or Admin to input courier and receipt number, automatically setting status to 'shipped'.
+// @Description  Allows shop members with the fulfil_orders shop permission or an admin to input courier and receipt number, automatically setting status to 'shipped'.
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
@@ -214,16 +237,16 @@ func (s *OrderService) UpdateOrderStatus(userID uuid.UUID, role string, orderID
 // @Failure      403  {object}  map[string]interface{} "Unauthorized"
 // @Failure      404  {object}  map[string]interface{} "Order not found"
 // @Router       /orders/{id}/shipping [post]
-func (s *OrderService) InputShippingReceipt(userID uuid.UUID, role string, orderID uuid.UUID, input entity.InputShippingReceiptInput) (*entity.Order, error) {
+func (s *OrderService) InputShippingReceipt(userID uuid.UUID, permissions []string, orderID uuid.UUID, input entity.InputShippingReceiptInput) (*entity.Order, error) {
     order, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil { return nil, err }
 	if order == nil { return nil, errors.New("order not found") }
 
-	shop, _ := s.shopRepo.GetByUserID(userID)
-	isOwner := shop != nil && order.ShopID == shop.ID
-	isAdmin := role == "admin"
+	isStaff, err := canActForShop(s.memberRepo, userID, order.ShopID, entity.ShopPermFulfilOrders)
+	if err != nil { return nil, err }
+	isAdmin := HasPermission(permissions, entity.PermOrderManageAny)
 	
-	if !isOwner && !isAdmin { return nil, errors.New("unauthorized") }
+	if !isStaff && !isAdmin { return nil, errors.New("unauthorized") }
 
 	if err := s.orderRepo.UpdateOrderShipment(orderID, input.ShippingCourier, input.ShippingReceipt); err != nil { return nil, err }
     order.ShippingCourier = input.ShippingCourier 
@@ -239,7 +262,7 @@ func (s *OrderService) InputShippingReceipt(userID uuid.UUID, role string, order
 }
 
 // @Summary      Get Order Tracking Details
-// @Description  Retrieves order details and associated items for tracking purposes (Buyer or Admin access).
+// @Description  Retrieves order details and associated items for tracking purposes (Buyer, staff of the selling shop, or Admin access).
 // @Tags         Orders
 // @Accept       json
 // @Produce      json
@@ -249,14 +272,19 @@ func (s *OrderService) InputShippingReceipt(userID uuid.UUID, role string, order
 // @Failure      403  {object}  map[string]interface{} "Unauthorized"
 // @Failure      404  {object}  map[string]interface{} "Order not found"
 // @Router       /orders/{id}/tracking [get]
-func (s *OrderService) GetOrderTracking(userID uuid.UUID, role string, orderID uuid.UUID) (*entity.Order, []entity.OrderItem, error) {
+func (s *OrderService) GetOrderTracking(userID uuid.UUID, permissions []string, orderID uuid.UUID) (*entity.Order, []entity.OrderItem, error) {
 	order, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil { return nil, nil, err }
 	if order == nil { return nil, nil, errors.New("order not found") }
 
-	isAdmin := role == "admin"
+	isAdmin := HasPermission(permissions, entity.PermOrderReadAny)
 	isBuyer := order.BuyerID == userID
-	if !isBuyer && !isAdmin { return nil, nil, errors.New("unauthorized: access denied") }
+	if !isBuyer && !isAdmin {
+		// staf toko yang memproses order juga perlu melihat item-nya
+		isStaff, err := canActForShop(s.memberRepo, userID, order.ShopID, entity.ShopPermFulfilOrders)
+		if err != nil { return nil, nil, err }
+		if !isStaff { return nil, nil, errors.New("unauthorized: access denied") }
+	}
 	items, err := s.orderRepo.GetOrderItems(orderID)
 	if err != nil { return order, nil, err }
 
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifiedShopPolicyOnItemAccess(t *testing.T) {
+	tests := []struct {
+		name                string
+		requireVerifiedShop bool
+		verificationStatus  string
+		wantVisible         bool
+	}{
+		{"policy on, verified shop", true, entity.ShopVerified, true},
+		{"policy on, unverified shop", true, entity.ShopUnverified, false},
+		{"policy off, unverified shop", false, entity.ShopUnverified, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, tt.requireVerifiedShop)
+			f.shop.VerificationStatus = tt.verificationStatus
+			item := f.addItem(entity.Item{Name: "Kursi", Price: 50000, Stock: 3, Status: entity.ItemStatusActive})
+
+			orders := &fakeOrderRepo{shops: f.shops, items: f.items}
+			svc := NewOrderService(orders, f.shops, f.members, f.logs, tt.requireVerifiedShop)
+
+			detail, err := svc.GetItemDetail(item.ID)
+			if gotVisible := err == nil; gotVisible != tt.wantVisible {
+				t.Fatalf("GetItemDetail error = %v, want visible %v", err, tt.wantVisible)
+			}
+			wantBadge := tt.verificationStatus == entity.ShopVerified
+			if err == nil && detail.ShopVerified != wantBadge {
+				t.Errorf("ShopVerified = %v, want %v", detail.ShopVerified, wantBadge)
+			}
+
+			_, err = svc.CreateOrder(uuid.New(), entity.CreateOrderInput{
+				Items:           []entity.OrderItemInput{{ItemID: item.ID, Quantity: 1}},
+				ShippingAddress: "Jl. Melati 1",
+				ShippingCourier: "jne",
+			})
+			if gotOrdered := err == nil; gotOrdered != tt.wantVisible {
+				t.Fatalf("CreateOrder error = %v, want ordered %v", err, tt.wantVisible)
+			}
+			wantOrders := 0
+			if tt.wantVisible {
+				wantOrders = 1
+			}
+			if len(orders.orders) != wantOrders {
+				t.Errorf("orders saved = %d, want %d", len(orders.orders), wantOrders)
+			}
+		})
+	}
+}
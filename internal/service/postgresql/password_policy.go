@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"home-market/internal/config"
+)
+
+// PasswordPolicyError dikembalikan saat password baru tidak memenuhi kebijakan.
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy: " + e.Reason
+}
+
+// commonPasswords adalah daftar bawaan password yang paling sering bocor;
+// daftar lengkap bisa ditambahkan lewat PASSWORD_BREACHED_LIST_FILE.
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "1234567890", "12345", "1234567", "111111", "123123",
+	"000000", "654321", "666666", "121212", "112233", "987654321", "123321", "11111111",
+	"password", "password1", "password123", "passw0rd", "qwerty", "qwerty123", "qwertyuiop",
+	"abc123", "abcd1234", "1q2w3e4r", "1qaz2wsx", "zaq12wsx", "asdfghjkl", "iloveyou",
+	"admin", "admin123", "administrator", "welcome", "welcome1", "letmein", "monkey",
+	"dragon", "football", "baseball", "sunshine", "princess", "superman", "starwars",
+	"trustno1", "master", "shadow", "michael", "jennifer", "computer", "whatever",
+	"indonesia", "bismillah", "sayang", "rahasia", "katasandi", "homemarket",
+}
+
+type PasswordPolicy struct {
+	minLength int
+	maxLength int
+	breached  map[string]struct{}
+}
+
+// NewPasswordPolicy memuat daftar password bocor bawaan dan dari file (jika ada).
+func NewPasswordPolicy(cfg config.PasswordConfig) (*PasswordPolicy, error) {
+	maxLength := cfg.MaxLength
+	// bcrypt hanya memakai 72 byte pertama
+	if cfg.Algorithm == config.PasswordAlgorithmBcrypt && maxLength > 72 {
+		maxLength = 72
+	}
+
+	p := &PasswordPolicy{
+		minLength: cfg.MinLength,
+		maxLength: maxLength,
+		breached:  make(map[string]struct{}, len(commonPasswords)),
+	}
+	for _, pw := range commonPasswords {
+		p.breached[pw] = struct{}{}
+	}
+
+	if cfg.BreachedListFile == "" {
+		return p, nil
+	}
+	f, err := os.Open(cfg.BreachedListFile)
+	if err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.ToLower(strings.TrimSpace(scanner.Text())); line != "" {
+			p.breached[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("password policy: %w", err)
+	}
+	return p, nil
+}
+
+// Validate memeriksa panjang, daftar password bocor, dan larangan memakai username/email.
+func (p *PasswordPolicy) Validate(password, username, email string) error {
+	length := len([]rune(password))
+	if length < p.minLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("must be at least %d characters", p.minLength)}
+	}
+	if len(password) > p.maxLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("must be at most %d bytes", p.maxLength)}
+	}
+
+	lower := strings.ToLower(password)
+	if _, found := p.breached[lower]; found {
+		return &PasswordPolicyError{Reason: "appears in a list of breached or common passwords"}
+	}
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if len(username) >= 3 && strings.Contains(lower, username) {
+		return &PasswordPolicyError{Reason: "must not contain the username"}
+	}
+	if local := strings.ToLower(strings.SplitN(email, "@", 2)[0]); len(local) >= 3 && strings.Contains(lower, local) {
+		return &PasswordPolicyError{Reason: "must not contain the email address"}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"home-market/internal/config"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breached, []byte("Kucing-Oren-99\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPasswordPolicy(config.PasswordConfig{
+		Algorithm: config.PasswordAlgorithmArgon2id, MinLength: 8, MaxLength: 64, BreachedListFile: breached,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "acceptable", password: "rahasia-kuat-1"},
+		{name: "too short", password: "pendek", wantErr: true},
+		{name: "length counts characters, not bytes", password: "kata🔑sandi"},
+		{name: "too long", password: strings.Repeat("a", 65), wantErr: true},
+		{name: "built-in common password", password: "Password123", wantErr: true},
+		{name: "password from the breached list file", password: "kucing-oren-99", wantErr: true},
+		{name: "contains the username", password: "ana-rahasia-kuat", wantErr: true},
+		{name: "contains the email local part", password: "xx-anastasia-xx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, "Ana", "anastasia@example.com")
+			var policyErr *PasswordPolicyError
+			if (err != nil) != tt.wantErr || err != nil && !errors.As(err, &policyErr) {
+				t.Fatalf("Validate(%q) = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyBcryptLimit(t *testing.T) {
+	policy, err := NewPasswordPolicy(config.PasswordConfig{Algorithm: config.PasswordAlgorithmBcrypt, MinLength: 8, MaxLength: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bcrypt hanya memakai 72 byte pertama, jadi batas atas diturunkan
+	if err := policy.Validate(strings.Repeat("x", 73), "", ""); err == nil {
+		t.Fatal("73-byte password accepted with bcrypt")
+	}
+	if _, err := NewPasswordPolicy(config.PasswordConfig{BreachedListFile: "/does/not/exist"}); err == nil {
+		t.Fatal("missing breached list file was ignored")
+	}
+}
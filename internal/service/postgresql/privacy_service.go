@@ -0,0 +1,296 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+	repo "home-market/internal/repository/postgresql"
+	"home-market/pkg"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeletionAlreadyRequested = errors.New("account deletion has already been requested")
+	ErrNoDeletionRequest        = errors.New("no pending account deletion request")
+)
+
+type PrivacyService struct {
+	userRepo         repo.UserRepository
+	privacyRepo      repo.PrivacyRepository
+	sessionRepo      repo.SessionRepository
+	orderRepo        repo.OrderRepository
+	offerRepo        repo.OfferRepository
+	shopRepo         repo.ShopRepository
+	verificationRepo repo.ShopVerificationRepository
+	logRepo          mongorepo.LogRepository
+	sessionGuard     *SessionGuard
+	gracePeriod      time.Duration
+	kycStorageDir    string // direktori file dokumen KYC (SHOP_KYC_STORAGE_DIR)
+}
+
+func NewPrivacyService(
+	userRepo repo.UserRepository,
+	privacyRepo repo.PrivacyRepository,
+	sessionRepo repo.SessionRepository,
+	orderRepo repo.OrderRepository,
+	offerRepo repo.OfferRepository,
+	shopRepo repo.ShopRepository,
+	verificationRepo repo.ShopVerificationRepository,
+	logRepo mongorepo.LogRepository,
+	sessionGuard *SessionGuard,
+	gracePeriod time.Duration,
+	kycStorageDir string,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:         userRepo,
+		privacyRepo:      privacyRepo,
+		sessionRepo:      sessionRepo,
+		orderRepo:        orderRepo,
+		offerRepo:        offerRepo,
+		shopRepo:         shopRepo,
+		verificationRepo: verificationRepo,
+		logRepo:          logRepo,
+		sessionGuard:     sessionGuard,
+		gracePeriod:      gracePeriod,
+		kycStorageDir:    kycStorageDir,
+	}
+}
+
+// @Summary      Export Personal Data
+// @Description  Exports everything tied to the current user: account, roles, shop with its verification (KYC) submissions, orders with items, offers, notifications, status history, login sessions (IP and device) and security activity logs (login and 2FA lockouts). Returns JSON, or a ZIP with one JSON file per section plus the KYC document files when format=zip.
+// @Tags         Me
+// @Produce      json
+// @Produce      application/zip
+// @Security     ApiKeyAuth
+// @Param        format  query  string  false  "json (default) or zip"
+// @Success      200  {object}  entity.DataExport
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /me/export [get]
+func (s *PrivacyService) ExportData(userID uuid.UUID) (*entity.DataExport, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	roles, err := s.userRepo.GetRoleNamesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	shop, err := s.shopRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	verifications := []entity.ShopVerification{}
+	if shop != nil {
+		verifications, err = s.verificationRepo.ListByShop(shop.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := s.orderRepo.GetOrdersByBuyerID(userID)
+	if err != nil {
+		return nil, err
+	}
+	exportOrders := make([]entity.ExportOrder, 0, len(orders))
+	orderIDs := make([]string, 0, len(orders))
+	for _, order := range orders {
+		items, err := s.orderRepo.GetOrderItems(order.ID)
+		if err != nil {
+			return nil, err
+		}
+		exportOrders = append(exportOrders, entity.ExportOrder{Order: order, Items: items})
+		orderIDs = append(orderIDs, order.ID.String())
+	}
+
+	offersGiven, err := s.offerRepo.GetOffersByGiverID(userID)
+	if err != nil {
+		return nil, err
+	}
+	offersReceived, err := s.offerRepo.GetOffersBySellerID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := s.logRepo.ListNotificationsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.logRepo.ListHistoryStatus(orderIDs, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionRepo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	activityLogs, err := s.logRepo.ListActivityLogsByUser(userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.DataExport{
+		ExportedAt:        time.Now(),
+		User:              *user,
+		Roles:             roles,
+		Shop:              shop,
+		ShopVerifications: verifications,
+		Orders:            exportOrders,
+		OffersGiven:       offersGiven,
+		OffersReceived:    offersReceived,
+		Notifications:     notifications,
+		StatusHistory:     history,
+		Sessions:          sessions,
+		ActivityLogs:      activityLogs,
+	}, nil
+}
+
+// @Summary      Request Account Deletion
+// @Description  Schedules deletion of the current account after a grace period. Until then the account keeps working and the request can be cancelled. On execution, personal data in orders is anonymized (financial records are kept), notifications and security activity logs are removed, session IPs and devices are erased, the account is deactivated and its shop is closed (items archived, API keys revoked, staff removed, KYC documents and their files deleted).
+// @Tags         Me
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.DeleteAccountInput true "Current password"
+// @Success      202  {object}  entity.AccountDeletionRequest
+// @Failure      401  {object}  map[string]interface{} "Current password is incorrect"
+// @Failure      409  {object}  map[string]interface{} "Deletion already requested"
+// @Router       /me/deletion [post]
+func (s *PrivacyService) RequestDeletion(userID uuid.UUID, input entity.DeleteAccountInput) (*entity.AccountDeletionRequest, error) {
+	current, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	// GetByID tidak memuat password_hash
+	user, _, err := s.userRepo.GetByUsername(current.Username)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !utils.CheckPasswordHash(input.Password, user.PasswordHash) {
+		return nil, ErrWrongPassword
+	}
+
+	pending, err := s.privacyRepo.GetPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, ErrDeletionAlreadyRequested
+	}
+
+	now := time.Now()
+	req := &entity.AccountDeletionRequest{
+		ID:           uuid.New(),
+		UserID:       userID,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.gracePeriod),
+	}
+	if err := s.privacyRepo.CreateDeletionRequest(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// @Summary      Get Account Deletion Status
+// @Description  Returns the pending deletion request of the current user, if any.
+// @Tags         Me
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  entity.AccountDeletionRequest
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /me/deletion [get]
+func (s *PrivacyService) GetDeletion(userID uuid.UUID) (*entity.AccountDeletionRequest, error) {
+	req, err := s.privacyRepo.GetPendingDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, ErrNoDeletionRequest
+	}
+	return req, nil
+}
+
+// @Summary      Cancel Account Deletion
+// @Description  Cancels the pending deletion request of the current user during the grace period.
+// @Tags         Me
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /me/deletion [delete]
+func (s *PrivacyService) CancelDeletion(userID uuid.UUID) error {
+	cancelled, err := s.privacyRepo.CancelDeletion(userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrNoDeletionRequest
+	}
+	return nil
+}
+
+// ProcessDueDeletions mengeksekusi permintaan hapus akun yang masa tenggangnya sudah lewat.
+func (s *PrivacyService) ProcessDueDeletions() (int, error) {
+	due, err := s.privacyRepo.ListDueDeletions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, req := range due {
+		// notifikasi dan activity log dihapus lebih dulu; jika anonimisasi gagal,
+		// permintaan tetap pending dan diulang pada putaran berikutnya
+		if _, err := s.logRepo.DeleteNotificationsByUser(req.UserID); err != nil {
+			log.Printf("Warning: failed to delete notifications of user %s: %v", req.UserID.String(), err)
+			continue
+		}
+		if _, err := s.logRepo.DeleteActivityLogsByUser(req.UserID.String()); err != nil {
+			log.Printf("Warning: failed to delete activity logs of user %s: %v", req.UserID.String(), err)
+			continue
+		}
+		documentPaths, err := s.privacyRepo.AnonymizeUser(req.ID, req.UserID)
+		if err != nil {
+			log.Printf("Warning: failed to anonymize user %s: %v", req.UserID.String(), err)
+			continue
+		}
+		s.sessionGuard.Invalidate(req.UserID)
+		s.removeDocumentFiles(documentPaths)
+		processed++
+	}
+	return processed, nil
+}
+
+// removeDocumentFiles menghapus file KYC yang barisnya sudah dihapus. Kegagalan hanya
+// dicatat karena data di database sudah dianonimkan.
+func (s *PrivacyService) removeDocumentFiles(paths []string) {
+	for _, path := range paths {
+		err := os.Remove(filepath.Join(s.kycStorageDir, filepath.Base(path)))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove KYC document %s: %v", path, err)
+		}
+	}
+}
+
+// StartDeletionWorker menjalankan ProcessDueDeletions secara berkala di background.
+func (s *PrivacyService) StartDeletionWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := s.ProcessDueDeletions()
+			if err != nil {
+				log.Printf("Warning: account deletion sweep failed: %v", err)
+			} else if n > 0 {
+				log.Printf("account deletion sweep: %d account(s) anonymized", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
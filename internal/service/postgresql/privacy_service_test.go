@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// newPrivacyFixture merangkai PrivacyService untuk satu user tanpa toko, order, maupun offer,
+// dengan satu sesi dan activity log lockout miliknya serta milik user lain.
+func newPrivacyFixture(t *testing.T) (*PrivacyService, *fakePrivacyRepo, *fakeLogRepo, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	users := newFakeUserRepo()
+	user := users.add(&entity.User{Username: "ana"}, "buyer")
+	other := users.add(&entity.User{Username: "budi"}, "buyer")
+
+	sessions := newFakeSessionRepo()
+	sessions.Create(&entity.UserSession{
+		ID: uuid.New(), UserID: user.ID, IPAddress: "203.0.113.7", UserAgent: "Firefox", ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	logs := &fakeLogRepo{}
+	for _, id := range []uuid.UUID{user.ID, other.ID} {
+		logs.SaveActivityLog(&entity.ActivityLog{UserID: id.String(), Action: "login_locked", IPAddress: "203.0.113.7", Device: "Firefox"})
+		logs.SaveActivityLog(&entity.ActivityLog{UserID: id.String(), Action: "mfa_locked", IPAddress: "203.0.113.7", Device: "Firefox"})
+	}
+
+	privacy := &fakePrivacyRepo{}
+	shops := &fakeShopRepo{shops: make(map[uuid.UUID]*entity.Shop)}
+	svc := NewPrivacyService(
+		users, privacy, sessions, &fakeOrderRepo{}, &fakeOfferRepo{offers: make(map[uuid.UUID]*entity.Offer)},
+		shops, nil, logs, NewSessionGuard(users, sessions), time.Hour, t.TempDir(),
+	)
+	return svc, privacy, logs, user.ID, other.ID
+}
+
+func TestExportDataIncludesSessionsAndActivityLogs(t *testing.T) {
+	svc, _, _, userID, _ := newPrivacyFixture(t)
+
+	export, err := svc.ExportData(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Sessions) != 1 || export.Sessions[0].IPAddress != "203.0.113.7" {
+		t.Errorf("sessions = %+v, want the user's session with its IP", export.Sessions)
+	}
+	if len(export.ActivityLogs) != 2 {
+		t.Fatalf("activity logs = %d, want 2", len(export.ActivityLogs))
+	}
+	for _, a := range export.ActivityLogs {
+		if a.UserID != userID.String() {
+			t.Errorf("exported activity log of user %s", a.UserID)
+		}
+	}
+}
+
+func TestProcessDueDeletionsRemovesActivityLogs(t *testing.T) {
+	svc, privacy, logs, userID, otherID := newPrivacyFixture(t)
+	privacy.due = []entity.AccountDeletionRequest{{ID: uuid.New(), UserID: userID}}
+
+	processed, err := svc.ProcessDueDeletions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != 1 || len(privacy.anonymized) != 1 {
+		t.Fatalf("processed = %d, anonymized = %v", processed, privacy.anonymized)
+	}
+
+	if left, _ := logs.ListActivityLogsByUser(userID.String()); len(left) != 0 {
+		t.Errorf("activity logs of deleted user left = %d, want 0", len(left))
+	}
+	if kept, _ := logs.ListActivityLogsByUser(otherID.String()); len(kept) != 2 {
+		t.Errorf("activity logs of other user = %d, want 2", len(kept))
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound              = errors.New("role not found")
+	ErrRoleAlreadyHeld           = errors.New("user already has this role")
+	ErrApplicationPending        = errors.New("an application for this role is already pending")
+	ErrRoleApplicationNotFound   = errors.New("role application not found")
+	ErrRoleApplicationNotPending = errors.New("role application is not pending")
+)
+
+type RoleApplicationService struct {
+	appRepo      repo.RoleApplicationRepository
+	roleRepo     repo.RoleRepository
+	userRepo     repo.UserRepository
+	logRepo      mongorepo.LogRepository
+	sessionGuard *SessionGuard
+}
+
+func NewRoleApplicationService(
+	appRepo repo.RoleApplicationRepository,
+	roleRepo repo.RoleRepository,
+	userRepo repo.UserRepository,
+	logRepo mongorepo.LogRepository,
+	sessionGuard *SessionGuard,
+) *RoleApplicationService {
+	return &RoleApplicationService{
+		appRepo:      appRepo,
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		logRepo:      logRepo,
+		sessionGuard: sessionGuard,
+	}
+}
+
+// @Summary      Apply for a Role
+// @Description  Submits a request to become a seller or giver. An admin must approve it before the role is granted.
+// @Tags         Role Applications
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.CreateRoleApplicationInput true "Requested role and supporting information"
+// @Success      201  {object}  entity.RoleApplication
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Role already held or application pending"
+// @Router       /role-applications [post]
+func (s *RoleApplicationService) Submit(userID uuid.UUID, input entity.CreateRoleApplicationInput) (*entity.RoleApplication, error) {
+	role, err := s.roleRepo.GetByName(input.Role)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	roles, err := s.userRepo.GetRoleNamesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if HasRole(roles, role.Name) {
+		return nil, ErrRoleAlreadyHeld
+	}
+
+	pending, err := s.appRepo.HasPending(userID, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrApplicationPending
+	}
+
+	app := &entity.RoleApplication{
+		ID:        uuid.New(),
+		UserID:    userID,
+		RoleID:    role.ID,
+		RoleName:  role.Name,
+		Reason:    input.Reason,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.appRepo.Create(app); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// @Summary      My Role Applications
+// @Description  Lists the role applications submitted by the current user.
+// @Tags         Role Applications
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.RoleApplication
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /role-applications/my [get]
+func (s *RoleApplicationService) ListMine(userID uuid.UUID) ([]entity.RoleApplication, error) {
+	return s.appRepo.ListByUserID(userID)
+}
+
+// @Summary      List Role Applications
+// @Description  Lists role applications for review, optionally filtered by status (Admin access only).
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        status query string false "pending, approved or rejected"
+// @Success      200  {array}   entity.RoleApplication
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /admin/role-applications [get]
+func (s *RoleApplicationService) List(status string) ([]entity.RoleApplication, error) {
+	return s.appRepo.ListByStatus(status)
+}
+
+// @Summary      Approve Role Application
+// @Description  Adds the requested role to the applicant's roles and notifies them (Admin access only).
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Application ID"
+// @Param        input body entity.ReviewRoleApplicationInput false "Optional review note"
+// @Success      200  {object}  entity.RoleApplication
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Application is not pending"
+// @Router       /admin/role-applications/{id}/approve [post]
+func (s *RoleApplicationService) Approve(adminID uuid.UUID, appID uuid.UUID, note string) (*entity.RoleApplication, error) {
+	app, err := s.getPending(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.markReviewed(app, adminID, "approved", note, s.appRepo.Approve); err != nil {
+		return nil, err
+	}
+	s.sessionGuard.Invalidate(app.UserID)
+
+	saveNotification(
+		s.logRepo, app.UserID, "Pengajuan Role Disetujui",
+		fmt.Sprintf("Pengajuan Anda untuk menjadi %s telah disetujui. Silakan login ulang untuk menggunakan fitur baru.", app.RoleName),
+		"role_application", app.ID,
+	)
+
+	return app, nil
+}
+
+// @Summary      Reject Role Application
+// @Description  Rejects a pending role application and notifies the applicant (Admin access only).
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Application ID"
+// @Param        input body entity.ReviewRoleApplicationInput false "Optional review note"
+// @Success      200  {object}  entity.RoleApplication
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Application is not pending"
+// @Router       /admin/role-applications/{id}/reject [post]
+func (s *RoleApplicationService) Reject(adminID uuid.UUID, appID uuid.UUID, note string) (*entity.RoleApplication, error) {
+	app, err := s.getPending(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.markReviewed(app, adminID, "rejected", note, s.appRepo.UpdateReview); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Pengajuan Anda untuk menjadi %s ditolak.", app.RoleName)
+	if note != "" {
+		message += " Catatan: " + note
+	}
+	saveNotification(s.logRepo, app.UserID, "Pengajuan Role Ditolak", message, "role_application", app.ID)
+
+	return app, nil
+}
+
+func (s *RoleApplicationService) getPending(appID uuid.UUID) (*entity.RoleApplication, error) {
+	app, err := s.appRepo.GetByID(appID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, ErrRoleApplicationNotFound
+	}
+	if app.Status != "pending" {
+		return nil, ErrRoleApplicationNotPending
+	}
+	return app, nil
+}
+
+// markReviewed menyimpan review lewat save; save mengembalikan false jika pengajuan
+// sudah direview admin lain sejak getPending.
+func (s *RoleApplicationService) markReviewed(
+	app *entity.RoleApplication, adminID uuid.UUID, status string, note string,
+	save func(*entity.RoleApplication) (bool, error),
+) error {
+	now := time.Now()
+	app.Status = status
+	app.ReviewedBy = &adminID
+	app.ReviewNote = note
+	app.ReviewedAt = &now
+	app.UpdatedAt = now
+
+	ok, err := save(app)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRoleApplicationNotPending
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestReviewRoleApplicationOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      string
+		second     string
+		wantStatus string
+		wantRoles  int
+	}{
+		{"approve after approve", "approve", "approve", "approved", 2},
+		{"reject after approve", "approve", "reject", "approved", 2},
+		{"approve after reject", "reject", "approve", "rejected", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepo()
+			applicant := users.add(&entity.User{Username: "budi"}, "buyer")
+			sellerRoleID := uuid.New()
+			users.roleNames[sellerRoleID] = "seller"
+
+			apps := &fakeRoleApplicationRepo{
+				apps:     make(map[uuid.UUID]*entity.RoleApplication),
+				snapshot: make(map[uuid.UUID]entity.RoleApplication),
+				users:    users,
+			}
+			app := &entity.RoleApplication{
+				ID: uuid.New(), UserID: applicant.ID, RoleID: sellerRoleID, RoleName: "seller", Status: "pending",
+			}
+			apps.Create(app)
+
+			svc := NewRoleApplicationService(apps, nil, users, &fakeLogRepo{}, NewSessionGuard(users, newFakeSessionRepo()))
+			review := func(action string) error {
+				var err error
+				if action == "approve" {
+					_, err = svc.Approve(uuid.New(), app.ID, "")
+				} else {
+					_, err = svc.Reject(uuid.New(), app.ID, "")
+				}
+				return err
+			}
+			if err := review(tt.first); err != nil {
+				t.Fatalf("first review: %v", err)
+			}
+
+			// admin kedua masih melihat pengajuan pending dari bacaan sebelumnya
+			if err := review(tt.second); !errors.Is(err, ErrRoleApplicationNotPending) {
+				t.Fatalf("second review: got %v, want ErrRoleApplicationNotPending", err)
+			}
+
+			if got := len(users.roles[applicant.ID]); got != tt.wantRoles {
+				t.Errorf("roles = %v, want %d roles", users.roles[applicant.ID], tt.wantRoles)
+			}
+			if got := apps.apps[app.ID].Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"strings"
+
+	entity "home-market/internal/domain"
+)
+
+// HasRole memeriksa apakah role tertentu ada di dalam kumpulan role user.
+func HasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission memeriksa apakah permission tertentu ada di dalam permission user.
+func HasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasShopPermission memeriksa permission shop-scoped milik role anggota toko.
+func HasShopPermission(shopRole string, permission string) bool {
+	return HasPermission(entity.ShopRolePermissions[shopRole], permission)
+}
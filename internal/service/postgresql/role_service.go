@@ -0,0 +1,433 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNameTaken       = errors.New("role name already exists")
+	ErrSystemRole          = errors.New("built-in roles cannot be renamed or deleted")
+	ErrRoleInUse           = errors.New("role is still assigned to users")
+	ErrRoleNotHeld         = errors.New("user does not have this role")
+	ErrPrimaryRole         = errors.New("cannot remove the user's primary role")
+	ErrPermissionNotFound  = errors.New("permission not found")
+	ErrPermissionNameTaken = errors.New("permission name already exists")
+	ErrSystemPermission    = errors.New("built-in permissions cannot be deleted")
+)
+
+// Role dan permission yang direferensikan langsung oleh kode, tidak boleh dihapus/diubah namanya.
+var (
+	systemRoles = map[string]bool{"buyer": true, "seller": true, "giver": true, "admin": true}
+
+	systemPermissions = map[string]bool{
+		entity.PermUserRead:              true,
+		entity.PermUserManage:            true,
+		entity.PermItemModerate:          true,
+		entity.PermOrderCreate:           true,
+		entity.PermOrderUpdateStatus:     true,
+		entity.PermOrderManageAny:        true,
+		entity.PermOrderReadAny:          true,
+		entity.PermOfferCreate:           true,
+		entity.PermRoleManage:            true,
+		entity.PermRoleApplicationReview: true,
+		entity.PermShopVerify:            true,
+		entity.PermTaxonomyManage:        true,
+		entity.PermShopManage:            true,
+		entity.PermShopStaff:             true,
+	}
+)
+
+type RoleService struct {
+	roleRepo     repo.RoleRepository
+	permRepo     repo.PermissionRepository
+	userRepo     repo.UserRepository
+	sessionGuard *SessionGuard
+}
+
+func NewRoleService(roleRepo repo.RoleRepository, permRepo repo.PermissionRepository, userRepo repo.UserRepository, sessionGuard *SessionGuard) *RoleService {
+	return &RoleService{
+		roleRepo:     roleRepo,
+		permRepo:     permRepo,
+		userRepo:     userRepo,
+		sessionGuard: sessionGuard,
+	}
+}
+
+// @Summary      List Roles
+// @Description  Lists all roles in the system (requires role:manage).
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.Role
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /admin/roles [get]
+func (s *RoleService) ListRoles() ([]entity.Role, error) {
+	return s.roleRepo.List()
+}
+
+// @Summary      Create Role
+// @Description  Creates a new role without any permissions (requires role:manage).
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.RoleInput true "Role name and description"
+// @Success      201  {object}  entity.Role
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/roles [post]
+func (s *RoleService) CreateRole(input entity.RoleInput) (*entity.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	existing, err := s.roleRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrRoleNameTaken
+	}
+
+	role := &entity.Role{ID: uuid.New(), Name: name, Description: input.Description}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// @Summary      Update Role
+// @Description  Renames a role or changes its description. Built-in roles can only change their description.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Role ID"
+// @Param        input body entity.RoleInput true "Role name and description"
+// @Success      200  {object}  entity.Role
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/roles/{id} [patch]
+func (s *RoleService) UpdateRole(roleID uuid.UUID, input entity.RoleInput) (*entity.Role, error) {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	if name != role.Name {
+		if systemRoles[role.Name] {
+			return nil, ErrSystemRole
+		}
+		existing, err := s.roleRepo.GetByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrRoleNameTaken
+		}
+	}
+
+	renamed := name != role.Name
+	role.Name = name
+	role.Description = input.Description
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+
+	// nama role ikut tertanam di JWT
+	if renamed {
+		if err := s.refreshRoleHolders(role.ID); err != nil {
+			return nil, err
+		}
+	}
+	return role, nil
+}
+
+// @Summary      Delete Role
+// @Description  Deletes a custom role that is no longer assigned to any user.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Role ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/roles/{id} [delete]
+func (s *RoleService) DeleteRole(roleID uuid.UUID) error {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+	if systemRoles[role.Name] {
+		return ErrSystemRole
+	}
+
+	count, err := s.roleRepo.CountUsers(roleID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	return s.roleRepo.Delete(roleID)
+}
+
+// @Summary      List Role Permissions
+// @Description  Lists the permissions granted to a role.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Role ID"
+// @Success      200  {array}   entity.Permission
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/roles/{id}/permissions [get]
+func (s *RoleService) ListRolePermissions(roleID uuid.UUID) ([]entity.Permission, error) {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return s.roleRepo.GetPermissions(roleID)
+}
+
+// @Summary      Grant Permission to Role
+// @Description  Grants a permission to a role. Holders of the role must use a fresh token.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id            path  string  true  "Role ID"
+// @Param        permissionId  path  string  true  "Permission ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/roles/{id}/permissions/{permissionId} [put]
+func (s *RoleService) GrantPermission(roleID, permissionID uuid.UUID) error {
+	if err := s.ensureRoleAndPermission(roleID, permissionID); err != nil {
+		return err
+	}
+	if err := s.roleRepo.GrantPermission(roleID, permissionID); err != nil {
+		return err
+	}
+	return s.refreshRoleHolders(roleID)
+}
+
+// @Summary      Revoke Permission from Role
+// @Description  Removes a permission from a role. Holders of the role must use a fresh token.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id            path  string  true  "Role ID"
+// @Param        permissionId  path  string  true  "Permission ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) RevokePermission(roleID, permissionID uuid.UUID) error {
+	if err := s.ensureRoleAndPermission(roleID, permissionID); err != nil {
+		return err
+	}
+	revoked, err := s.roleRepo.RevokePermission(roleID, permissionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return nil
+	}
+	return s.refreshRoleHolders(roleID)
+}
+
+// @Summary      List Permissions
+// @Description  Lists all permissions that can be granted to roles.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.Permission
+// @Router       /admin/permissions [get]
+func (s *RoleService) ListPermissions() ([]entity.Permission, error) {
+	return s.permRepo.List()
+}
+
+// @Summary      Create Permission
+// @Description  Registers a new named permission (e.g. report:read).
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.CreatePermissionInput true "Permission data"
+// @Success      201  {object}  entity.Permission
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/permissions [post]
+func (s *RoleService) CreatePermission(input entity.CreatePermissionInput) (*entity.Permission, error) {
+	name := strings.TrimSpace(input.Name)
+	existing, err := s.permRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPermissionNameTaken
+	}
+
+	perm := &entity.Permission{
+		ID:          uuid.New(),
+		Name:        name,
+		Resource:    input.Resource,
+		Action:      input.Action,
+		Description: input.Description,
+	}
+	if err := s.permRepo.Create(perm); err != nil {
+		return nil, err
+	}
+	return perm, nil
+}
+
+// @Summary      Delete Permission
+// @Description  Deletes a custom permission and all of its grants.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Permission ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/permissions/{id} [delete]
+func (s *RoleService) DeletePermission(permissionID uuid.UUID) error {
+	perm, err := s.permRepo.GetByID(permissionID)
+	if err != nil {
+		return err
+	}
+	if perm == nil {
+		return ErrPermissionNotFound
+	}
+	if systemPermissions[perm.Name] {
+		return ErrSystemPermission
+	}
+
+	roleIDs, err := s.permRepo.GetRoleIDs(permissionID)
+	if err != nil {
+		return err
+	}
+	if err := s.permRepo.Delete(permissionID); err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		if err := s.refreshRoleHolders(roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// @Summary      Assign Role to User
+// @Description  Adds a role to a user's role set.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "User ID"
+// @Param        input body entity.AssignRoleInput true "Role to assign"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/users/{id}/roles [post]
+func (s *RoleService) AssignUserRole(userID, roleID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+
+	roles, err := s.userRepo.GetRoleNamesByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if HasRole(roles, role.Name) {
+		return ErrRoleAlreadyHeld
+	}
+
+	if err := s.userRepo.AddUserRole(userID, roleID); err != nil {
+		return err
+	}
+	s.sessionGuard.Invalidate(userID)
+	return nil
+}
+
+// @Summary      Remove Role from User
+// @Description  Removes a secondary role from a user. The primary role cannot be removed.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id      path  string  true  "User ID"
+// @Param        roleId  path  string  true  "Role ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/users/{id}/roles/{roleId} [delete]
+func (s *RoleService) RemoveUserRole(userID, roleID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.RoleID == roleID {
+		return ErrPrimaryRole
+	}
+
+	removed, err := s.userRepo.RemoveUserRole(userID, roleID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrRoleNotHeld
+	}
+	s.sessionGuard.Invalidate(userID)
+	return nil
+}
+
+func (s *RoleService) ensureRoleAndPermission(roleID, permissionID uuid.UUID) error {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+	perm, err := s.permRepo.GetByID(permissionID)
+	if err != nil {
+		return err
+	}
+	if perm == nil {
+		return ErrPermissionNotFound
+	}
+	return nil
+}
+
+// refreshRoleHolders menaikkan token_version semua pemegang role agar JWT lama
+// (dengan permission lama) ditolak dan user mengambil token baru via refresh.
+func (s *RoleService) refreshRoleHolders(roleID uuid.UUID) error {
+	if err := s.roleRepo.BumpTokenVersionByRole(roleID); err != nil {
+		return err
+	}
+	s.sessionGuard.InvalidateAll()
+	return nil
+}
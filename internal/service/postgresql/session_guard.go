@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionRevoked = errors.New("session is no longer valid")
+
+// Lama cache status token per user. Perubahan dari instance ini langsung
+// di-invalidate, TTL hanya membatasi keterlambatan antar instance.
+const sessionCacheTTL = 30 * time.Second
+
+const sessionCacheMaxEntries = 10000
+
+type tokenState struct {
+	version   int
+	isActive  bool
+	expiresAt time.Time
+}
+
+type sessionState struct {
+	userID    uuid.UUID
+	active    bool
+	expiresAt time.Time
+}
+
+// SessionGuard memeriksa apakah claims JWT masih sesuai dengan state user di database
+// (akun aktif, token_version sama, dan sesi belum dicabut), dengan cache in-process.
+type SessionGuard struct {
+	userRepo    repo.UserRepository
+	sessionRepo repo.SessionRepository
+
+	mu       sync.Mutex
+	cache    map[uuid.UUID]tokenState
+	sessions map[uuid.UUID]sessionState
+}
+
+func NewSessionGuard(userRepo repo.UserRepository, sessionRepo repo.SessionRepository) *SessionGuard {
+	return &SessionGuard{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		cache:       make(map[uuid.UUID]tokenState),
+		sessions:    make(map[uuid.UUID]sessionState),
+	}
+}
+
+func (g *SessionGuard) ValidateClaims(claims *entity.JWTClaims) error {
+	state, err := g.lookup(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if !state.isActive {
+		return ErrInactiveAccount
+	}
+	if state.version != claims.TokenVersion {
+		return ErrSessionRevoked
+	}
+
+	// token lama tanpa claim sid tetap diterima sampai kedaluwarsa
+	if claims.SessionID == uuid.Nil {
+		return nil
+	}
+	active, err := g.lookupSession(claims.SessionID, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// Invalidate menghapus cache user agar perubahan (blokir, ganti role/password)
+// langsung berlaku pada request berikutnya.
+func (g *SessionGuard) Invalidate(userID uuid.UUID) {
+	g.mu.Lock()
+	delete(g.cache, userID)
+	for id, session := range g.sessions {
+		if session.userID == userID {
+			delete(g.sessions, id)
+		}
+	}
+	g.mu.Unlock()
+}
+
+// InvalidateSession menghapus cache satu sesi agar pencabutannya langsung berlaku.
+func (g *SessionGuard) InvalidateSession(sessionID uuid.UUID) {
+	g.mu.Lock()
+	delete(g.sessions, sessionID)
+	g.mu.Unlock()
+}
+
+// InvalidateAll mengosongkan seluruh cache, dipakai setelah perubahan yang
+// mengenai banyak user sekaligus (mis. grant permission ke sebuah role).
+func (g *SessionGuard) InvalidateAll() {
+	g.mu.Lock()
+	g.cache = make(map[uuid.UUID]tokenState)
+	g.sessions = make(map[uuid.UUID]sessionState)
+	g.mu.Unlock()
+}
+
+func (g *SessionGuard) lookup(userID uuid.UUID) (tokenState, error) {
+	g.mu.Lock()
+	state, ok := g.cache[userID]
+	g.mu.Unlock()
+	if ok && time.Now().Before(state.expiresAt) {
+		return state, nil
+	}
+
+	version, isActive, err := g.userRepo.GetTokenState(userID)
+	if err != nil {
+		return tokenState{}, err
+	}
+
+	state = tokenState{version: version, isActive: isActive, expiresAt: time.Now().Add(sessionCacheTTL)}
+	g.mu.Lock()
+	g.cache[userID] = state
+	g.mu.Unlock()
+
+	return state, nil
+}
+
+// lookupSession sekaligus memperbarui last_seen_at, paling sering sekali per sessionCacheTTL.
+func (g *SessionGuard) lookupSession(sessionID, userID uuid.UUID) (bool, error) {
+	g.mu.Lock()
+	state, ok := g.sessions[sessionID]
+	g.mu.Unlock()
+	if ok && time.Now().Before(state.expiresAt) {
+		return state.active, nil
+	}
+
+	active, err := g.sessionRepo.Touch(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	g.mu.Lock()
+	g.sessions[sessionID] = sessionState{userID: userID, active: active, expiresAt: now.Add(sessionCacheTTL)}
+	// bersihkan entry kedaluwarsa sesekali agar map tidak tumbuh tanpa batas
+	if len(g.sessions) > sessionCacheMaxEntries {
+		for id, s := range g.sessions {
+			if now.After(s.expiresAt) {
+				delete(g.sessions, id)
+			}
+		}
+	}
+	g.mu.Unlock()
+
+	return active, nil
+}
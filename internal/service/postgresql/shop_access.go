@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShopPermissionDenied = errors.New("your role in this shop does not allow this action")
+	ErrShopAccessRevoked    = errors.New("your account is no longer allowed to act for a shop")
+)
+
+// globalShopPermission mengembalikan permission RBAC yang wajib tetap dimiliki anggota toko.
+// Owner butuh shop:manage (order:update_status untuk memproses order); staf cukup shop:staff.
+func globalShopPermission(shopRole, permission string) string {
+	if shopRole != entity.ShopRoleOwner {
+		return entity.PermShopStaff
+	}
+	if permission == entity.ShopPermFulfilOrders {
+		return entity.PermOrderUpdateStatus
+	}
+	return entity.PermShopManage
+}
+
+func checkGlobalShopPermission(memberRepo repo.ShopMemberRepository, member *entity.ShopMember, permission string) error {
+	ok, err := memberRepo.HasGlobalPermission(member.UserID, globalShopPermission(member.Role, permission))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrShopAccessRevoked
+	}
+	return nil
+}
+
+// resolveShop mencari toko tempat user menjadi anggota (owner atau staf) dan memastikan
+// role-nya memiliki permission shop-scoped tersebut. permission kosong berarti cukup menjadi anggota.
+func resolveShop(shopRepo repo.ShopRepository, memberRepo repo.ShopMemberRepository, userID uuid.UUID, permission string) (*entity.Shop, *entity.ShopMember, error) {
+	member, err := memberRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member == nil {
+		return nil, nil, ErrNoShopOwned
+	}
+	if permission != "" && !HasShopPermission(member.Role, permission) {
+		return nil, nil, ErrShopPermissionDenied
+	}
+	if err := checkGlobalShopPermission(memberRepo, member, permission); err != nil {
+		return nil, nil, err
+	}
+
+	shop, err := shopRepo.GetByID(member.ShopID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shop == nil {
+		return nil, nil, ErrNoShopOwned
+	}
+	return shop, member, nil
+}
+
+// canActForShop dipakai saat resource (order, item) sudah diketahui tokonya.
+func canActForShop(memberRepo repo.ShopMemberRepository, userID, shopID uuid.UUID, permission string) (bool, error) {
+	member, err := memberRepo.GetByUserID(userID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	if member.ShopID != shopID || !HasShopPermission(member.Role, permission) {
+		return false, nil
+	}
+	if err := checkGlobalShopPermission(memberRepo, member, permission); err != nil {
+		if err == ErrShopAccessRevoked {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
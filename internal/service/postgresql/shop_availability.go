@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShopOnVacation        = errors.New("shop is on vacation")
+	ErrShopClosed            = errors.New("shop is currently closed")
+	ErrInvalidOperatingHours = errors.New("invalid operating hours")
+	ErrInvalidVacationUntil  = errors.New("vacation end date must be in the future")
+	ErrInvalidUTCOffset      = errors.New("utc_offset_minutes must be between -720 and 840")
+)
+
+// loadShopAvailability membaca status libur dan jadwal toko lalu menghitung IsOpen pada waktu now.
+// Mengembalikan nil jika toko tidak ada.
+func loadShopAvailability(shopRepo repo.ShopRepository, shopID uuid.UUID, now time.Time) (*entity.ShopAvailability, error) {
+	availability, slots, err := shopRepo.GetAvailability(shopID)
+	if err != nil || availability == nil {
+		return nil, err
+	}
+
+	availability.Hours = make([]entity.OperatingHours, 0, len(slots))
+	for _, slot := range slots {
+		availability.Hours = append(availability.Hours, entity.OperatingHours{
+			Weekday: slot.Weekday,
+			Open:    formatClock(slot.OpenMinute),
+			Close:   formatClock(slot.CloseMinute),
+		})
+	}
+
+	// libur dengan tanggal kembali yang sudah lewat dianggap selesai
+	if availability.VacationMode && availability.VacationUntil != nil && !now.Before(*availability.VacationUntil) {
+		availability.VacationMode = false
+		availability.VacationUntil = nil
+		availability.VacationMessage = ""
+	}
+
+	availability.IsOpen = !availability.VacationMode && withinOperatingHours(slots, availability.UTCOffsetMinutes, now)
+	return availability, nil
+}
+
+// checkShopOpen dipakai sebelum menerima order.
+func checkShopOpen(shopRepo repo.ShopRepository, shopID uuid.UUID) error {
+	availability, err := loadShopAvailability(shopRepo, shopID, time.Now())
+	if err != nil {
+		return err
+	}
+	if availability == nil {
+		return ErrShopNotFound
+	}
+	if availability.VacationMode {
+		return ErrShopOnVacation
+	}
+	if !availability.IsOpen {
+		return ErrShopClosed
+	}
+	return nil
+}
+
+// withinOperatingHours: jadwal kosong berarti toko buka setiap saat.
+func withinOperatingHours(slots []entity.OperatingSlot, utcOffsetMinutes int, now time.Time) bool {
+	if len(slots) == 0 {
+		return true
+	}
+
+	local := now.In(time.FixedZone("", utcOffsetMinutes*60))
+	weekday := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, slot := range slots {
+		if slot.Weekday == weekday && minute >= slot.OpenMinute && minute < slot.CloseMinute {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak 00:00; "24:00" hanya valid sebagai jam tutup.
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if len(value) != 5 {
+		return 0, fmt.Errorf("%w: time %q must use HH:MM", ErrInvalidOperatingHours, value)
+	}
+	if _, err := fmt.Sscanf(value, "%02d:%02d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("%w: time %q must use HH:MM", ErrInvalidOperatingHours, value)
+	}
+	if hour == 24 && minute == 0 {
+		return 24 * 60, nil
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("%w: time %q out of range", ErrInvalidOperatingHours, value)
+	}
+	return hour*60 + minute, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// toOperatingSlots memvalidasi jadwal: jam buka sebelum jam tutup dan tidak ada rentang yang tumpang tindih.
+func toOperatingSlots(hours []entity.OperatingHours) ([]entity.OperatingSlot, error) {
+	slots := make([]entity.OperatingSlot, 0, len(hours))
+	for _, h := range hours {
+		if h.Weekday < 0 || h.Weekday > 6 {
+			return nil, fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", ErrInvalidOperatingHours)
+		}
+		open, err := parseClock(h.Open)
+		if err != nil {
+			return nil, err
+		}
+		closeAt, err := parseClock(h.Close)
+		if err != nil {
+			return nil, err
+		}
+		if open >= closeAt {
+			return nil, fmt.Errorf("%w: open %s must be before close %s", ErrInvalidOperatingHours, h.Open, h.Close)
+		}
+		slots = append(slots, entity.OperatingSlot{Weekday: h.Weekday, OpenMinute: open, CloseMinute: closeAt})
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Weekday != slots[j].Weekday {
+			return slots[i].Weekday < slots[j].Weekday
+		}
+		return slots[i].OpenMinute < slots[j].OpenMinute
+	})
+	for i := 1; i < len(slots); i++ {
+		if slots[i].Weekday == slots[i-1].Weekday && slots[i].OpenMinute < slots[i-1].CloseMinute {
+			return nil, fmt.Errorf("%w: overlapping ranges on weekday %d", ErrInvalidOperatingHours, slots[i].Weekday)
+		}
+	}
+	return slots, nil
+}
+
+// @Summary      Get My Shop Availability
+// @Description  Returns vacation status, weekly operating hours and whether the seller's shop is open right now.
+// @Tags         Shop
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  entity.ShopAvailability
+// @Failure      404  {object}  map[string]interface{} "User does not own a shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/availability [get]
+func (s *ShopItemService) GetMyAvailability(userID uuid.UUID) (*entity.ShopAvailability, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	return loadShopAvailability(s.shopRepo, shop.ID, time.Now())
+}
+
+// @Summary      Set Vacation Mode
+// @Description  Turns vacation mode on or off. While on vacation the shop's items are hidden from the marketplace and new orders are refused; item statuses are not changed. An optional end date switches vacation off automatically.
+// @Tags         Shop
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.UpdateVacationInput true "Vacation settings"
+// @Success      200  {object}  entity.ShopAvailability
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "User does not own a shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/vacation [put]
+func (s *ShopItemService) UpdateVacation(userID uuid.UUID, input entity.UpdateVacationInput) (*entity.ShopAvailability, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageShop)
+	if err != nil {
+		return nil, err
+	}
+
+	until := input.Until
+	message := input.Message
+	if input.Enabled {
+		if until != nil && !until.After(time.Now()) {
+			return nil, ErrInvalidVacationUntil
+		}
+	} else {
+		until = nil
+		message = ""
+	}
+
+	if err := s.shopRepo.UpdateVacation(shop.ID, input.Enabled, until, message); err != nil {
+		return nil, err
+	}
+	return loadShopAvailability(s.shopRepo, shop.ID, time.Now())
+}
+
+// @Summary      Set Operating Hours
+// @Description  Replaces the weekly operating hours of the seller's shop. Times use HH:MM in the shop's UTC offset (close may be 24:00); several ranges per day are allowed. An empty list means the shop is always open. Orders are refused outside these hours.
+// @Tags         Shop
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.UpdateOperatingHoursInput true "Weekly schedule"
+// @Success      200  {object}  entity.ShopAvailability
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "User does not own a shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/hours [put]
+func (s *ShopItemService) UpdateOperatingHours(userID uuid.UUID, input entity.UpdateOperatingHoursInput) (*entity.ShopAvailability, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageShop)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := toOperatingSlots(input.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	current, _, err := s.shopRepo.GetAvailability(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNoShopOwned
+	}
+	offset := current.UTCOffsetMinutes
+	if input.UTCOffsetMinutes != nil {
+		offset = *input.UTCOffsetMinutes
+		if offset < -12*60 || offset > 14*60 {
+			return nil, ErrInvalidUTCOffset
+		}
+	}
+
+	if err := s.shopRepo.ReplaceOperatingHours(shop.ID, offset, slots); err != nil {
+		return nil, err
+	}
+	return loadShopAvailability(s.shopRepo, shop.ID, time.Now())
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// maxCategoryDepth: kategori tingkat atas berkedalaman 1
+const maxCategoryDepth = 3
+
+var (
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrInvalidCategoryName   = errors.New("category name cannot be empty")
+	ErrCategoryDepthExceeded = errors.New("categories can be nested at most 3 levels deep")
+	ErrCategoryCycle         = errors.New("a category cannot be moved under itself or one of its descendants")
+	ErrCategoryInUse         = errors.New("category still has items; pass reassign_to to move them to another category")
+	ErrInvalidReassignTarget = errors.New("reassign_to must be another category of the same shop")
+)
+
+// categoryIndex memudahkan perhitungan kedalaman dan turunan dari daftar kategori datar
+// (kategori satu toko maupun taksonomi global).
+type categoryIndex struct {
+	parent   map[uuid.UUID]*uuid.UUID
+	children map[uuid.UUID][]uuid.UUID
+}
+
+func (idx *categoryIndex) add(id uuid.UUID, parentID *uuid.UUID) {
+	if idx.parent == nil {
+		idx.parent = make(map[uuid.UUID]*uuid.UUID)
+		idx.children = make(map[uuid.UUID][]uuid.UUID)
+	}
+	idx.parent[id] = parentID
+	if parentID != nil {
+		idx.children[*parentID] = append(idx.children[*parentID], id)
+	}
+}
+
+func newCategoryIndex(categories []entity.Category) categoryIndex {
+	var idx categoryIndex
+	for _, c := range categories {
+		idx.add(c.ID, c.ParentID)
+	}
+	return idx
+}
+
+func (idx categoryIndex) has(id uuid.UUID) bool {
+	_, ok := idx.parent[id]
+	return ok
+}
+
+// depth mengembalikan tingkat kategori (1 untuk tingkat atas).
+func (idx categoryIndex) depth(id uuid.UUID) int {
+	d := 0
+	for seen := 0; seen <= len(idx.parent); seen++ {
+		parentID, ok := idx.parent[id]
+		if !ok {
+			break
+		}
+		d++
+		if parentID == nil {
+			break
+		}
+		id = *parentID
+	}
+	return d
+}
+
+// height mengembalikan jumlah tingkat subtree yang berakar di id (1 jika tanpa anak).
+func (idx categoryIndex) height(id uuid.UUID) int {
+	h := 0
+	for _, child := range idx.children[id] {
+		if ch := idx.height(child); ch > h {
+			h = ch
+		}
+	}
+	return h + 1
+}
+
+func (idx categoryIndex) isDescendant(id, ancestor uuid.UUID) bool {
+	for _, child := range idx.children[ancestor] {
+		if child == id || idx.isDescendant(id, child) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMove memvalidasi pemindahan kategori id ke bawah parentID.
+func (idx categoryIndex) checkMove(id, parentID uuid.UUID) error {
+	if parentID == id || idx.isDescendant(parentID, id) {
+		return ErrCategoryCycle
+	}
+	if idx.depth(parentID)+idx.height(id) > maxCategoryDepth {
+		return ErrCategoryDepthExceeded
+	}
+	return nil
+}
+
+func categoryTree(parent *uuid.UUID, categories []entity.Category) []entity.CategoryNode {
+	nodes := []entity.CategoryNode{}
+	for _, c := range categories {
+		if sameParent(parent, c.ParentID) {
+			id := c.ID
+			nodes = append(nodes, entity.CategoryNode{Category: c, Children: categoryTree(&id, categories)})
+		}
+	}
+	return nodes
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// shopCategory memastikan kategori ada dan milik toko; indeks kategori toko ikut dikembalikan untuk cek hierarki.
+func (s *ShopItemService) shopCategory(shopID, categoryID uuid.UUID) (*entity.Category, categoryIndex, error) {
+	categories, err := s.categoryRepo.ListByShop(shopID)
+	if err != nil {
+		return nil, categoryIndex{}, err
+	}
+	for i := range categories {
+		if categories[i].ID == categoryID {
+			return &categories[i], newCategoryIndex(categories), nil
+		}
+	}
+	return nil, categoryIndex{}, ErrCategoryNotFound
+}
+
+// @Summary      List Shop Categories
+// @Description  Returns the categories of the caller's shop as a tree, ordered by sort_order then name.
+// @Tags         Category
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.CategoryNode
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /categories [get]
+func (s *ShopItemService) ListCategories(userID uuid.UUID) ([]entity.CategoryNode, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.ListByShop(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	return categoryTree(nil, categories), nil
+}
+
+// @Summary      Update Shop Category
+// @Description  Renames, reorders or moves a category of the caller's shop. Set move_to_root to make it a top-level category. Nesting is limited to 3 levels.
+// @Tags         Category
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Category ID"
+// @Param        input body entity.UpdateCategoryInput true "Fields to change"
+// @Success      200  {object}  entity.Category
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items)"
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Conflict (category name already exists)"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /categories/{id} [patch]
+func (s *ShopItemService) UpdateCategory(userID, categoryID uuid.UUID, input entity.UpdateCategoryInput) (*entity.Category, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
+	if err != nil {
+		return nil, err
+	}
+	category, idx, err := s.shopCategory(shop.ID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidCategoryName
+		}
+		if !strings.EqualFold(name, category.Name) {
+			exists, err := s.categoryRepo.ExistsByName(shop.ID, name)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				return nil, ErrCategoryExists
+			}
+		}
+		category.Name = name
+	}
+
+	if input.MoveToRoot {
+		category.ParentID = nil
+	} else if input.ParentID != nil {
+		parentID := *input.ParentID
+		if !idx.has(parentID) {
+			return nil, ErrCategoryNotOwned
+		}
+		if err := idx.checkMove(category.ID, parentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = &parentID
+	}
+
+	if input.SortOrder != nil {
+		category.SortOrder = *input.SortOrder
+	}
+
+	if err := s.categoryRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// @Summary      Delete Shop Category
+// @Description  Deletes a category of the caller's shop. Sub-categories move up to the deleted category's parent. If items still use the category, pass reassign_to to move them to another category of the shop; otherwise the request is rejected.
+// @Tags         Category
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id           path   string  true   "Category ID"
+// @Param        reassign_to  query  string  false  "Category ID that receives the items"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items)"
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Category still has items"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /categories/{id} [delete]
+func (s *ShopItemService) DeleteCategory(userID, categoryID uuid.UUID, reassignTo *uuid.UUID) error {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
+	if err != nil {
+		return err
+	}
+	category, idx, err := s.shopCategory(shop.ID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	if reassignTo != nil {
+		if !idx.has(*reassignTo) || *reassignTo == category.ID {
+			return ErrInvalidReassignTarget
+		}
+	} else {
+		n, err := s.categoryRepo.CountItems(category.ID)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrCategoryInUse
+		}
+	}
+
+	return s.categoryRepo.DeleteCategory(category, reassignTo)
+}
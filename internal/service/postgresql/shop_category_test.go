@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// categoryTestTree membangun pohon:
+//
+//	A
+//	├── B
+//	│   └── C
+//	└── D
+//	E
+//	F
+//	└── G
+func categoryTestTree(f *shopFixture) map[string]*entity.Category {
+	c := map[string]*entity.Category{}
+	c["A"] = f.addCategory("A", nil)
+	c["B"] = f.addCategory("B", c["A"])
+	c["C"] = f.addCategory("C", c["B"])
+	c["D"] = f.addCategory("D", c["A"])
+	c["E"] = f.addCategory("E", nil)
+	c["F"] = f.addCategory("F", nil)
+	c["G"] = f.addCategory("G", c["F"])
+	return c
+}
+
+func TestCategoryIndexCheckMove(t *testing.T) {
+	f := newShopFixture(t, false)
+	c := categoryTestTree(f)
+	idx := newCategoryIndex(f.categories.categories)
+
+	tests := []struct {
+		category, parent string
+		want             error
+	}{
+		{category: "C", parent: "D"},
+		{category: "B", parent: "E"},
+		{category: "E", parent: "G"},
+		{category: "A", parent: "A", want: ErrCategoryCycle},
+		{category: "A", parent: "B", want: ErrCategoryCycle},
+		{category: "A", parent: "C", want: ErrCategoryCycle},
+		{category: "B", parent: "C", want: ErrCategoryCycle},
+		{category: "B", parent: "D", want: ErrCategoryDepthExceeded},
+		{category: "F", parent: "B", want: ErrCategoryDepthExceeded},
+		{category: "G", parent: "C", want: ErrCategoryDepthExceeded},
+		{category: "A", parent: "E", want: ErrCategoryDepthExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.category+" under "+tt.parent, func(t *testing.T) {
+			if err := idx.checkMove(c[tt.category].ID, c[tt.parent].ID); err != tt.want {
+				t.Fatalf("checkMove = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryIndexDepthAndHeight(t *testing.T) {
+	f := newShopFixture(t, false)
+	c := categoryTestTree(f)
+	idx := newCategoryIndex(f.categories.categories)
+
+	tests := []struct {
+		name          string
+		depth, height int
+	}{
+		{name: "A", depth: 1, height: 3},
+		{name: "B", depth: 2, height: 2},
+		{name: "C", depth: 3, height: 1},
+		{name: "E", depth: 1, height: 1},
+		{name: "G", depth: 2, height: 1},
+	}
+	for _, tt := range tests {
+		if d, h := idx.depth(c[tt.name].ID), idx.height(c[tt.name].ID); d != tt.depth || h != tt.height {
+			t.Errorf("%s: depth %d height %d, want %d and %d", tt.name, d, h, tt.depth, tt.height)
+		}
+	}
+	if d := idx.depth(uuid.New()); d != 0 {
+		t.Errorf("unknown category depth = %d, want 0", d)
+	}
+
+	tree := categoryTree(nil, f.categories.categories)
+	if len(tree) != 3 || tree[0].Name != "A" || len(tree[0].Children) != 2 || tree[0].Children[0].Children[0].Name != "C" {
+		t.Fatalf("unexpected tree %+v", tree)
+	}
+}
+
+func TestCreateCategory(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID func(f *shopFixture) uuid.UUID
+		input  func(c map[string]*entity.Category) entity.CreateCategoryInput
+		want   error
+	}{
+		{
+			name: "top level",
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				return entity.CreateCategoryInput{Name: "Baru"}
+			},
+		},
+		{
+			name: "under a second level category",
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				return entity.CreateCategoryInput{Name: "Baru", ParentID: &c["B"].ID}
+			},
+		},
+		{
+			name: "under a third level category",
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				return entity.CreateCategoryInput{Name: "Baru", ParentID: &c["C"].ID}
+			},
+			want: ErrCategoryDepthExceeded,
+		},
+		{
+			name: "parent of another shop",
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				other := uuid.New()
+				return entity.CreateCategoryInput{Name: "Baru", ParentID: &other}
+			},
+			want: ErrCategoryNotOwned,
+		},
+		{
+			name: "duplicate name",
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				return entity.CreateCategoryInput{Name: "e"}
+			},
+			want: ErrCategoryExists,
+		},
+		{
+			name:   "staff without manage_items",
+			userID: func(f *shopFixture) uuid.UUID { return f.staffID },
+			input: func(c map[string]*entity.Category) entity.CreateCategoryInput {
+				return entity.CreateCategoryInput{Name: "Baru"}
+			},
+			want: ErrShopPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, false)
+			c := categoryTestTree(f)
+			userID := f.ownerID
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+
+			input := tt.input(c)
+			created, err := f.service.CreateCategory(userID, input)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err == nil && (created.ShopID != f.shop.ID || !sameParent(created.ParentID, input.ParentID)) {
+				t.Fatalf("created %+v", created)
+			}
+		})
+	}
+}
+
+func TestUpdateCategoryMove(t *testing.T) {
+	tests := []struct {
+		name       string
+		category   string
+		input      func(c map[string]*entity.Category) entity.UpdateCategoryInput
+		want       error
+		wantParent string // kosong: tingkat atas
+	}{
+		{
+			name:     "move under another branch",
+			category: "C",
+			input: func(c map[string]*entity.Category) entity.UpdateCategoryInput {
+				return entity.UpdateCategoryInput{ParentID: &c["D"].ID}
+			},
+			wantParent: "D",
+		},
+		{
+			name:     "move to root",
+			category: "B",
+			input: func(c map[string]*entity.Category) entity.UpdateCategoryInput {
+				return entity.UpdateCategoryInput{MoveToRoot: true, ParentID: &c["E"].ID}
+			},
+		},
+		{
+			name:     "under its own descendant",
+			category: "A",
+			input: func(c map[string]*entity.Category) entity.UpdateCategoryInput {
+				return entity.UpdateCategoryInput{ParentID: &c["C"].ID}
+			},
+			want: ErrCategoryCycle,
+		},
+		{
+			name:     "subtree too deep",
+			category: "F",
+			input: func(c map[string]*entity.Category) entity.UpdateCategoryInput {
+				return entity.UpdateCategoryInput{ParentID: &c["B"].ID}
+			},
+			want: ErrCategoryDepthExceeded,
+		},
+		{
+			name:     "parent of another shop",
+			category: "E",
+			input: func(c map[string]*entity.Category) entity.UpdateCategoryInput {
+				other := uuid.New()
+				return entity.UpdateCategoryInput{ParentID: &other}
+			},
+			want: ErrCategoryNotOwned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, false)
+			c := categoryTestTree(f)
+
+			updated, err := f.service.UpdateCategory(f.ownerID, c[tt.category].ID, tt.input(c))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			var wantParent *uuid.UUID
+			if tt.wantParent != "" {
+				wantParent = &c[tt.wantParent].ID
+			}
+			if !sameParent(updated.ParentID, wantParent) {
+				t.Fatalf("parent = %v, want %v", updated.ParentID, wantParent)
+			}
+		})
+	}
+}
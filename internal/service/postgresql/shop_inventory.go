@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrItemModerated     = errors.New("item has been moderated by an admin; its status cannot be changed")
+	ErrInvalidItemState  = errors.New("status must be one of draft, active, inactive")
+	ErrDraftNotPublished = errors.New("drafts must be published via POST /items/{id}/publish")
+)
+
+// checkSellerStatusChange: seller tidak boleh memberi atau mencabut status moderated.
+func checkSellerStatusChange(item *entity.Item, status string) error {
+	if status == item.Status {
+		return nil
+	}
+	if item.Status == entity.ItemStatusModerated {
+		return ErrItemModerated
+	}
+	if item.Status == entity.ItemStatusDraft && status == entity.ItemStatusActive {
+		return ErrDraftNotPublished
+	}
+	switch status {
+	case entity.ItemStatusDraft, entity.ItemStatusActive, entity.ItemStatusInactive:
+		return nil
+	}
+	return ErrInvalidItemState
+}
+
+// @Summary      List My Shop Inventory
+// @Description  Lists every item of the caller's shop, including drafts, inactive and moderated items. Filters by status, category (including sub-categories), stock level (in_stock, low, out) and text; sorts by newest, oldest, updated, name, price_asc, price_desc, stock_asc or stock_desc. Also accepts a shop API key with the items:read scope in X-API-Key.
+// @Tags         Seller/Items
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        status       query  string   false  "draft, active, inactive or moderated"
+// @Param        category_id  query  string   false  "Shop category ID"
+// @Param        stock        query  string   false  "in_stock, low or out"
+// @Param        q            query  string   false  "Search in name and description"
+// @Param        sort         query  string   false  "Sort order (default newest)"
+// @Param        limit        query  integer  false  "Limit (default 20, max 100)"
+// @Param        offset       query  integer  false  "Offset"
+// @Success      200  {array}   entity.Item
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/items [get]
+func (s *ShopItemService) ListInventory(userID uuid.UUID, filter entity.InventoryFilter) ([]entity.Item, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.itemRepo.ListInventory(shop.ID, filter)
+}
+
+// @Summary      Bulk Change Item Status
+// @Description  Activates or deactivates up to 100 items of the caller's shop at once. Items that are missing, moderated, drafts being activated, or already in the target status are skipped and reported.
+// @Tags         Seller/Items
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.BulkItemStatusInput true "Item IDs and target status (active or inactive)"
+// @Success      200  {object}  entity.BulkItemStatusResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items, or shop not verified while the verified-shop policy is on)"
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/items/status [patch]
+func (s *ShopItemService) BulkUpdateItemStatus(userID uuid.UUID, input entity.BulkItemStatusInput) (*entity.BulkItemStatusResult, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
+	if err != nil {
+		return nil, err
+	}
+	if input.Status == entity.ItemStatusActive {
+		if err := s.checkCanList(shop); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.itemRepo.ListByIDs(shop.ID, input.ItemIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]entity.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	result := &entity.BulkItemStatusResult{Updated: []uuid.UUID{}, Skipped: []entity.BulkItemSkip{}}
+	seen := make(map[uuid.UUID]bool, len(input.ItemIDs))
+	for _, id := range input.ItemIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, ok := byID[id]
+		switch {
+		case !ok:
+			result.Skipped = append(result.Skipped, entity.BulkItemSkip{ItemID: id, Reason: "item not found in your shop"})
+		case item.Status == entity.ItemStatusModerated:
+			result.Skipped = append(result.Skipped, entity.BulkItemSkip{ItemID: id, Reason: ErrItemModerated.Error()})
+		case item.Status == entity.ItemStatusDraft && input.Status == entity.ItemStatusActive:
+			result.Skipped = append(result.Skipped, entity.BulkItemSkip{ItemID: id, Reason: ErrDraftNotPublished.Error()})
+		case item.Status == input.Status:
+			result.Skipped = append(result.Skipped, entity.BulkItemSkip{ItemID: id, Reason: "item is already " + input.Status})
+		default:
+			result.Updated = append(result.Updated, id)
+		}
+	}
+
+	if len(result.Updated) > 0 {
+		if err := s.itemRepo.UpdateStatusBulk(shop.ID, result.Updated, input.Status); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
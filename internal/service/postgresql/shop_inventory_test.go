@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestBulkUpdateItemStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		verified bool // status verifikasi toko saat kebijakan toko terverifikasi aktif
+		userID   func(f *shopFixture) uuid.UUID
+		wantErr  error
+		// item disebut dengan nama status awalnya (plus "missing" yang tidak ada dan "foreign" milik toko lain)
+		wantUpdated []string
+		wantSkipped map[string]string
+	}{
+		{
+			name:        "deactivate a mixed batch",
+			status:      entity.ItemStatusInactive,
+			verified:    true,
+			wantUpdated: []string{"active", "draft"},
+			wantSkipped: map[string]string{
+				"inactive":  "item is already inactive",
+				"moderated": ErrItemModerated.Error(),
+				"missing":   "item not found in your shop",
+				"foreign":   "item not found in your shop",
+			},
+		},
+		{
+			name:        "activate skips drafts",
+			status:      entity.ItemStatusActive,
+			verified:    true,
+			wantUpdated: []string{"inactive"},
+			wantSkipped: map[string]string{
+				"active":    "item is already active",
+				"draft":     ErrDraftNotPublished.Error(),
+				"moderated": ErrItemModerated.Error(),
+				"missing":   "item not found in your shop",
+				"foreign":   "item not found in your shop",
+			},
+		},
+		{
+			name:    "activate in an unverified shop",
+			status:  entity.ItemStatusActive,
+			wantErr: ErrShopNotVerified,
+		},
+		{
+			name:        "deactivate in an unverified shop",
+			status:      entity.ItemStatusInactive,
+			wantUpdated: []string{"active", "draft"},
+			wantSkipped: map[string]string{
+				"inactive":  "item is already inactive",
+				"moderated": ErrItemModerated.Error(),
+				"missing":   "item not found in your shop",
+				"foreign":   "item not found in your shop",
+			},
+		},
+		{
+			name:    "staff without manage_items",
+			status:  entity.ItemStatusInactive,
+			userID:  func(f *shopFixture) uuid.UUID { return f.staffID },
+			wantErr: ErrShopPermissionDenied,
+		},
+		{
+			name:     "owner whose shop permission was revoked",
+			status:   entity.ItemStatusInactive,
+			verified: true,
+			userID: func(f *shopFixture) uuid.UUID {
+				f.members.revoked[f.ownerID] = true
+				return f.ownerID
+			},
+			wantErr: ErrShopAccessRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, true)
+			if !tt.verified {
+				f.shop.VerificationStatus = entity.ShopPending
+			}
+			ids := map[string]uuid.UUID{"missing": uuid.New()}
+			for _, status := range []string{entity.ItemStatusDraft, entity.ItemStatusActive, entity.ItemStatusInactive, entity.ItemStatusModerated} {
+				ids[status] = f.addItem(entity.Item{Name: status, Status: status}).ID
+			}
+			ids["foreign"] = f.addItem(entity.Item{ShopID: uuid.New(), Name: "foreign", Status: entity.ItemStatusActive}).ID
+
+			userID := f.ownerID
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+			// ID ganda hanya diproses sekali
+			input := entity.BulkItemStatusInput{Status: tt.status, ItemIDs: []uuid.UUID{ids["active"]}}
+			for _, id := range ids {
+				input.ItemIDs = append(input.ItemIDs, id)
+			}
+
+			result, err := f.service.BulkUpdateItemStatus(userID, input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			names := make(map[uuid.UUID]string, len(ids))
+			for name, id := range ids {
+				names[id] = name
+			}
+			if len(result.Updated) != len(tt.wantUpdated) {
+				t.Fatalf("updated %d items, want %v", len(result.Updated), tt.wantUpdated)
+			}
+			for _, name := range tt.wantUpdated {
+				if item := f.items.items[ids[name]]; item.Status != tt.status {
+					t.Errorf("%s: status %q, want %q", name, item.Status, tt.status)
+				}
+			}
+			if len(result.Skipped) != len(tt.wantSkipped) {
+				t.Fatalf("skipped %+v, want %v", result.Skipped, tt.wantSkipped)
+			}
+			for _, skip := range result.Skipped {
+				name := names[skip.ItemID]
+				if want := tt.wantSkipped[name]; skip.Reason != want {
+					t.Errorf("%s skipped with %q, want %q", name, skip.Reason, want)
+				}
+				if item, ok := f.items.items[skip.ItemID]; ok && item.Status != name && name != "foreign" {
+					t.Errorf("skipped item %s changed to %q", name, item.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSellerStatusChange(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     error
+	}{
+		{from: entity.ItemStatusActive, to: entity.ItemStatusInactive},
+		{from: entity.ItemStatusInactive, to: entity.ItemStatusActive},
+		{from: entity.ItemStatusActive, to: entity.ItemStatusDraft},
+		{from: entity.ItemStatusDraft, to: entity.ItemStatusInactive},
+		{from: entity.ItemStatusModerated, to: entity.ItemStatusModerated},
+		{from: entity.ItemStatusDraft, to: entity.ItemStatusActive, want: ErrDraftNotPublished},
+		{from: entity.ItemStatusModerated, to: entity.ItemStatusActive, want: ErrItemModerated},
+		{from: entity.ItemStatusActive, to: entity.ItemStatusModerated, want: ErrInvalidItemState},
+		{from: entity.ItemStatusActive, to: "sold", want: ErrInvalidItemState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if err := checkSellerStatusChange(&entity.Item{Status: tt.from}, tt.to); err != tt.want {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInviteeNotFound          = errors.New("no active user with that username or email")
+	ErrCannotInviteSelf         = errors.New("you cannot invite yourself")
+	ErrAlreadyShopMember        = errors.New("user is already a member of a shop")
+	ErrInvitationPending        = errors.New("user already has a pending invitation to this shop")
+	ErrShopInvitationNotFound   = errors.New("shop invitation not found")
+	ErrShopInvitationNotPending = errors.New("shop invitation is no longer pending")
+	ErrShopMemberNotFound       = errors.New("shop member not found")
+	ErrCannotModifyOwner        = errors.New("the shop owner cannot be changed or removed")
+)
+
+const shopInvitationTTL = 7 * 24 * time.Hour
+
+type ShopMemberService struct {
+	memberRepo repo.ShopMemberRepository
+	shopRepo   repo.ShopRepository
+	userRepo   repo.UserRepository
+	logRepo    mongorepo.LogRepository
+}
+
+func NewShopMemberService(
+	memberRepo repo.ShopMemberRepository,
+	shopRepo repo.ShopRepository,
+	userRepo repo.UserRepository,
+	logRepo mongorepo.LogRepository,
+) *ShopMemberService {
+	return &ShopMemberService{
+		memberRepo: memberRepo,
+		shopRepo:   shopRepo,
+		userRepo:   userRepo,
+		logRepo:    logRepo,
+	}
+}
+
+// findInvitee mencari user berdasarkan email (jika mengandung '@') atau username.
+func (s *ShopMemberService) findInvitee(identifier string) (*entity.User, error) {
+	identifier = strings.TrimSpace(identifier)
+	if strings.Contains(identifier, "@") {
+		user, err := s.userRepo.GetByEmail(identifier)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || !user.IsActive {
+			return nil, ErrInviteeNotFound
+		}
+		return user, nil
+	}
+
+	// GetByUsername mengembalikan error jika user tidak ditemukan
+	user, _, err := s.userRepo.GetByUsername(identifier)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrInviteeNotFound
+	}
+	return user, nil
+}
+
+// @Summary      List Shop Members
+// @Description  Lists the owner and staff of the current user's shop with their shop roles.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.ShopMember
+// @Failure      404  {object}  map[string]interface{} "User is not a member of any shop"
+// @Router       /shops/me/members [get]
+func (s *ShopMemberService) ListMembers(userID uuid.UUID) ([]entity.ShopMember, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.memberRepo.ListByShop(shop.ID)
+}
+
+// @Summary      Invite Shop Staff
+// @Description  Invites a registered user, by username or email, to join the shop as manager or packer. Managers can manage items, orders, offers and shop settings; packers can only fulfil orders. Requires the manage_staff shop permission (owner).
+// @Tags         Shop/Staff
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.InviteShopMemberInput true "Username or email and shop role"
+// @Success      201  {object}  entity.ShopInvitation
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "Invitee not found"
+// @Failure      409  {object}  map[string]interface{} "Already a member or invitation pending"
+// @Router       /shops/me/invitations [post]
+func (s *ShopMemberService) Invite(userID uuid.UUID, input entity.InviteShopMemberInput) (*entity.ShopInvitation, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.findInvitee(input.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == userID {
+		return nil, ErrCannotInviteSelf
+	}
+
+	existing, err := s.memberRepo.GetByUserID(invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyShopMember
+	}
+	pending, err := s.memberRepo.HasPendingInvitation(shop.ID, invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrInvitationPending
+	}
+
+	now := time.Now()
+	inv := &entity.ShopInvitation{
+		ID:        uuid.New(),
+		ShopID:    shop.ID,
+		ShopName:  shop.Name,
+		UserID:    invitee.ID,
+		Username:  invitee.Username,
+		Role:      input.Role,
+		InvitedBy: userID,
+		Status:    "pending",
+		ExpiresAt: now.Add(shopInvitationTTL),
+		CreatedAt: now,
+	}
+	// undangan pending yang sudah kedaluwarsa masih memegang unique index, tutup dulu
+	if err := s.memberRepo.ExpirePendingInvitations(shop.ID, invitee.ID); err != nil {
+		return nil, err
+	}
+	if err := s.memberRepo.CreateInvitation(inv); err != nil {
+		return nil, err
+	}
+
+	saveNotification(
+		s.logRepo, invitee.ID, "Undangan Bergabung ke Toko",
+		fmt.Sprintf("Anda diundang bergabung ke toko '%s' sebagai %s.", shop.Name, inv.Role),
+		"shop_invitation", inv.ID,
+	)
+
+	return inv, nil
+}
+
+// @Summary      List Pending Shop Invitations
+// @Description  Lists pending invitations sent by the current user's shop. Requires the manage_staff shop permission.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.ShopInvitation
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /shops/me/invitations [get]
+func (s *ShopMemberService) ListShopInvitations(userID uuid.UUID) ([]entity.ShopInvitation, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageStaff)
+	if err != nil {
+		return nil, err
+	}
+	return s.memberRepo.ListPendingByShop(shop.ID)
+}
+
+// @Summary      Revoke Shop Invitation
+// @Description  Cancels a pending invitation of the current user's shop. Requires the manage_staff shop permission.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Invitation is not pending"
+// @Router       /shops/me/invitations/{id} [delete]
+func (s *ShopMemberService) RevokeInvitation(userID, invitationID uuid.UUID) error {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageStaff)
+	if err != nil {
+		return err
+	}
+
+	inv, err := s.memberRepo.GetInvitationByID(invitationID)
+	if err != nil {
+		return err
+	}
+	if inv == nil || inv.ShopID != shop.ID {
+		return ErrShopInvitationNotFound
+	}
+	return s.respond(inv, "revoked")
+}
+
+// @Summary      Change Staff Role
+// @Description  Changes a staff member's shop role between manager and packer. The owner cannot be changed. Requires the manage_staff shop permission.
+// @Tags         Shop/Staff
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path  string  true  "Staff user ID"
+// @Param        input body entity.UpdateShopMemberInput true "New shop role"
+// @Success      200  {object}  entity.ShopMember
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /shops/me/members/{userId} [patch]
+func (s *ShopMemberService) UpdateMemberRole(userID, memberUserID uuid.UUID, input entity.UpdateShopMemberInput) (*entity.ShopMember, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.getShopMember(shop.ID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.memberRepo.UpdateRole(shop.ID, member.UserID, input.Role); err != nil {
+		return nil, err
+	}
+	member.Role = input.Role
+	member.UpdatedAt = time.Now()
+
+	saveNotification(
+		s.logRepo, member.UserID, "Role Toko Berubah",
+		fmt.Sprintf("Role Anda di toko '%s' sekarang %s.", shop.Name, input.Role),
+		"shop_member", shop.ID,
+	)
+
+	return member, nil
+}
+
+// @Summary      Remove Staff Member
+// @Description  Removes a staff member from the shop. The owner cannot be removed. Requires the manage_staff shop permission.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        userId  path  string  true  "Staff user ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /shops/me/members/{userId} [delete]
+func (s *ShopMemberService) RemoveMember(userID, memberUserID uuid.UUID) error {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageStaff)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.getShopMember(shop.ID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.memberRepo.Remove(shop.ID, member.UserID); err != nil {
+		return err
+	}
+
+	saveNotification(
+		s.logRepo, member.UserID, "Dikeluarkan dari Toko",
+		fmt.Sprintf("Anda tidak lagi menjadi staf toko '%s'.", shop.Name),
+		"shop_member", shop.ID,
+	)
+	return nil
+}
+
+// @Summary      Leave Shop
+// @Description  Lets a staff member leave the shop they belong to. The owner cannot leave their own shop.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Owner cannot leave"
+// @Router       /shops/me/membership [delete]
+func (s *ShopMemberService) LeaveShop(userID uuid.UUID) error {
+	shop, member, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.ShopRoleOwner {
+		return ErrCannotModifyOwner
+	}
+	if _, err := s.memberRepo.Remove(shop.ID, userID); err != nil {
+		return err
+	}
+
+	saveNotification(
+		s.logRepo, shop.UserID, "Staf Keluar dari Toko",
+		fmt.Sprintf("%s telah keluar dari toko '%s'.", member.Username, shop.Name),
+		"shop_member", shop.ID,
+	)
+	return nil
+}
+
+// getShopMember memastikan target adalah staf (bukan owner) di toko yang sama.
+func (s *ShopMemberService) getShopMember(shopID, memberUserID uuid.UUID) (*entity.ShopMember, error) {
+	member, err := s.memberRepo.GetByUserID(memberUserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.ShopID != shopID {
+		return nil, ErrShopMemberNotFound
+	}
+	if member.Role == entity.ShopRoleOwner {
+		return nil, ErrCannotModifyOwner
+	}
+	return member, nil
+}
+
+// @Summary      My Shop Invitations
+// @Description  Lists pending shop staff invitations addressed to the current user.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   entity.ShopInvitation
+// @Router       /me/shop-invitations [get]
+func (s *ShopMemberService) ListMyInvitations(userID uuid.UUID) ([]entity.ShopInvitation, error) {
+	return s.memberRepo.ListPendingByUser(userID)
+}
+
+// @Summary      Accept Shop Invitation
+// @Description  Joins the inviting shop with the role from the invitation. A user can belong to only one shop at a time.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  entity.ShopMember
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Already a member or invitation not pending"
+// @Router       /me/shop-invitations/{id}/accept [post]
+func (s *ShopMemberService) AcceptInvitation(userID, invitationID uuid.UUID) (*entity.ShopMember, error) {
+	inv, err := s.getMyPendingInvitation(userID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.memberRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyShopMember
+	}
+
+	if err := s.memberRepo.AcceptInvitation(inv); err != nil {
+		return nil, err
+	}
+
+	saveNotification(
+		s.logRepo, inv.InvitedBy, "Undangan Toko Diterima",
+		fmt.Sprintf("%s telah bergabung ke toko '%s' sebagai %s.", inv.Username, inv.ShopName, inv.Role),
+		"shop_member", inv.ShopID,
+	)
+
+	return s.memberRepo.GetByUserID(userID)
+}
+
+// @Summary      Decline Shop Invitation
+// @Description  Declines a pending shop staff invitation addressed to the current user.
+// @Tags         Shop/Staff
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Invitation not pending"
+// @Router       /me/shop-invitations/{id}/decline [post]
+func (s *ShopMemberService) DeclineInvitation(userID, invitationID uuid.UUID) error {
+	inv, err := s.getMyPendingInvitation(userID, invitationID)
+	if err != nil {
+		return err
+	}
+	return s.respond(inv, "declined")
+}
+
+func (s *ShopMemberService) getMyPendingInvitation(userID, invitationID uuid.UUID) (*entity.ShopInvitation, error) {
+	inv, err := s.memberRepo.GetInvitationByID(invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil || inv.UserID != userID {
+		return nil, ErrShopInvitationNotFound
+	}
+	if inv.Status != "pending" || !time.Now().Before(inv.ExpiresAt) {
+		return nil, ErrShopInvitationNotPending
+	}
+	return inv, nil
+}
+
+func (s *ShopMemberService) respond(inv *entity.ShopInvitation, status string) error {
+	ok, err := s.memberRepo.RespondInvitation(inv.ID, status)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrShopInvitationNotPending
+	}
+	return nil
+}
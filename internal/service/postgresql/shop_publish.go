@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrItemNotFound    = errors.New("item not found")
+	ErrItemNotInShop   = errors.New("this item does not belong to your shop")
+	ErrItemNotDraft    = errors.New("only draft items can be published")
+	ErrDraftIncomplete = errors.New("draft is not ready to publish")
+)
+
+// @Summary      Publish Draft Item
+// @Description  Validates a draft (category owned by the shop, price not negative, stock of at least one, at least one image), copies the photo of the source offer into the item images, and makes the item active. Complete missing fields first with PUT /items/{id}.
+// @Tags         Seller/Items
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Draft item ID"
+// @Success      200  {object}  map[string]interface{} "Returns the published item and its images"
+// @Failure      400  {object}  map[string]interface{} "Draft is incomplete"
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items, item of another shop, or shop not verified while the verified-shop policy is on)"
+// @Failure      404  {object}  map[string]interface{} "Item not found"
+// @Failure      409  {object}  map[string]interface{} "Item is not a draft"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /items/{id}/publish [post]
+func (s *ShopItemService) PublishItem(userID, itemID uuid.UUID) (*entity.Item, []string, error) {
+	item, err := s.itemRepo.GetItemByID(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, ErrItemNotFound
+	}
+
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.ShopID != shop.ID {
+		return nil, nil, ErrItemNotInShop
+	}
+	if item.Status != entity.ItemStatusDraft {
+		return nil, nil, ErrItemNotDraft
+	}
+	if err := s.checkCanList(shop); err != nil {
+		return nil, nil, err
+	}
+
+	imageURLs, err := s.itemRepo.ListImageURLs(item.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// foto penawaran asal disalin sekali; publish ulang setelah gagal tidak menggandakannya
+	var newImages []entity.ItemImage
+	if item.SourceOfferID != nil {
+		offer, err := s.offerRepo.GetOfferByID(*item.SourceOfferID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if offer != nil && offer.ImageURL != "" && !containsString(imageURLs, offer.ImageURL) {
+			newImages = append(newImages, entity.ItemImage{ID: uuid.New(), ItemID: item.ID, ImageURL: offer.ImageURL})
+			imageURLs = append(imageURLs, offer.ImageURL)
+		}
+	}
+
+	var problems []string
+	if item.CategoryID == uuid.Nil {
+		problems = append(problems, "category is required")
+	} else {
+		owned, err := s.shopRepo.IsCategoryOwnedByShop(item.CategoryID, shop.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !owned {
+			problems = append(problems, ErrCategoryNotOwned.Error())
+		}
+	}
+	// aturan harga sama dengan CreateItem/UpdateItem: 0 boleh (barang gratis)
+	if item.Price < 0 {
+		problems = append(problems, ErrInvalidPrice.Error())
+	}
+	if item.Stock < 1 {
+		problems = append(problems, "stock must be at least 1")
+	}
+	if len(imageURLs) == 0 {
+		problems = append(problems, "at least one image is required")
+	}
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrDraftIncomplete, strings.Join(problems, "; "))
+	}
+
+	published, err := s.itemRepo.PublishDraft(item.ID, newImages)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !published {
+		return nil, nil, ErrItemNotDraft
+	}
+	item.Status = entity.ItemStatusActive
+	item.UpdatedAt = time.Now()
+
+	history := &entity.HistoryStatus{
+		ID:          primitive.NewObjectID(),
+		RelatedID:   item.ID.String(),
+		RelatedType: "item",
+		OldStatus:   entity.ItemStatusDraft,
+		NewStatus:   entity.ItemStatusActive,
+		ChangedBy:   userID.String(),
+		Timestamp:   time.Now(),
+		Note:        "published",
+	}
+	if err := s.logRepo.SaveHistoryStatus(history); err != nil {
+		log.Printf("Warning: failed to save history status for item %s: %v", item.ID.String(), err)
+	}
+
+	return item, imageURLs, nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
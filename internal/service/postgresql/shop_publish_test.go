@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestPublishItem(t *testing.T) {
+	const offerPhoto = "/uploads/offers/meja.jpg"
+
+	tests := []struct {
+		name string
+		// draft mengubah draft lengkap bawaan; kembalikan gambar item yang sudah tersimpan
+		draft      func(f *shopFixture, item *entity.Item) []string
+		userID     func(f *shopFixture) uuid.UUID
+		unverified bool
+		wantErr    error
+		// wantProblems: potongan pesan yang harus ada pada ErrDraftIncomplete
+		wantProblems []string
+		wantImages   []string
+	}{
+		{
+			name:       "complete draft",
+			draft:      func(f *shopFixture, item *entity.Item) []string { return []string{"/uploads/items/1.jpg"} },
+			wantImages: []string{"/uploads/items/1.jpg"},
+		},
+		{
+			name: "free item at price zero",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.Price = 0
+				return []string{"/uploads/items/1.jpg"}
+			},
+			wantImages: []string{"/uploads/items/1.jpg"},
+		},
+		{
+			name: "offer photo is copied",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.SourceOfferID = addOffer(f, offerPhoto)
+				return nil
+			},
+			wantImages: []string{offerPhoto},
+		},
+		{
+			name: "offer photo is not copied twice",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.SourceOfferID = addOffer(f, offerPhoto)
+				return []string{offerPhoto}
+			},
+			wantImages: []string{offerPhoto},
+		},
+		{
+			name: "everything missing",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.CategoryID = uuid.Nil
+				item.Price = -1
+				item.Stock = 0
+				return nil
+			},
+			wantErr:      ErrDraftIncomplete,
+			wantProblems: []string{"category is required", ErrInvalidPrice.Error(), "stock must be at least 1", "at least one image is required"},
+		},
+		{
+			name: "category of another shop",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.CategoryID = uuid.New()
+				return []string{"/uploads/items/1.jpg"}
+			},
+			wantErr:      ErrDraftIncomplete,
+			wantProblems: []string{ErrCategoryNotOwned.Error()},
+		},
+		{
+			name: "offer without photo still needs an image",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.SourceOfferID = addOffer(f, "")
+				return nil
+			},
+			wantErr:      ErrDraftIncomplete,
+			wantProblems: []string{"at least one image is required"},
+		},
+		{
+			name: "already active",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.Status = entity.ItemStatusActive
+				return []string{"/uploads/items/1.jpg"}
+			},
+			wantErr: ErrItemNotDraft,
+		},
+		{
+			name: "item of another shop",
+			draft: func(f *shopFixture, item *entity.Item) []string {
+				item.ShopID = uuid.New()
+				return []string{"/uploads/items/1.jpg"}
+			},
+			wantErr: ErrItemNotInShop,
+		},
+		{
+			name:       "unverified shop",
+			draft:      func(f *shopFixture, item *entity.Item) []string { return []string{"/uploads/items/1.jpg"} },
+			unverified: true,
+			wantErr:    ErrShopNotVerified,
+		},
+		{
+			name:    "staff without manage_items",
+			draft:   func(f *shopFixture, item *entity.Item) []string { return []string{"/uploads/items/1.jpg"} },
+			userID:  func(f *shopFixture) uuid.UUID { return f.staffID },
+			wantErr: ErrShopPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, true)
+			if tt.unverified {
+				f.shop.VerificationStatus = entity.ShopPending
+			}
+			category := f.addCategory("Meja", nil)
+			draft := entity.Item{
+				ID: uuid.New(), ShopID: f.shop.ID, CategoryID: category.ID, Name: "Meja kayu",
+				Price: 150000, Stock: 1, Status: entity.ItemStatusDraft,
+			}
+			images := tt.draft(f, &draft)
+			f.addItem(draft, images...)
+			userID := f.ownerID
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+
+			item, imageURLs, err := f.service.PublishItem(userID, draft.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			for _, problem := range tt.wantProblems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("error %q does not mention %q", err, problem)
+				}
+			}
+			stored := f.items.items[draft.ID]
+			if err != nil {
+				if stored.Status != draft.Status || len(f.logs.history) != 0 {
+					t.Fatalf("failed publish changed the item: %+v, history %+v", stored, f.logs.history)
+				}
+				return
+			}
+
+			if item.Status != entity.ItemStatusActive || stored.Status != entity.ItemStatusActive {
+				t.Fatalf("status = %q (stored %q), want active", item.Status, stored.Status)
+			}
+			if !slices.Equal(imageURLs, tt.wantImages) || !slices.Equal(f.items.images[draft.ID], tt.wantImages) {
+				t.Fatalf("images = %v (stored %v), want %v", imageURLs, f.items.images[draft.ID], tt.wantImages)
+			}
+			if len(f.logs.history) != 1 || f.logs.history[0].OldStatus != entity.ItemStatusDraft || f.logs.history[0].NewStatus != entity.ItemStatusActive {
+				t.Fatalf("history = %+v", f.logs.history)
+			}
+		})
+	}
+}
+
+func TestPublishItemNotFound(t *testing.T) {
+	f := newShopFixture(t, false)
+	if _, _, err := f.service.PublishItem(f.ownerID, uuid.New()); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("err = %v, want ErrItemNotFound", err)
+	}
+}
+
+// addOffer menyimpan penawaran yang sudah diterima toko fixture dan mengembalikan ID-nya.
+func addOffer(f *shopFixture, imageURL string) *uuid.UUID {
+	offer := &entity.Offer{ID: uuid.New(), SellerID: f.ownerID, ItemName: "Meja kayu", ImageURL: imageURL, Status: "accepted"}
+	f.offers.offers[offer.ID] = offer
+	return &offer.ID
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
 	repo "home-market/internal/repository/postgresql"
 	"github.com/google/uuid"
 )
@@ -12,9 +14,12 @@ import (
 // --- ERROR DEFINITIONS (Consolidated) ---
 var (
 	// Shop Errors
-	ErrNotSeller      = errors.New("only seller role can manage shop/items")
+	ErrNotSeller      = errors.New("access denied: shop:manage permission is required")
 	ErrShopExists     = errors.New("user already has a shop")
 	ErrNoShopOwned    = errors.New("seller does not own a shop")
+	ErrShopNotFound   = errors.New("shop not found")
+	ErrInvalidShopName    = errors.New("shop name cannot be empty")
+	ErrInvalidShopAddress = errors.New("shop address cannot be empty")
 	
 	// Category Errors
 	ErrCategoryExists = errors.New("category name already exists in this shop")
@@ -23,40 +28,83 @@ var (
 	ErrInvalidStock     = errors.New("stock must be >= 0")
 	ErrInvalidPrice     = errors.New("price must be >= 0")
 	ErrCategoryNotOwned = errors.New("category does not belong to seller's shop")
+	ErrGlobalCategoryConflict = errors.New("global_category_id and clear_global_category cannot be combined")
 )
 
 // --- SERVICE STRUCT (Consolidated Dependencies) ---
 
 type ShopItemService struct {
 	// Repositories untuk Shop/Category/Item CRUD
-	shopRepo     repo.ShopRepository 
-	categoryRepo repo.CategoryRepository
-	itemRepo     repo.ItemRepository
+	shopRepo           repo.ShopRepository 
+	memberRepo         repo.ShopMemberRepository
+	categoryRepo       repo.CategoryRepository
+	globalCategoryRepo repo.GlobalCategoryRepository
+	itemRepo           repo.ItemRepository
 	
 	// ItemService masih memerlukan OrderRepo untuk GetItemForOrder (Marketplace/Detail)
 	orderRepo    repo.OrderRepository 
+
+	// Publish draft: foto dari penawaran asal dan riwayat status
+	offerRepo    repo.OfferRepository
+	logRepo      mongorepo.LogRepository
+
+	// kebijakan platform: hanya toko terverifikasi yang boleh menayangkan item
+	requireVerifiedShop bool
 }
 
 func NewShopItemService(
 	shopRepo repo.ShopRepository,
+	memberRepo repo.ShopMemberRepository,
 	categoryRepo repo.CategoryRepository,
+	globalCategoryRepo repo.GlobalCategoryRepository,
 	itemRepo repo.ItemRepository,
 	orderRepo repo.OrderRepository,
+	offerRepo repo.OfferRepository,
+	logRepo mongorepo.LogRepository,
+	requireVerifiedShop bool,
 ) *ShopItemService {
 	return &ShopItemService{
-		shopRepo:     shopRepo,
-		categoryRepo: categoryRepo,
-		itemRepo:     itemRepo,
-		orderRepo:    orderRepo,
+		shopRepo:            shopRepo,
+		memberRepo:          memberRepo,
+		categoryRepo:        categoryRepo,
+		globalCategoryRepo:  globalCategoryRepo,
+		itemRepo:            itemRepo,
+		orderRepo:           orderRepo,
+		offerRepo:           offerRepo,
+		logRepo:             logRepo,
+		requireVerifiedShop: requireVerifiedShop,
 	}
 }
 
+// checkGlobalCategory memastikan kategori global yang dipilih seller benar-benar ada.
+func (s *ShopItemService) checkGlobalCategory(id *uuid.UUID) error {
+	if id == nil {
+		return nil
+	}
+	c, err := s.globalCategoryRepo.GetByID(*id)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return ErrInvalidGlobalCategory
+	}
+	return nil
+}
+
+// checkCanList menerapkan kebijakan REQUIRE_VERIFIED_SHOP_TO_LIST saat item akan ditayangkan.
+func (s *ShopItemService) checkCanList(shop *entity.Shop) error {
+	if s.requireVerifiedShop && shop.VerificationStatus != entity.ShopVerified {
+		return ErrShopNotVerified
+	}
+	return nil
+}
+
 // ===============================================
 // 1. SHOP METHODS (dari ShopService)
 // ===============================================
 
 // @Summary      Create Seller Shop
-// @Description  Allows a registered Seller to create their shop. A user can only own one shop.
+// @Description  Allows a user with the shop:manage permission (e.g. a Seller) to create their shop. A user can only own one shop.
 // @Tags         Shop
 // @Accept       json
 // @Produce      json
@@ -64,16 +112,17 @@ func NewShopItemService(
 // @Param        input body entity.CreateShopInput true "Shop details (Name, Address, Description)"
 // @Success      201  {object}  entity.Shop
 // @Failure      400  {object}  map[string]interface{}
-// @Failure      403  {object}  map[string]interface{} "Forbidden (not seller)"
+// @Failure      403  {object}  map[string]interface{} "Forbidden (missing shop:manage)"
 // @Failure      409  {object}  map[string]interface{} "Conflict (shop already exists)"
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /shops [post]
-func (s *ShopItemService) CreateShop(userID uuid.UUID, role string, input entity.CreateShopInput) (*entity.Shop, error) {
-	if role != "seller" {
+func (s *ShopItemService) CreateShop(userID uuid.UUID, permissions []string, input entity.CreateShopInput) (*entity.Shop, error) {
+	if !HasPermission(permissions, entity.PermShopManage) {
 		return nil, ErrNotSeller
 	}
 
-	existing, err := s.shopRepo.GetByUserID(userID)
+	// staf toko lain juga tidak boleh membuat toko sendiri
+	existing, err := s.memberRepo.GetByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -98,38 +147,202 @@ func (s *ShopItemService) CreateShop(userID uuid.UUID, role string, input entity
 	return shop, nil
 }
 
+// Jumlah item aktif yang ditampilkan di halaman profil toko
+const shopProfileItemLimit = 50
+
+const (
+	shopDirectoryDefaultLimit = 20
+	shopDirectoryMaxLimit     = 100
+)
+
+// @Summary      Get Shop Profile
+// @Description  Public shop profile with its categories, active items and sales statistics. Shops whose owner is deactivated or deleted are not found.
+// @Tags         Shop
+// @Produce      json
+// @Param        id   path      string  true  "Shop ID"
+// @Success      200  {object}  entity.ShopProfile
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "Shop not found"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/{id} [get]
+func (s *ShopItemService) GetShopProfile(shopID uuid.UUID) (*entity.ShopProfile, error) {
+	// toko dengan pemilik nonaktif/terhapus tidak tampil, sama seperti di direktori
+	shop, err := s.shopRepo.GetActiveByID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+	return s.buildShopProfile(shop)
+}
+
+// @Summary      Get My Shop
+// @Description  Returns the profile of the shop owned by the logged-in seller.
+// @Tags         Shop
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  entity.ShopProfile
+// @Failure      404  {object}  map[string]interface{} "User does not own a shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me [get]
+func (s *ShopItemService) GetMyShop(userID uuid.UUID) (*entity.ShopProfile, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.buildShopProfile(shop)
+}
+
+func publicShop(shop *entity.Shop) entity.PublicShop {
+	return entity.PublicShop{
+		ID:                 shop.ID,
+		Name:               shop.Name,
+		Description:        shop.Description,
+		Address:            shop.Address,
+		LogoURL:            shop.LogoURL,
+		BannerURL:          shop.BannerURL,
+		VerificationStatus: shop.VerificationStatus,
+		VerifiedAt:         shop.VerifiedAt,
+		CreatedAt:          shop.CreatedAt,
+	}
+}
+
+func (s *ShopItemService) buildShopProfile(shop *entity.Shop) (*entity.ShopProfile, error) {
+	availability, err := loadShopAvailability(s.shopRepo, shop.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.shopRepo.GetShopStats(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.ListByShop(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.itemRepo.ListActiveByShop(shop.ID, shopProfileItemLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ShopProfile{
+		Shop:         publicShop(shop),
+		Availability: *availability,
+		Stats:        *stats,
+		Categories:   categories,
+		Items:        items,
+	}, nil
+}
+
+// @Summary      Update My Shop
+// @Description  Updates name, description and address of the seller's shop. Logo and banner images can be uploaded in the same multipart request; omitted fields are left unchanged. Replaced images are deleted, and uploads of a rejected request are not kept.
+// @Tags         Shop
+// @Accept       mpfd
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name formData string false "Shop Name"
+// @Param        description formData string false "Shop Description"
+// @Param        address formData string false "Shop Address"
+// @Param        logo formData file false "Logo image (jpg, png, webp)"
+// @Param        banner formData file false "Banner image (jpg, png, webp)"
+// @Success      200  {object}  entity.Shop
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "User does not own a shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me [patch]
+func (s *ShopItemService) UpdateShop(userID uuid.UUID, input entity.UpdateShopInput, logoURL, bannerURL string) (*entity.Shop, []string, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageShop)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, nil, ErrInvalidShopName
+		}
+		shop.Name = name
+	}
+	if input.Description != nil {
+		shop.Description = *input.Description
+	}
+	if input.Address != nil {
+		address := strings.TrimSpace(*input.Address)
+		if address == "" {
+			return nil, nil, ErrInvalidShopAddress
+		}
+		shop.Address = address
+	}
+	// gambar lama yang diganti dikembalikan agar filenya dihapus setelah update berhasil
+	var replaced []string
+	if logoURL != "" {
+		replaced = append(replaced, shop.LogoURL)
+		shop.LogoURL = logoURL
+	}
+	if bannerURL != "" {
+		replaced = append(replaced, shop.BannerURL)
+		shop.BannerURL = bannerURL
+	}
+
+	if err := s.shopRepo.UpdateShop(shop); err != nil {
+		return nil, nil, err
+	}
+	shop.UpdatedAt = time.Now()
+
+	return shop, replaced, nil
+}
+
+// @Summary      Shop Directory
+// @Description  Public list of shops, optionally filtered by name.
+// @Tags         Shop
+// @Produce      json
+// @Param        q      query  string  false  "Search by shop name"
+// @Param        limit  query  int     false  "Page size (default 20, max 100)"
+// @Param        offset query  int     false  "Offset"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops [get]
+func (s *ShopItemService) ListShops(filter entity.ShopFilter) ([]entity.ShopSummary, error) {
+	filter.Keyword = strings.TrimSpace(filter.Keyword)
+	if filter.Limit <= 0 {
+		filter.Limit = shopDirectoryDefaultLimit
+	}
+	if filter.Limit > shopDirectoryMaxLimit {
+		filter.Limit = shopDirectoryMaxLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.shopRepo.SearchShops(filter)
+}
+
 // ===============================================
 // 2. CATEGORY METHODS (dari CategoryService)
 // ===============================================
 
 // @Summary      Create New Shop Category
-// @Description  Allows a logged-in seller to create a new category unique to their shop.
+// @Description  Allows the shop owner or staff with the manage_items shop permission to create a new category unique to their shop, optionally nested under parent_id (at most 3 levels).
 // @Tags         Category
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        input body entity.CreateCategoryInput true "Category details (Name)"
+// @Param        input body entity.CreateCategoryInput true "Category details (Name, optional parent_id and sort_order)"
 // @Success      201  {object}  entity.Category
 // @Failure      400  {object}  map[string]interface{} "Invalid input or missing shop"
-// @Failure      403  {object}  map[string]interface{} "Forbidden (not seller)"
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items)"
 // @Failure      409  {object}  map[string]interface{} "Conflict (category name already exists)"
 // @Failure      500  {object}  map[string]interface{} "Internal server error"
 // @Router       /categories [post]
-func (s *ShopItemService) CreateCategory(userID uuid.UUID, role string, input entity.CreateCategoryInput) (*entity.Category, error) {
-
-	if role != "seller" {
-		return nil, ErrNotSeller
-	}
+func (s *ShopItemService) CreateCategory(userID uuid.UUID, input entity.CreateCategoryInput) (*entity.Category, error) {
 
-	shop, err := s.shopRepo.GetByUserID(userID) // Menggunakan shopRepo
+	// owner maupun staf dengan izin manage_items
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
 	if err != nil {
 		return nil, err
 	}
 
-	if shop == nil {
-		return nil, ErrNoShopOwned
-	}
-
 	exists, err := s.categoryRepo.ExistsByName(shop.ID, input.Name) // Menggunakan categoryRepo
 	if err != nil {
 		return nil, err
@@ -138,10 +351,25 @@ func (s *ShopItemService) CreateCategory(userID uuid.UUID, role string, input en
 		return nil, ErrCategoryExists
 	}
 
+	if input.ParentID != nil {
+		parent, idx, err := s.shopCategory(shop.ID, *input.ParentID)
+		if err == ErrCategoryNotFound {
+			return nil, ErrCategoryNotOwned
+		}
+		if err != nil {
+			return nil, err
+		}
+		if idx.depth(parent.ID) >= maxCategoryDepth {
+			return nil, ErrCategoryDepthExceeded
+		}
+	}
+
 	category := &entity.Category{
 		ID: uuid.New(),
 		ShopID: shop.ID,
+		ParentID: input.ParentID,
 		Name: input.Name,
+		SortOrder: input.SortOrder,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -159,7 +387,7 @@ func (s *ShopItemService) CreateCategory(userID uuid.UUID, role string, input en
 // ===============================================
 
 // @Summary      Create New Item
-// @Description  Allows a Seller to create a new item within their shop. Requires multipart/form-data for input and image upload.
+// @Description  Allows the shop owner or staff with the manage_items shop permission to create a new item within their shop. Requires multipart/form-data for input and image upload.
 // @Tags         Seller/Items
 // @Accept       mpfd
 // @Produce      json
@@ -170,24 +398,22 @@ func (s *ShopItemService) CreateCategory(userID uuid.UUID, role string, input en
 // @Param        stock formData integer true "Initial Stock"
 // @Param        condition formData string false "Item Condition"
 // @Param        category_id formData string true "Category ID (UUID) owned by the shop"
+// @Param        global_category_id formData string false "Global taxonomy category ID (UUID) for cross-shop browsing"
 // @Param        images formData file true "Item Images"
 // @Success      201  {object}  map[string]interface{} "Returns created item and image URLs"
-// @Failure      400  {object}  map[string]interface{}
-// @Failure      403  {object}  map[string]interface{} "Forbidden (not seller)"
+// @Failure      400  {object}  map[string]interface{} "Invalid price, stock, category or global category"
+// @Failure      403  {object}  map[string]interface{} "Forbidden (shop role lacks manage_items, or shop not verified while the verified-shop policy is on)"
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /items [post]
-func (s *ShopItemService) CreateItem(userID uuid.UUID, role string, input entity.CreateItemInput, imageURLs []string) (*entity.Item, []entity.ItemImage, error) {
-
-	if role != "seller" {
-		return nil, nil, ErrNotSeller
-	}
+func (s *ShopItemService) CreateItem(userID uuid.UUID, input entity.CreateItemInput, imageURLs []string) (*entity.Item, []entity.ItemImage, error) {
 
-	shop, err := s.shopRepo.GetByUserID(userID)
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
 	if err != nil {
 		return nil, nil, err
 	}
-	if shop == nil {
-		return nil, nil, ErrNoShopOwned
+	if err := s.checkCanList(shop); err != nil {
+		return nil, nil, err
 	}
 
 	
@@ -205,11 +431,15 @@ func (s *ShopItemService) CreateItem(userID uuid.UUID, role string, input entity
 	if input.Price < 0 {
 		return nil, nil, ErrInvalidPrice
 	}
+	if err := s.checkGlobalCategory(input.GlobalCategoryID); err != nil {
+		return nil, nil, err
+	}
 
 	item := &entity.Item{
 		ID: uuid.New(),
 		ShopID: shop.ID,
 		CategoryID: input.CategoryID,
+		GlobalCategoryID: input.GlobalCategoryID,
 		Name: input.Name,
 		Description: input.Description,
 		Price: input.Price,
@@ -246,7 +476,7 @@ func (s *ShopItemService) CreateItem(userID uuid.UUID, role string, input entity
 }
 
 // @Summary      Update Item Details
-// @Description  Allows a Seller to update item fields (name, price, stock, status, etc.) for an item they own.
+// @Description  Allows a Seller to update item fields (name, price, stock, status, category, etc.) for an item they own. Omitting global_category_id keeps the current mapping; send clear_global_category=true to remove it. Drafts cannot be activated here; use /items/{id}/publish. Can also be called with a shop API key (X-API-Key header, scope items:write).
 // @Tags         Seller/Items
 // @Accept       json
 // @Produce      json
@@ -269,13 +499,10 @@ func (s *ShopItemService) UpdateItem(userID uuid.UUID, itemID uuid.UUID, input e
 		return nil, errors.New("item not found")
 	}
 
-	shop, err := s.shopRepo.GetByUserID(userID)
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
 	if err != nil {
 		return nil, err
 	}
-	if shop == nil {
-		return nil, errors.New("you do not have a shop")
-	}
 
 	if item.ShopID != shop.ID {
 		return nil, errors.New("unauthorized: this item does not belong to your shop")
@@ -288,8 +515,39 @@ func (s *ShopItemService) UpdateItem(userID uuid.UUID, itemID uuid.UUID, input e
 	item.Stock = input.Stock
 	item.Condition = input.Condition
 
+	if input.CategoryID != nil {
+		owned, err := s.shopRepo.IsCategoryOwnedByShop(*input.CategoryID, shop.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !owned {
+			return nil, ErrCategoryNotOwned
+		}
+		item.CategoryID = *input.CategoryID
+	}
+
+	switch {
+	case input.ClearGlobalCategory && input.GlobalCategoryID != nil:
+		return nil, ErrGlobalCategoryConflict
+	case input.ClearGlobalCategory:
+		item.GlobalCategoryID = nil
+	case input.GlobalCategoryID != nil:
+		if err := s.checkGlobalCategory(input.GlobalCategoryID); err != nil {
+			return nil, err
+		}
+		item.GlobalCategoryID = input.GlobalCategoryID
+	}
+
 	
 	if input.Status != "" {
+		if err := checkSellerStatusChange(item, input.Status); err != nil {
+			return nil, err
+		}
+		if input.Status == "active" && item.Status != "active" {
+			if err := s.checkCanList(shop); err != nil {
+				return nil, err
+			}
+		}
 		item.Status = input.Status
 	}
 
@@ -321,13 +579,16 @@ func (s *ShopItemService) DeleteItem(userID uuid.UUID, itemID uuid.UUID) error {
 		return errors.New("item not found")
 	}
 
-	shop, err := s.shopRepo.GetByUserID(userID)
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, entity.ShopPermManageItems)
 	if err != nil {
 		return err
 	}
-	if shop == nil || item.ShopID != shop.ID {
+	if item.ShopID != shop.ID {
 		return errors.New("unauthorized")
 	}
+	if err := checkSellerStatusChange(item, entity.ItemStatusInactive); err != nil {
+		return err
+	}
 
 	item.Status = "inactive"
 	return s.itemRepo.UpdateItem(item)
@@ -349,7 +610,7 @@ func (s *ShopItemService) GetItemDetail(itemID uuid.UUID) (*entity.Item, error)
 	if err != nil {
 		return nil, err
 	}
-	if item == nil || item.Status != "active" {
+	if item == nil || item.Status != "active" || (s.requireVerifiedShop && !item.ShopVerified) {
 		return nil, errors.New("item not found or inactive")
 	}
 	return item, nil
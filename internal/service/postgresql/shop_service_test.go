@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	entity "home-market/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateShopImages(t *testing.T) {
+	tests := []struct {
+		name               string
+		userID             func(f *shopFixture) uuid.UUID
+		logoURL, bannerURL string
+		wantErr            error
+		wantReplaced       []string
+	}{
+		{name: "no new images"},
+		{name: "new logo", logoURL: "/uploads/shops/new-logo.png", wantReplaced: []string{"/uploads/shops/old-logo.png"}},
+		{
+			name: "new logo and banner", logoURL: "/uploads/shops/new-logo.png", bannerURL: "/uploads/shops/new-banner.png",
+			wantReplaced: []string{"/uploads/shops/old-logo.png", "/uploads/shops/old-banner.png"},
+		},
+		{
+			name: "staff without manage_shop", logoURL: "/uploads/shops/new-logo.png",
+			userID:  func(f *shopFixture) uuid.UUID { return f.staffID },
+			wantErr: ErrShopPermissionDenied,
+		},
+		{
+			name: "user without a shop", logoURL: "/uploads/shops/new-logo.png",
+			userID:  func(f *shopFixture) uuid.UUID { return uuid.New() },
+			wantErr: ErrNoShopOwned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, false)
+			f.shop.LogoURL = "/uploads/shops/old-logo.png"
+			f.shop.BannerURL = "/uploads/shops/old-banner.png"
+			userID := f.ownerID
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+
+			shop, replaced, err := f.service.UpdateShop(userID, entity.UpdateShopInput{}, tt.logoURL, tt.bannerURL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(replaced, tt.wantReplaced) {
+				t.Fatalf("replaced = %v, want %v", replaced, tt.wantReplaced)
+			}
+			if err == nil && tt.logoURL != "" && shop.LogoURL != tt.logoURL {
+				t.Fatalf("logo = %q, want %q", shop.LogoURL, tt.logoURL)
+			}
+		})
+	}
+}
+
+func TestCreateShopPermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		userID      func(f *shopFixture) uuid.UUID
+		want        error
+	}{
+		{name: "custom role with shop:manage", permissions: []string{entity.PermShopManage}},
+		{name: "without shop:manage", permissions: []string{entity.PermOfferCreate}, want: ErrNotSeller},
+		{
+			name: "staff of another shop", permissions: []string{entity.PermShopManage},
+			userID: func(f *shopFixture) uuid.UUID { return f.staffID },
+			want:   ErrShopExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newShopFixture(t, false)
+			userID := uuid.New()
+			if tt.userID != nil {
+				userID = tt.userID(f)
+			}
+
+			shop, err := f.service.CreateShop(userID, tt.permissions, entity.CreateShopInput{Name: "Toko Budi", Address: "Jl. Mawar 1"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err == nil && shop.UserID != userID {
+				t.Fatalf("shop owner = %s, want %s", shop.UserID, userID)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	entity "home-market/internal/domain"
+	mongorepo "home-market/internal/repository/mongodb"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShopAlreadyVerified       = errors.New("shop is already verified")
+	ErrVerificationPending       = errors.New("a verification request for this shop is already pending review")
+	ErrIdentityDocumentRequired  = errors.New("an identity document is required")
+	ErrVerificationNotFound      = errors.New("no verification request found for this shop")
+	ErrVerificationNotPending    = errors.New("verification request is no longer pending")
+	ErrVerificationDocNotFound   = errors.New("verification document not found")
+	ErrRejectionNoteRequired     = errors.New("a note explaining the rejection is required")
+	ErrShopNotVerified           = errors.New("only verified shops can list items")
+	ErrInvalidVerificationStatus = errors.New("verification_status must be one of unverified, pending, verified, rejected")
+)
+
+type ShopVerificationService struct {
+	verificationRepo repo.ShopVerificationRepository
+	shopRepo         repo.ShopRepository
+	memberRepo       repo.ShopMemberRepository
+	logRepo          mongorepo.LogRepository
+}
+
+func NewShopVerificationService(
+	verificationRepo repo.ShopVerificationRepository,
+	shopRepo repo.ShopRepository,
+	memberRepo repo.ShopMemberRepository,
+	logRepo mongorepo.LogRepository,
+) *ShopVerificationService {
+	return &ShopVerificationService{
+		verificationRepo: verificationRepo,
+		shopRepo:         shopRepo,
+		memberRepo:       memberRepo,
+		logRepo:          logRepo,
+	}
+}
+
+// ownerShop: dokumen KYC adalah data pribadi pemilik, jadi hanya owner (bukan staf) yang boleh mengajukan.
+func (s *ShopVerificationService) ownerShop(userID uuid.UUID) (*entity.Shop, error) {
+	shop, member, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != entity.ShopRoleOwner {
+		return nil, ErrShopPermissionDenied
+	}
+	return shop, nil
+}
+
+// CanSubmit dipanggil handler sebelum menyimpan file agar dokumen tidak tertulis ke disk untuk pengajuan yang pasti ditolak.
+func (s *ShopVerificationService) CanSubmit(userID uuid.UUID) error {
+	shop, err := s.ownerShop(userID)
+	if err != nil {
+		return err
+	}
+	return checkSubmittable(shop)
+}
+
+func checkSubmittable(shop *entity.Shop) error {
+	switch shop.VerificationStatus {
+	case entity.ShopVerified:
+		return ErrShopAlreadyVerified
+	case entity.ShopPending:
+		return ErrVerificationPending
+	}
+	return nil
+}
+
+// @Summary      Submit Shop Verification
+// @Description  Allows the shop owner to submit identity (required) and business (optional) documents for verification. Documents are stored privately and only visible to admins. Requires multipart/form-data.
+// @Tags         Shop
+// @Accept       mpfd
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        identity formData file true "Identity document (PDF, JPG or PNG)"
+// @Param        business formData file false "Business document (PDF, JPG or PNG)"
+// @Param        note formData string false "Note for the reviewer"
+// @Success      201  {object}  entity.ShopVerification
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Only the shop owner can submit"
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
+// @Failure      409  {object}  map[string]interface{} "Already verified or a request is pending"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/verification [post]
+func (s *ShopVerificationService) Submit(userID uuid.UUID, note string, docs []entity.VerificationDocument) (*entity.ShopVerification, error) {
+	shop, err := s.ownerShop(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSubmittable(shop); err != nil {
+		return nil, err
+	}
+
+	hasIdentity := false
+	for _, doc := range docs {
+		if doc.DocType == entity.VerificationDocIdentity {
+			hasIdentity = true
+		}
+	}
+	if !hasIdentity {
+		return nil, ErrIdentityDocumentRequired
+	}
+
+	now := time.Now()
+	v := &entity.ShopVerification{
+		ID:          uuid.New(),
+		ShopID:      shop.ID,
+		ShopName:    shop.Name,
+		SubmittedBy: userID,
+		Note:        strings.TrimSpace(note),
+		Status:      "pending",
+		CreatedAt:   now,
+		Documents:   docs,
+	}
+	for i := range v.Documents {
+		v.Documents[i].ID = uuid.New()
+		v.Documents[i].VerificationID = v.ID
+		v.Documents[i].CreatedAt = now
+	}
+
+	if err := s.verificationRepo.Submit(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// @Summary      Get My Shop Verification Status
+// @Description  Returns the verification status of the caller's shop and the latest submission, including the reviewer's note when rejected.
+// @Tags         Shop
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  entity.ShopVerificationStatus
+// @Failure      404  {object}  map[string]interface{} "User has no shop"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /shops/me/verification [get]
+func (s *ShopVerificationService) GetMyVerification(userID uuid.UUID) (*entity.ShopVerificationStatus, error) {
+	shop, _, err := resolveShop(s.shopRepo, s.memberRepo, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.verificationRepo.GetLatestByShop(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ShopVerificationStatus{
+		Status:     shop.VerificationStatus,
+		VerifiedAt: shop.VerifiedAt,
+		Latest:     latest,
+	}, nil
+}
+
+// @Summary      List Shops for Verification Review
+// @Description  Admin queue of shops, optionally filtered by verification_status (unverified, pending, verified, rejected). Pending submissions are listed oldest first.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        verification_status query string false "Filter by verification status"
+// @Param        limit query integer false "Limit (default 20, max 100)"
+// @Param        offset query integer false "Offset"
+// @Success      200  {array}   entity.AdminShopEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/shops [get]
+func (s *ShopVerificationService) ListShops(filter entity.AdminShopFilter) ([]entity.AdminShopEntry, error) {
+	switch filter.VerificationStatus {
+	case "", entity.ShopUnverified, entity.ShopPending, entity.ShopVerified, entity.ShopRejected:
+	default:
+		return nil, ErrInvalidVerificationStatus
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.verificationRepo.ListShops(filter)
+}
+
+// @Summary      Get Shop Verification Request
+// @Description  Returns the latest verification request of a shop with its document list.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Shop ID"
+// @Success      200  {object}  entity.ShopVerification
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/shops/{id}/verification [get]
+func (s *ShopVerificationService) GetShopVerification(shopID uuid.UUID) (*entity.ShopVerification, error) {
+	v, err := s.verificationRepo.GetLatestByShop(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrVerificationNotFound
+	}
+	return v, nil
+}
+
+// @Summary      Download Shop Verification Document
+// @Description  Streams a private verification document of the shop's latest request to the reviewing admin.
+// @Tags         Admin
+// @Produce      octet-stream
+// @Security     ApiKeyAuth
+// @Param        id     path  string  true  "Shop ID"
+// @Param        docId  path  string  true  "Document ID"
+// @Success      200  {file}    file
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/shops/{id}/verification/documents/{docId} [get]
+func (s *ShopVerificationService) GetDocument(shopID, documentID uuid.UUID) (*entity.VerificationDocument, error) {
+	v, err := s.GetShopVerification(shopID)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.verificationRepo.GetDocument(v.ID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrVerificationDocNotFound
+	}
+	return doc, nil
+}
+
+// @Summary      Approve Shop Verification
+// @Description  Approves the shop's pending verification request. The shop gets the verified badge and the owner is notified.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Shop ID"
+// @Param        input body entity.ReviewShopVerificationInput false "Optional note"
+// @Success      200  {object}  entity.ShopVerification
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Request is not pending"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/shops/{id}/verification/approve [post]
+func (s *ShopVerificationService) Approve(adminID, shopID uuid.UUID, input entity.ReviewShopVerificationInput) (*entity.ShopVerification, error) {
+	return s.review(adminID, shopID, true, strings.TrimSpace(input.Note))
+}
+
+// @Summary      Reject Shop Verification
+// @Description  Rejects the shop's pending verification request with a note. The owner is notified and may submit again.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Shop ID"
+// @Param        input body entity.ReviewShopVerificationInput true "Reason for rejection"
+// @Success      200  {object}  entity.ShopVerification
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Request is not pending"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/shops/{id}/verification/reject [post]
+func (s *ShopVerificationService) Reject(adminID, shopID uuid.UUID, input entity.ReviewShopVerificationInput) (*entity.ShopVerification, error) {
+	note := strings.TrimSpace(input.Note)
+	if note == "" {
+		return nil, ErrRejectionNoteRequired
+	}
+	return s.review(adminID, shopID, false, note)
+}
+
+func (s *ShopVerificationService) review(adminID, shopID uuid.UUID, approved bool, note string) (*entity.ShopVerification, error) {
+	v, err := s.GetShopVerification(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != "pending" {
+		return nil, ErrVerificationNotPending
+	}
+
+	ok, err := s.verificationRepo.Review(v.ID, adminID, approved, note)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrVerificationNotPending
+	}
+
+	v, err = s.verificationRepo.GetLatestByShop(shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	shop, err := s.shopRepo.GetByID(shopID)
+	if err == nil && shop != nil {
+		if approved {
+			saveNotification(s.logRepo, shop.UserID, "Toko Terverifikasi", fmt.Sprintf("Selamat, toko '%s' telah terverifikasi.", shop.Name), "shop_verification", v.ID)
+		} else {
+			saveNotification(s.logRepo, shop.UserID, "Verifikasi Toko Ditolak", fmt.Sprintf("Pengajuan verifikasi toko '%s' ditolak: %s", shop.Name, note), "shop_verification", v.ID)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	entity "home-market/internal/domain"
+	repo "home-market/internal/repository/postgresql"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGlobalCategoryNotFound  = errors.New("global category not found")
+	ErrInvalidGlobalCategory   = errors.New("global_category_id does not exist")
+	ErrInvalidSlug             = errors.New("slug may only contain lowercase letters, digits and single dashes")
+	ErrSlugExists              = errors.New("slug is already used by another global category")
+	ErrGlobalCategoryInUse     = errors.New("global category still has items; pass reassign_to to move them to another global category")
+	ErrInvalidGlobalReassignTo = errors.New("reassign_to must be another existing global category")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugify membentuk slug dari nama, mis. "Meja & Kursi" -> "meja-kursi".
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+type TaxonomyService struct {
+	globalCategoryRepo repo.GlobalCategoryRepository
+}
+
+func NewTaxonomyService(globalCategoryRepo repo.GlobalCategoryRepository) *TaxonomyService {
+	return &TaxonomyService{globalCategoryRepo: globalCategoryRepo}
+}
+
+func newGlobalCategoryIndex(categories []entity.GlobalCategory) categoryIndex {
+	var idx categoryIndex
+	for _, c := range categories {
+		idx.add(c.ID, c.ParentID)
+	}
+	return idx
+}
+
+func globalCategoryTree(parent *uuid.UUID, categories []entity.GlobalCategory) []entity.GlobalCategoryNode {
+	nodes := []entity.GlobalCategoryNode{}
+	for _, c := range categories {
+		if sameParent(parent, c.ParentID) {
+			id := c.ID
+			nodes = append(nodes, entity.GlobalCategoryNode{GlobalCategory: c, Children: globalCategoryTree(&id, categories)})
+		}
+	}
+	return nodes
+}
+
+func (s *TaxonomyService) checkSlug(slug string, excludeID uuid.UUID) error {
+	if !slugPattern.MatchString(slug) {
+		return ErrInvalidSlug
+	}
+	exists, err := s.globalCategoryRepo.SlugExists(slug, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrSlugExists
+	}
+	return nil
+}
+
+// @Summary      Get Global Category Tree
+// @Description  Returns the platform-wide category taxonomy as a tree. Use a category id as global_category_id in /market/items to browse items across all shops.
+// @Tags         Marketplace
+// @Produce      json
+// @Success      200  {array}   entity.GlobalCategoryNode
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /taxonomy [get]
+func (s *TaxonomyService) GetTree() ([]entity.GlobalCategoryNode, error) {
+	categories, err := s.globalCategoryRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	return globalCategoryTree(nil, categories), nil
+}
+
+// @Summary      Create Global Category
+// @Description  Adds a category to the platform-wide taxonomy, optionally under parent_id (at most 3 levels). The slug is derived from the name when omitted.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        input body entity.CreateGlobalCategoryInput true "Global category"
+// @Success      201  {object}  entity.GlobalCategory
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Slug already used"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/taxonomy [post]
+func (s *TaxonomyService) Create(input entity.CreateGlobalCategoryInput) (*entity.GlobalCategory, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrInvalidCategoryName
+	}
+	slug := strings.TrimSpace(input.Slug)
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if err := s.checkSlug(slug, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil {
+		categories, err := s.globalCategoryRepo.List()
+		if err != nil {
+			return nil, err
+		}
+		idx := newGlobalCategoryIndex(categories)
+		if !idx.has(*input.ParentID) {
+			return nil, ErrGlobalCategoryNotFound
+		}
+		if idx.depth(*input.ParentID) >= maxCategoryDepth {
+			return nil, ErrCategoryDepthExceeded
+		}
+	}
+
+	now := time.Now()
+	c := &entity.GlobalCategory{
+		ID:        uuid.New(),
+		ParentID:  input.ParentID,
+		Name:      name,
+		Slug:      slug,
+		SortOrder: input.SortOrder,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.globalCategoryRepo.Create(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// @Summary      Update Global Category
+// @Description  Renames, re-slugs, reorders or moves a global category. Set move_to_root to make it a top-level category.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      string  true  "Global category ID"
+// @Param        input body entity.UpdateGlobalCategoryInput true "Fields to change"
+// @Success      200  {object}  entity.GlobalCategory
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Slug already used"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/taxonomy/{id} [patch]
+func (s *TaxonomyService) Update(id uuid.UUID, input entity.UpdateGlobalCategoryInput) (*entity.GlobalCategory, error) {
+	categories, err := s.globalCategoryRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	var c *entity.GlobalCategory
+	for i := range categories {
+		if categories[i].ID == id {
+			c = &categories[i]
+		}
+	}
+	if c == nil {
+		return nil, ErrGlobalCategoryNotFound
+	}
+	idx := newGlobalCategoryIndex(categories)
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidCategoryName
+		}
+		c.Name = name
+	}
+	if input.Slug != nil {
+		slug := strings.TrimSpace(*input.Slug)
+		if slug != c.Slug {
+			if err := s.checkSlug(slug, c.ID); err != nil {
+				return nil, err
+			}
+			c.Slug = slug
+		}
+	}
+
+	if input.MoveToRoot {
+		c.ParentID = nil
+	} else if input.ParentID != nil {
+		parentID := *input.ParentID
+		if !idx.has(parentID) {
+			return nil, ErrGlobalCategoryNotFound
+		}
+		if err := idx.checkMove(c.ID, parentID); err != nil {
+			return nil, err
+		}
+		c.ParentID = &parentID
+	}
+
+	if input.SortOrder != nil {
+		c.SortOrder = *input.SortOrder
+	}
+
+	c.UpdatedAt = time.Now()
+	if err := s.globalCategoryRepo.Update(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// @Summary      Delete Global Category
+// @Description  Deletes a global category. Sub-categories move up to its parent. Items mapped to it must be moved with reassign_to, otherwise the request is rejected.
+// @Tags         Admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id           path   string  true   "Global category ID"
+// @Param        reassign_to  query  string  false  "Global category ID that receives the items"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Global category still has items"
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/taxonomy/{id} [delete]
+func (s *TaxonomyService) Delete(id uuid.UUID, reassignTo *uuid.UUID) error {
+	c, err := s.globalCategoryRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return ErrGlobalCategoryNotFound
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == c.ID {
+			return ErrInvalidGlobalReassignTo
+		}
+		target, err := s.globalCategoryRepo.GetByID(*reassignTo)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return ErrInvalidGlobalReassignTo
+		}
+	} else {
+		n, err := s.globalCategoryRepo.CountItems(c.ID)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrGlobalCategoryInUse
+		}
+	}
+
+	return s.globalCategoryRepo.Delete(c, reassignTo)
+}
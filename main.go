@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"log"
 	config "home-market/internal/config"
 	_ "database/sql"
 	"home-market/internal/delivery/http/route"
+	"home-market/pkg"
 )
 
 // @title           Home Market API
@@ -22,6 +24,11 @@ func main() {
 	//1. Load .env file
 	config.LoadEnv()
 
+	// Muat key JWT di awal agar konfigurasi yang salah langsung gagal
+	if err := utils.InitSigningKeys(); err != nil {
+		log.Fatalf("failed to load JWT signing keys: %v", err)
+	}
+
 	//2. Connect to Database
 
 	// Connect to PostgreSQL
@@ -36,9 +43,12 @@ func main() {
 	var app = config.SetupGin()
 
 	//4. Initialize Routes
-	route.SetupRoute(app, config.PostgresDB, mongoClient)
+	privacyService := route.SetupRoute(app, config.PostgresDB, mongoClient)
 	fmt.Println("Setup route berhasil")
 
-	//5. Run the server
+	//5. Start background workers
+	privacyService.StartDeletionWorker(config.LoadPrivacy().DeletionSweepInterval)
+
+	//6. Run the server
 	config.SetupServer(app)
 }
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Versi token per user; dinaikkan saat user diblokir, ganti role, atau ganti password
+-- sehingga access token lama langsung ditolak oleh middleware.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
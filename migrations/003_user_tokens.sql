@@ -0,0 +1,13 @@
+-- Token sekali pakai milik user (reset password, verifikasi email, dll). Hanya hash yang disimpan.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose     VARCHAR(50) NOT NULL,
+    token_hash  TEXT NOT NULL UNIQUE,
+    payload     TEXT NOT NULL DEFAULT '',
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
@@ -0,0 +1,13 @@
+-- Akun yang sudah ada sebelum fitur verifikasi dianggap terverifikasi. Backfill hanya
+-- dijalankan saat kolom baru dibuat, sehingga menjalankan ulang migrasi ini tidak
+-- ikut memverifikasi akun yang mendaftar setelahnya.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+        UPDATE users SET email_verified_at = created_at;
+    END IF;
+END $$;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS role_applications (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id     UUID NOT NULL REFERENCES roles(id),
+    reason      TEXT NOT NULL,
+    status      VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    reviewed_by UUID REFERENCES users(id),
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_applications_status ON role_applications(status);
+CREATE INDEX IF NOT EXISTS idx_role_applications_user_id ON role_applications(user_id);
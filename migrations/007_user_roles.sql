@@ -0,0 +1,11 @@
+-- User bisa memiliki beberapa role sekaligus. users.role_id tetap dipakai sebagai role utama.
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id    UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO user_roles (user_id, role_id, created_at)
+SELECT id, role_id, NOW() FROM users
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,40 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uq_roles_name ON roles(name);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_permissions_name ON permissions(name);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_role_permissions ON role_permissions(role_id, permission_id);
+
+INSERT INTO permissions (id, name, resource, action, description) VALUES
+    (gen_random_uuid(), 'user:read',               'user',             'read',          'List user accounts'),
+    (gen_random_uuid(), 'user:manage',             'user',             'manage',        'Block/unblock users and assign roles'),
+    (gen_random_uuid(), 'item:moderate',           'item',             'moderate',      'Take down items that violate policy'),
+    (gen_random_uuid(), 'order:create',            'order',            'create',        'Place orders in the marketplace'),
+    (gen_random_uuid(), 'order:update_status',     'order',            'update_status', 'Update status and shipping of orders'),
+    (gen_random_uuid(), 'order:manage_any',        'order',            'manage_any',    'Manage orders of any shop'),
+    (gen_random_uuid(), 'order:read_any',          'order',            'read_any',      'Track orders of any buyer'),
+    (gen_random_uuid(), 'offer:create',            'offer',            'create',        'Offer items to sellers'),
+    (gen_random_uuid(), 'role:manage',             'role',             'manage',        'Manage roles, permissions and grants'),
+    (gen_random_uuid(), 'role_application:review', 'role_application', 'review',        'Approve or reject role applications')
+ON CONFLICT (name) DO NOTHING;
+
+-- admin mendapat semua permission di atas
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name IN (
+    'user:read', 'user:manage', 'item:moderate', 'order:update_status', 'order:manage_any',
+    'order:read_any', 'role:manage', 'role_application:review'
+)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'order:create'
+WHERE r.name = 'buyer'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'order:update_status'
+WHERE r.name = 'seller'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'offer:create'
+WHERE r.name = 'giver'
+ON CONFLICT DO NOTHING;
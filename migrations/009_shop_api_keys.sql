@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS shop_api_keys (
+    id                  UUID PRIMARY KEY,
+    shop_id             UUID NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    created_by          UUID NOT NULL REFERENCES users(id),
+    name                VARCHAR(100) NOT NULL,
+    key_prefix          VARCHAR(16) NOT NULL,          -- ditampilkan di list agar key bisa dikenali
+    key_hash            VARCHAR(64) NOT NULL UNIQUE,   -- sha256 hex, key mentah tidak disimpan
+    scopes              TEXT[] NOT NULL DEFAULT '{}',
+    rate_limit_per_hour INT NOT NULL DEFAULT 1000,
+    last_used_at        TIMESTAMPTZ,
+    revoked_at          TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shop_api_keys_shop_id ON shop_api_keys(shop_id);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS account_deletion_requests (
+    id            UUID PRIMARY KEY,
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    scheduled_for TIMESTAMPTZ NOT NULL,  -- akhir masa tenggang, setelah ini data dianonimkan
+    cancelled_at  TIMESTAMPTZ,
+    completed_at  TIMESTAMPTZ
+);
+
+-- hanya satu permintaan aktif per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_deletion_pending
+    ON account_deletion_requests(user_id)
+    WHERE cancelled_at IS NULL AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_account_deletion_due
+    ON account_deletion_requests(scheduled_for)
+    WHERE cancelled_at IS NULL AND completed_at IS NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS logo_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS banner_url TEXT NOT NULL DEFAULT '';
+
+-- urutan direktori toko berdasarkan nama
+CREATE INDEX IF NOT EXISTS idx_shops_lower_name ON shops (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_items_shop_id_status ON items (shop_id, status);
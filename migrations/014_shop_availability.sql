@@ -0,0 +1,17 @@
+-- Mode libur toko. vacation_until NULL berarti libur sampai dimatikan manual.
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS vacation_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS vacation_until TIMESTAMPTZ;
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS vacation_message TEXT NOT NULL DEFAULT '';
+-- Zona waktu jam operasional dalam menit dari UTC (default WIB)
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS utc_offset_minutes INTEGER NOT NULL DEFAULT 420;
+
+-- Jam operasional mingguan. Toko tanpa baris di sini dianggap buka setiap saat.
+-- weekday 0 = Minggu, menit dihitung dari 00:00 (close_minute maksimal 1440).
+CREATE TABLE IF NOT EXISTS shop_operating_hours (
+    shop_id      UUID     NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    weekday      SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    open_minute  SMALLINT NOT NULL CHECK (open_minute BETWEEN 0 AND 1439),
+    close_minute SMALLINT NOT NULL CHECK (close_minute BETWEEN 1 AND 1440),
+    PRIMARY KEY (shop_id, weekday, open_minute),
+    CHECK (open_minute < close_minute)
+);
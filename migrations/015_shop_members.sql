@@ -0,0 +1,32 @@
+-- Anggota toko. Satu user hanya bisa menjadi anggota satu toko agar /shops/me tetap jelas.
+CREATE TABLE IF NOT EXISTS shop_members (
+    shop_id    UUID        NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'packer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shop_id, user_id),
+    UNIQUE (user_id)
+);
+
+-- pemilik toko yang sudah ada menjadi anggota dengan role owner
+INSERT INTO shop_members (shop_id, user_id, role, created_at, updated_at)
+SELECT id, user_id, 'owner', created_at, NOW() FROM shops
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS shop_invitations (
+    id           UUID PRIMARY KEY,
+    shop_id      UUID        NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role         VARCHAR(20) NOT NULL CHECK (role IN ('manager', 'packer')),
+    invited_by   UUID        NOT NULL REFERENCES users(id),
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, declined, revoked, expired
+    expires_at   TIMESTAMPTZ NOT NULL,
+    responded_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- paling banyak satu undangan pending per user per toko
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_invitations_pending
+    ON shop_invitations (shop_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_shop_invitations_user_id ON shop_invitations (user_id);
@@ -0,0 +1,44 @@
+-- Status verifikasi toko: unverified, pending, verified, rejected
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS verification_status VARCHAR(20) NOT NULL DEFAULT 'unverified';
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS shop_verifications (
+    id           UUID PRIMARY KEY,
+    shop_id      UUID        NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    submitted_by UUID        NOT NULL REFERENCES users(id),
+    note         TEXT        NOT NULL DEFAULT '',
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    reviewed_by  UUID REFERENCES users(id),
+    review_note  TEXT        NOT NULL DEFAULT '',
+    reviewed_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- paling banyak satu pengajuan pending per toko
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shop_verifications_pending
+    ON shop_verifications (shop_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_shop_verifications_status ON shop_verifications (status, created_at);
+
+-- storage_path relatif terhadap direktori penyimpanan privat (SHOP_KYC_STORAGE_DIR), tidak pernah diekspos publik
+CREATE TABLE IF NOT EXISTS shop_verification_documents (
+    id              UUID PRIMARY KEY,
+    verification_id UUID         NOT NULL REFERENCES shop_verifications(id) ON DELETE CASCADE,
+    doc_type        VARCHAR(20)  NOT NULL, -- identity, business
+    storage_path    TEXT         NOT NULL,
+    original_name   VARCHAR(255) NOT NULL DEFAULT '',
+    content_type    VARCHAR(100) NOT NULL DEFAULT '',
+    size_bytes      BIGINT       NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shop_verification_documents_verification_id
+    ON shop_verification_documents (verification_id);
+
+INSERT INTO permissions (id, name, resource, action, description) VALUES
+    (gen_random_uuid(), 'shop:verify', 'shop', 'verify', 'Review shop verification documents')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'shop:verify'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
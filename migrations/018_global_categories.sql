@@ -0,0 +1,25 @@
+-- Taksonomi global lintas toko, dikelola admin; item dipetakan ke satu kategori global
+-- di samping kategori milik tokonya sendiri.
+CREATE TABLE IF NOT EXISTS global_categories (
+    id         UUID PRIMARY KEY,
+    parent_id  UUID REFERENCES global_categories(id) ON DELETE RESTRICT,
+    name       VARCHAR(100) NOT NULL,
+    slug       VARCHAR(120) NOT NULL UNIQUE,
+    sort_order INT          NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_global_categories_parent_id ON global_categories (parent_id);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS global_category_id UUID REFERENCES global_categories(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_items_global_category_id ON items (global_category_id);
+
+INSERT INTO permissions (id, name, resource, action, description) VALUES
+    (gen_random_uuid(), 'taxonomy:manage', 'taxonomy', 'manage', 'Manage the platform-wide category taxonomy')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'taxonomy:manage'
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,6 @@
+-- Draft dari penawaran belum punya kategori sampai seller melengkapinya sebelum publish
+ALTER TABLE items ALTER COLUMN category_id DROP NOT NULL;
+UPDATE items SET category_id = NULL WHERE category_id = '00000000-0000-0000-0000-000000000000';
+
+-- Penawaran asal draft, dipakai untuk menyalin foto penawaran ke item_images saat publish
+ALTER TABLE items ADD COLUMN IF NOT EXISTS source_offer_id UUID REFERENCES offers(id) ON DELETE SET NULL;
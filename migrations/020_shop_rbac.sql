@@ -0,0 +1,17 @@
+-- Keanggotaan toko tetap tunduk pada RBAC global: owner butuh shop:manage (dan
+-- order:update_status untuk memproses order), staf butuh shop:staff.
+INSERT INTO permissions (id, name, resource, action, description) VALUES
+    (gen_random_uuid(), 'shop:manage', 'shop', 'manage', 'Run an own shop: profile, items, categories, staff and API keys'),
+    (gen_random_uuid(), 'shop:staff',  'shop', 'staff',  'Work in another user''s shop as invited staff')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'shop:manage'
+WHERE r.name = 'seller'
+ON CONFLICT DO NOTHING;
+
+-- staf bisa diundang dari role apa pun; admin mencabutnya per role lewat role_permissions
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'shop:staff'
+WHERE r.name IN ('buyer', 'seller', 'giver')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- Status 2FA disimpan per refresh token family. Family lama dianggap belum lolos 2FA,
+-- sehingga admin yang diwajibkan 2FA perlu login ulang sekali setelah migrasi ini.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Time step TOTP terakhir yang diterima; kode dengan step yang sama atau lebih lama ditolak.
+ALTER TABLE user_mfa ADD COLUMN IF NOT EXISTS last_used_step BIGINT;
@@ -0,0 +1,21 @@
+-- Draft yang dibuat sebelum 019 belum punya source_offer_id sehingga foto penawarannya
+-- tidak tersalin saat publish. Draft ditautkan ke penawarannya hanya jika cocok dengan
+-- tepat satu penawaran yang diterima: nama barang dan deskripsi otomatis yang dibuat saat
+-- penawaran diterima. Draft yang sudah diedit seller tetap tanpa tautan; seller perlu
+-- mengunggah foto sendiri sebelum publish.
+UPDATE items i SET source_offer_id = m.offer_id
+FROM (
+    SELECT d.id AS item_id, MIN(o.id::text)::uuid AS offer_id
+    FROM items d
+    JOIN shops s ON s.id = d.shop_id
+    JOIN offers o ON o.item_name = d.name
+        AND d.description = 'Draft dari Penawaran: ' || o.description || '. Kondisi: ' || o.condition || '. Lokasi Awal: ' || o.location || '.'
+        AND o.status IN ('accepted', 'paid')
+        AND COALESCE(o.seller_id, '00000000-0000-0000-0000-000000000000') IN (s.user_id, '00000000-0000-0000-0000-000000000000')
+    WHERE d.status = 'draft'
+      AND d.source_offer_id IS NULL
+      AND NOT EXISTS (SELECT 1 FROM items x WHERE x.source_offer_id = o.id)
+    GROUP BY d.id
+    HAVING COUNT(*) = 1
+) m
+WHERE i.id = m.item_id;
@@ -0,0 +1,3 @@
+-- Jumlah kode salah per token (dipakai MFA challenge). Token ditandai terpakai
+-- begitu batas percobaan tercapai.
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
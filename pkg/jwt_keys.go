@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"home-market/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey adalah satu key di dalam key set. Key tanpa private key hanya
+// dipakai untuk verifikasi (key lama yang sedang dirotasi keluar).
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySet berisi semua key yang diterima saat verifikasi dan kid aktif untuk signing.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	// secret HS256: mode fallback jika JWT_KEYS_DIR kosong, atau untuk menerima
+	// token lama selama migrasi ke key asimetris (hanya jika JWT_ACCEPT_LEGACY_HS256=true).
+	secret []byte
+}
+
+// JWK adalah representasi public key di endpoint JWKS (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keySetOnce sync.Once
+	keySet     *KeySet
+	keySetErr  error
+)
+
+// InitSigningKeys memuat key set sekali; dipanggil saat startup agar konfigurasi
+// key yang salah langsung ketahuan.
+func InitSigningKeys() error {
+	keySetOnce.Do(func() {
+		keySet, keySetErr = LoadKeySet(config.LoadJWT())
+	})
+	return keySetErr
+}
+
+func signingKeys() (*KeySet, error) {
+	if err := InitSigningKeys(); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// LoadKeySet membaca semua file .pem di cfg.KeysDir. Nama file tanpa ekstensi dipakai sebagai kid.
+func LoadKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+	if cfg.KeysDir == "" {
+		if len(cfg.Secret) == 0 {
+			return nil, errors.New("jwt: JWT_SECRET or JWT_KEYS_DIR must be set")
+		}
+		ks.secret = cfg.Secret
+		return ks, nil
+	}
+	if cfg.AcceptLegacyHS256 {
+		if len(cfg.Secret) == 0 {
+			return nil, errors.New("jwt: JWT_ACCEPT_LEGACY_HS256 requires JWT_SECRET")
+		}
+		ks.secret = cfg.Secret
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var latestPrivate *signingKey
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		key, err := loadKeyFile(file, kid)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %s: %w", kid, err)
+		}
+		ks.keys[kid] = key
+		if key.private != nil {
+			latestPrivate = key
+		}
+	}
+
+	if cfg.ActiveKID != "" {
+		key, ok := ks.keys[cfg.ActiveKID]
+		if !ok || key.private == nil {
+			return nil, fmt.Errorf("jwt: active kid %q has no private key in %s", cfg.ActiveKID, cfg.KeysDir)
+		}
+		ks.active = key
+	} else {
+		// tanpa JWT_ACTIVE_KID, pakai private key dengan nama file terakhir (mis. 2025-01.pem)
+		ks.active = latestPrivate
+	}
+	if ks.active == nil {
+		return nil, fmt.Errorf("jwt: no private key found in %s", cfg.KeysDir)
+	}
+	return ks, nil
+}
+
+func loadKeyFile(path string, kid string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+	return key, nil
+}
+
+// sign menandatangani token dengan key aktif, atau HS256 pada mode fallback.
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+	return token.SignedString(ks.active.private)
+}
+
+// validMethods mengembalikan algoritma yang diterima, sehingga token tidak bisa
+// memilih algoritma lain (mis. HS256 dengan public key sebagai secret).
+func (ks *KeySet) validMethods() []string {
+	var methods []string
+	seen := map[string]bool{}
+	for _, k := range ks.keys {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	if len(ks.secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
+}
+
+func (ks *KeySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		if len(ks.secret) == 0 {
+			return nil, errors.New("jwt: HS256 tokens are not accepted")
+		}
+		return ks.secret, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("jwt: unknown kid %q", kid)
+	}
+	if key.method.Alg() != t.Method.Alg() {
+		return nil, errors.New("jwt: algorithm does not match key")
+	}
+	return key.public, nil
+}
+
+// JWKS mengembalikan public key dari semua key asimetris (termasuk key lama yang
+// masih diterima) agar service lain bisa memverifikasi token kita.
+func JWKS() (*JWKSet, error) {
+	ks, err := signingKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := &JWKSet{Keys: []JWK{}}
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"home-market/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("test-secret")
+
+// writeKeys menulis key ke dir: nilai "ed25519", "ed25519-public" atau "rsa" per kid.
+func writeKeys(t *testing.T, dir string, keys map[string]string) map[string]ed25519.PrivateKey {
+	t.Helper()
+	private := make(map[string]ed25519.PrivateKey)
+	for kid, kind := range keys {
+		var block *pem.Block
+		switch kind {
+		case "ed25519", "ed25519-public":
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			private[kid] = priv
+			if kind == "ed25519" {
+				der, err := x509.MarshalPKCS8PrivateKey(priv)
+				if err != nil {
+					t.Fatal(err)
+				}
+				block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+			} else {
+				der, err := x509.MarshalPKIXPublicKey(pub)
+				if err != nil {
+					t.Fatal(err)
+				}
+				block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+			}
+		case "rsa":
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+		}
+		if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return private
+}
+
+func TestLoadKeySet(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       map[string]string
+		cfg        config.JWTConfig
+		wantErr    bool
+		wantActive string // kosong: mode fallback HS256
+		wantLegacy bool
+	}{
+		{name: "secret only falls back to HS256", cfg: config.JWTConfig{Secret: testSecret}, wantLegacy: true},
+		{name: "no secret and no keys", wantErr: true},
+		{
+			name:       "latest private key is active",
+			keys:       map[string]string{"2025-01": "ed25519", "2025-02": "rsa", "2025-03": "ed25519-public"},
+			wantActive: "2025-02",
+		},
+		{
+			name:       "explicit active kid",
+			keys:       map[string]string{"2025-01": "ed25519", "2025-02": "ed25519"},
+			cfg:        config.JWTConfig{ActiveKID: "2025-01"},
+			wantActive: "2025-01",
+		},
+		{
+			name:    "active kid without private key",
+			keys:    map[string]string{"2025-01": "ed25519", "2025-02": "ed25519-public"},
+			cfg:     config.JWTConfig{ActiveKID: "2025-02"},
+			wantErr: true,
+		},
+		{name: "only public keys", keys: map[string]string{"2025-01": "ed25519-public"}, wantErr: true},
+		{
+			name:    "legacy HS256 without secret",
+			keys:    map[string]string{"2025-01": "ed25519"},
+			cfg:     config.JWTConfig{AcceptLegacyHS256: true},
+			wantErr: true,
+		},
+		{
+			name:       "secret is ignored with keys unless legacy is accepted",
+			keys:       map[string]string{"2025-01": "ed25519"},
+			cfg:        config.JWTConfig{Secret: testSecret},
+			wantActive: "2025-01",
+		},
+		{
+			name:       "legacy HS256 accepted during migration",
+			keys:       map[string]string{"2025-01": "ed25519"},
+			cfg:        config.JWTConfig{Secret: testSecret, AcceptLegacyHS256: true},
+			wantActive: "2025-01",
+			wantLegacy: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if tt.keys != nil {
+				cfg.KeysDir = t.TempDir()
+				writeKeys(t, cfg.KeysDir, tt.keys)
+			}
+
+			ks, err := LoadKeySet(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.wantActive == "" && ks.active != nil || tt.wantActive != "" && (ks.active == nil || ks.active.kid != tt.wantActive) {
+				t.Fatalf("active key = %+v, want %q", ks.active, tt.wantActive)
+			}
+			if legacy := len(ks.secret) > 0; legacy != tt.wantLegacy {
+				t.Fatalf("accepts HS256 = %v, want %v", legacy, tt.wantLegacy)
+			}
+		})
+	}
+}
+
+func TestKeySetVerify(t *testing.T) {
+	dir := t.TempDir()
+	private := writeKeys(t, dir, map[string]string{"2025-01": "ed25519-public", "2025-02": "ed25519"})
+	strict, err := LoadKeySet(config.JWTConfig{KeysDir: dir, Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := LoadKeySet(config.JWTConfig{KeysDir: dir, Secret: testSecret, AcceptLegacyHS256: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.RegisteredClaims{Subject: "user", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	signEd := func(kid string, key ed25519.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	hs256 := func(secret []byte) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	active, err := strict.sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// serangan algorithm confusion: HS256 dengan public key sebagai secret
+	publicDER, _ := x509.MarshalPKIXPublicKey(private["2025-02"].Public())
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name   string
+		ks     *KeySet
+		token  string
+		wantOK bool
+	}{
+		{name: "active key", ks: strict, token: active, wantOK: true},
+		{name: "retired public key still verifies", ks: strict, token: signEd("2025-01", private["2025-01"]), wantOK: true},
+		{name: "unknown kid", ks: strict, token: signEd("2024-12", private["2025-02"])},
+		{name: "kid with the wrong key", ks: strict, token: signEd("2025-01", otherKey)},
+		{name: "legacy HS256 rejected by default", ks: strict, token: hs256(testSecret)},
+		{name: "legacy HS256 accepted during migration", ks: legacy, token: hs256(testSecret), wantOK: true},
+		{name: "HS256 with the wrong secret", ks: legacy, token: hs256([]byte("other-secret"))},
+		{name: "HS256 signed with the public key", ks: strict, token: hs256(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, tt.ks.keyFunc, jwt.WithValidMethods(tt.ks.validMethods()))
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("verify err = %v, want ok=%v", err, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,13 +1,162 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"home-market/internal/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnsupportedPasswordHash = errors.New("unsupported password hash format")
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHasher membuat hash password dengan algoritma yang dikonfigurasi dan
+// memverifikasi hash argon2id maupun bcrypt yang sudah tersimpan.
+type PasswordHasher struct {
+	cfg config.PasswordConfig
+}
+
+func NewPasswordHasher(cfg config.PasswordConfig) *PasswordHasher {
+	return &PasswordHasher{cfg: cfg}
+}
+
+var (
+	defaultHasherOnce sync.Once
+	defaultHasher     *PasswordHasher
+)
+
+func passwordHasher() *PasswordHasher {
+	defaultHasherOnce.Do(func() {
+		defaultHasher = NewPasswordHasher(config.LoadPassword())
+	})
+	return defaultHasher
+}
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return passwordHasher().Hash(password)
 }
 
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
-}
\ No newline at end of file
+	ok, _ := passwordHasher().Verify(password, hash)
+	return ok
+}
+
+// VerifyPassword sama dengan CheckPasswordHash, ditambah needsRehash jika hash
+// memakai algoritma atau parameter yang sudah tidak sesuai konfigurasi.
+func VerifyPassword(password, hash string) (ok bool, needsRehash bool) {
+	h := passwordHasher()
+	ok, _ = h.Verify(password, hash)
+	if !ok {
+		return false, false
+	}
+	return true, h.NeedsRehash(hash)
+}
+
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == config.PasswordAlgorithmBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.cfg.Argon2Iterations, h.cfg.Argon2Memory, h.cfg.Argon2Parallelism, argon2KeyLength)
+
+	// format PHC: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.cfg.Argon2Memory, h.cfg.Argon2Iterations, h.cfg.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *PasswordHasher) Verify(password, hash string) (bool, error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	}
+
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		return err == nil, nil
+	}
+	return false, ErrUnsupportedPasswordHash
+}
+
+// NeedsRehash bernilai true jika hash dibuat dengan algoritma lain atau parameter
+// yang lebih lemah/berbeda dari konfigurasi saat ini.
+func (h *PasswordHasher) NeedsRehash(hash string) bool {
+	if h.cfg.Algorithm == config.PasswordAlgorithmBcrypt {
+		if !isBcryptHash(hash) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.cfg.BcryptCost
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return true
+	}
+	params, _, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params.version != argon2.Version ||
+		params.memory != h.cfg.Argon2Memory ||
+		params.iterations != h.cfg.Argon2Iterations ||
+		params.parallelism != h.cfg.Argon2Parallelism ||
+		len(key) != argon2KeyLength
+}
+
+type argon2Params struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func decodeArgon2Hash(hash string) (*argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, nil, nil, ErrUnsupportedPasswordHash
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &p.version); err != nil {
+		return nil, nil, nil, ErrUnsupportedPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, nil, nil, ErrUnsupportedPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrUnsupportedPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrUnsupportedPasswordHash
+	}
+	return &p, salt, key, nil
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"home-market/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// parameter argon2id kecil agar test cepat; nilainya tidak memengaruhi logika rehash
+var testArgon2 = config.PasswordConfig{
+	Algorithm:         config.PasswordAlgorithmArgon2id,
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+}
+
+func TestPasswordHasher(t *testing.T) {
+	const password = "rahasia-kuat-1"
+
+	bcryptCfg := config.PasswordConfig{Algorithm: config.PasswordAlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+	strongerArgon2 := testArgon2
+	strongerArgon2.Argon2Iterations = 2
+	strongerBcrypt := bcryptCfg
+	strongerBcrypt.BcryptCost = bcrypt.MinCost + 1
+
+	tests := []struct {
+		name string
+		// hashWith membuat hash tersimpan; verifyWith adalah konfigurasi saat login
+		hashWith    config.PasswordConfig
+		verifyWith  config.PasswordConfig
+		password    string
+		wantOK      bool
+		wantRehash  bool
+		wantErr     bool
+		corruptHash func(hash string) string
+	}{
+		{name: "argon2id with current parameters", hashWith: testArgon2, verifyWith: testArgon2, password: password, wantOK: true},
+		{name: "argon2id wrong password", hashWith: testArgon2, verifyWith: testArgon2, password: "salah"},
+		{name: "legacy bcrypt is upgraded to argon2id", hashWith: bcryptCfg, verifyWith: testArgon2, password: password, wantOK: true, wantRehash: true},
+		{name: "argon2id with old parameters", hashWith: testArgon2, verifyWith: strongerArgon2, password: password, wantOK: true, wantRehash: true},
+		{name: "bcrypt with current cost", hashWith: bcryptCfg, verifyWith: bcryptCfg, password: password, wantOK: true},
+		{name: "bcrypt with old cost", hashWith: bcryptCfg, verifyWith: strongerBcrypt, password: password, wantOK: true, wantRehash: true},
+		{name: "argon2id back to bcrypt", hashWith: testArgon2, verifyWith: bcryptCfg, password: password, wantOK: true, wantRehash: true},
+		{
+			name: "malformed argon2id hash", hashWith: testArgon2, verifyWith: testArgon2, password: password, wantRehash: true, wantErr: true,
+			corruptHash: func(hash string) string { return hash[:strings.LastIndex(hash, "$")] },
+		},
+		{
+			name: "unknown hash format", hashWith: testArgon2, verifyWith: testArgon2, password: password, wantRehash: true, wantErr: true,
+			corruptHash: func(string) string { return "plaintext" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := NewPasswordHasher(tt.hashWith).Hash(password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.corruptHash != nil {
+				hash = tt.corruptHash(hash)
+			}
+
+			h := NewPasswordHasher(tt.verifyWith)
+			ok, err := h.Verify(tt.password, hash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Verify = %v, want %v", ok, tt.wantOK)
+			}
+			if rehash := h.NeedsRehash(hash); rehash != tt.wantRehash {
+				t.Fatalf("NeedsRehash = %v, want %v", rehash, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestArgon2HashFormat(t *testing.T) {
+	h := NewPasswordHasher(testArgon2)
+	first, _ := h.Hash("rahasia-kuat-1")
+	second, _ := h.Hash("rahasia-kuat-1")
+
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash %q is not in PHC format with the configured parameters", first)
+	}
+	if first == second {
+		t.Fatal("two hashes of the same password share a salt")
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA1 dari lampiran B RFC 6238 ("12345678901234567890").
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestMatchTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       int64
+		wantStep int64
+		wantOK   bool
+	}{
+		// vektor RFC 6238 dipotong ke 6 digit terakhir
+		{name: "rfc vector t=59", secret: rfc6238Secret, code: "287082", at: 59, wantStep: 1, wantOK: true},
+		{name: "rfc vector t=1111111109", secret: rfc6238Secret, code: "081804", at: 1111111109, wantStep: 37037036, wantOK: true},
+		{name: "rfc vector t=1234567890", secret: rfc6238Secret, code: "005924", at: 1234567890, wantStep: 41152263, wantOK: true},
+		{name: "rfc vector t=2000000000", secret: rfc6238Secret, code: "279037", at: 2000000000, wantStep: 66666666, wantOK: true},
+		{name: "surrounding spaces are ignored", secret: rfc6238Secret, code: " 287082 ", at: 59, wantStep: 1, wantOK: true},
+		{name: "lowercase padded secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq====", code: "287082", at: 59, wantStep: 1, wantOK: true},
+		{name: "previous period is tolerated", secret: rfc6238Secret, code: "287082", at: 59 + 30, wantStep: 1, wantOK: true},
+		{name: "next period is tolerated", secret: rfc6238Secret, code: "287082", at: 59 - 30, wantStep: 1, wantOK: true},
+		{name: "two periods later is rejected", secret: rfc6238Secret, code: "287082", at: 59 + 60},
+		{name: "wrong code", secret: rfc6238Secret, code: "287083", at: 59},
+		{name: "too short", secret: rfc6238Secret, code: "28708", at: 59},
+		{name: "invalid secret", secret: "not base32!", code: "287082", at: 59},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := MatchTOTP(tt.secret, tt.code, time.Unix(tt.at, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("MatchTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes (err %v), want 20", secret, len(key), err)
+	}
+
+	now := time.Now()
+	code := totpCode(key, uint64(now.Unix()/totpPeriod))
+	if !ValidateTOTP(secret, code, now) {
+		t.Fatalf("code %s for a fresh secret was rejected", code)
+	}
+}